@@ -10,7 +10,20 @@ export class FetchError<B = any> extends Error {
   }
 }
 
-const getFullUrl = (url: string) => `${__BASE_URL__}${url}`;
+/**
+ * Returns the path Orchest is served under, without the trailing slash.
+ * The webserver sets it as the `<base>` of the page.
+ */
+export const getBasePath = () =>
+  typeof document === "undefined"
+    ? ""
+    : new URL(document.baseURI).pathname.replace(/\/$/, "");
+
+/** Prefixes the absolute paths of Orchest with the base path. */
+export const withBasePath = (url: string) =>
+  url.startsWith("/") && !url.startsWith("//") ? `${getBasePath()}${url}` : url;
+
+const getFullUrl = (url: string) => `${__BASE_URL__}${withBasePath(url)}`;
 
 export const fetcher = async <T, E = any>(
   url: string,
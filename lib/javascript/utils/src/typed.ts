@@ -1,3 +1,5 @@
+import { withBasePath } from "./fetcher";
+
 // used in orchest-webserver and mdc-components only
 export function uuidv4() {
   return "xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx".replace(/[xy]/g, function (c) {
@@ -99,7 +101,7 @@ export function arraysEqual(a, b) {
 export function makeRequest(method, url, body?, onprogressCallback?, timeout?) {
   return new Promise<string>(function (resolve, reject) {
     let xhr = new XMLHttpRequest();
-    xhr.open(method, url);
+    xhr.open(method, withBasePath(url));
 
     if (onprogressCallback) {
       xhr.onreadystatechange = onprogressCallback;
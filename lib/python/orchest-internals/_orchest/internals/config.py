@@ -3,6 +3,16 @@ import os
 ORCHEST_NAMESPACE = os.environ["ORCHEST_NAMESPACE"]
 ORCHEST_CLUSTER = os.environ["ORCHEST_CLUSTER"]
 
+
+def resource_name(name: str) -> str:
+    """Returns the name of an object of the OrchestCluster.
+
+    The objects of a cluster are prefixed with its name, so that
+    multiple clusters can run in the same namespace.
+    """
+    return f"{ORCHEST_CLUSTER}-{name}"
+
+
 ORCHEST_MAINTAINER_LABEL = "Orchest B.V. https://www.orchest.io"
 
 # Orchest directories that need to exist in the userdir.
@@ -36,8 +46,27 @@ GPU_ENABLED_INSTANCE = os.environ.get("ORCHEST_GPU_ENABLED_INSTANCE") == "True"
 # load. This is only enforced when CPU cycles are constrained. For more
 # information, see the k8s docs about CPU SHARES.
 USER_CONTAINERS_CPU_SHARES = "1m"
-REGISTRY = "docker-registry"
-REGISTRY_FQDN = f"docker-registry.{ORCHEST_NAMESPACE}.svc.cluster.local"
+REGISTRY = resource_name("docker-registry")
+# The external image registry, e.g. harbor.example.com/orchest, set by
+# the controller if it is used instead of the in-cluster registry.
+IMAGE_REGISTRY = (
+    os.environ.get("ORCHEST_IMAGE_REGISTRY", "").split("://")[-1].strip("/")
+)
+if IMAGE_REGISTRY:
+    # The repositories of the images are prefixed with the project or
+    # repository prefix of the registry url, if any.
+    REGISTRY_HOST, _, REGISTRY_REPOSITORY_PREFIX = IMAGE_REGISTRY.partition("/")
+    REGISTRY_FQDN = IMAGE_REGISTRY
+else:
+    REGISTRY_HOST = f"{REGISTRY}.{ORCHEST_NAMESPACE}.svc.cluster.local"
+    REGISTRY_REPOSITORY_PREFIX = ""
+    REGISTRY_FQDN = REGISTRY_HOST
+REGISTRY_RELEASE = f"{ORCHEST_NAMESPACE}-{REGISTRY}"
+REGISTRY_TLS_SECRET = resource_name("registry-tls-secret")
+
+# Volumes
+USERDIR_PVC = resource_name("userdir-pvc")
+BUILDER_CACHE_PVC = resource_name("image-builder-cache-pvc")
 
 # Container Runtime configs.
 CONTAINER_RUNTIME = os.environ.get("CONTAINER_RUNTIME")
@@ -46,6 +75,9 @@ CONTAINER_RUNTIME_SOCKET = os.environ.get("CONTAINER_RUNTIME_SOCKET")
 
 # Ingress configs
 INGRESS_CLASS = os.environ.get("INGRESS_CLASS", "nginx")
+# The path prefix Orchest is served under, e.g. "/orchest", empty if
+# Orchest is served at the root of its host.
+ORCHEST_BASE_PATH = os.environ.get("ORCHEST_BASE_PATH", "").rstrip("/")
 
 # Databases
 database_naming_convention = {
@@ -103,8 +135,12 @@ LOGS_PATH = ".orchest/pipelines/{pipeline_uuid}/logs"
 WEBSERVER_LOGS = "/orchest/services/orchest-webserver/app/orchest-webserver.log"
 
 # Networking
-ORCHEST_API_ADDRESS = "orchest-api"
-ORCHEST_SOCKETIO_SERVER_ADDRESS = "http://orchest-webserver"
+ORCHEST_API_ADDRESS = resource_name("orchest-api")
+ORCHEST_WEBSERVER_ADDRESS = resource_name("orchest-webserver")
+ORCHEST_AUTH_SERVER_ADDRESS = resource_name("auth-server")
+ORCHEST_DATABASE_ADDRESS = resource_name("orchest-database")
+ORCHEST_RABBITMQ_ADDRESS = resource_name("rabbitmq-server")
+ORCHEST_SOCKETIO_SERVER_ADDRESS = f"http://{ORCHEST_WEBSERVER_ADDRESS}"
 ORCHEST_SOCKETIO_ENV_IMG_BUILDING_NAMESPACE = "/environment_image_builds"
 ORCHEST_SOCKETIO_JUPYTER_IMG_BUILDING_NAMESPACE = "/jupyter_image_builds"
 
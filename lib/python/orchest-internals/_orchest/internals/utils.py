@@ -13,13 +13,16 @@ logger = logging.getLogger(__name__)
 
 
 def get_environment_capabilities(environment_uuid, project_uuid):
+    # The config requires the env of an Orchest service, which is not
+    # available to all users of this module.
+    from _orchest.internals import config as _config
 
     capabilities = []
 
     try:
         response = requests.get(
-            "http://orchest-webserver/store/environments/%s/%s"
-            % (project_uuid, environment_uuid)
+            "http://%s/store/environments/%s/%s"
+            % (_config.ORCHEST_WEBSERVER_ADDRESS, project_uuid, environment_uuid)
         )
         response.raise_for_status()
     except Exception as e:
@@ -163,7 +166,8 @@ def add_image_puller_if_needed(
         image_to_pull:
             The image that image_puller has to pull.
         registry_ip:
-            our registry ip address
+            our registry ip address, or the address of the external
+            registry including its repository prefix.
         container_runtime:
             The container runtime of the node.
         image_puller_image:
@@ -174,7 +178,7 @@ def add_image_puller_if_needed(
     """
     domain, name = split_docker_domain(image_to_pull)
 
-    if domain == registry_ip:
+    if f"{domain}/{name}".startswith(f"{registry_ip}/"):
         image_puller_manifest = get_init_container_manifest(
             f"{domain}/{name}",
             container_runtime,
@@ -203,7 +207,7 @@ def split_docker_domain(name: str) -> Tuple[str, str]:
     official_repo_name = "library"
     default_tag = "latest"
 
-    names = name.split("/")
+    names = name.split("/", 1)
     if len(names) == 1 or (
         not any(c in names[0] for c in [".", ":"]) and names[0] != "localhost"
     ):
@@ -239,6 +243,43 @@ def is_running_from_reloader():
     return _irfr()
 
 
+def check_secret_key(app) -> None:
+    """Raises if the app is served without its secret key.
+
+    To be called by the apps signing data with the secret key. The
+    controller generates the secret keys of the components, which are
+    only missing if the component is deployed by other means.
+
+    """
+    if not app.config.get("SECRET_KEY"):
+        raise RuntimeError("The ORCHEST_SECRET_KEY environment variable is not set.")
+
+
+class BasePathMiddleware:
+    """WSGI middleware serving the app under a path prefix.
+
+    The ingresses route the requests with their full path, so the
+    prefix is moved from the PATH_INFO to the SCRIPT_NAME, which makes
+    the app match its routes as usual and generate its URLs, e.g. the
+    redirects, under the prefix. Requests without the prefix, e.g. the
+    ones within the cluster, are passed as is.
+
+    """
+
+    def __init__(self, app, base_path: str):
+        self.app = app
+        self.base_path = base_path.rstrip("/")
+
+    def __call__(self, environ, start_response):
+        path = environ.get("PATH_INFO", "")
+        if self.base_path and (
+            path == self.base_path or path.startswith(self.base_path + "/")
+        ):
+            environ["SCRIPT_NAME"] = environ.get("SCRIPT_NAME", "") + self.base_path
+            environ["PATH_INFO"] = path[len(self.base_path) :] or "/"
+        return self.app(environ, start_response)
+
+
 def are_environment_variables_valid(env_variables: Dict[str, str]) -> bool:
     return isinstance(env_variables, dict) and all(
         [
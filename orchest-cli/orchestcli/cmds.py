@@ -1258,7 +1258,7 @@ def _run_pod_exec(
         label_selector=(
             f"controller.orchest.io/component={orchest_service},"
             "controller.orchest.io/part-of=orchest,"
-            f"controller.orchest.io/owner={orchest_service}"
+            f"controller.orchest.io/owner={cluster_name}-{orchest_service}"
         ),
     )
     if not pods.items:
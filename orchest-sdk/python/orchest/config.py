@@ -20,8 +20,13 @@ class Config:
     PIPELINE_DEFINITION_PATH = os.getenv("ORCHEST_PIPELINE_PATH")
 
     # Necessary to query the orchest-api for the current interactive
-    # session specs.
-    ORCHEST_API_ADDRESS = "orchest-api"
+    # session specs. The services of an Orchest cluster are prefixed
+    # with the name of the cluster.
+    ORCHEST_API_ADDRESS = (
+        f"{os.environ['ORCHEST_CLUSTER']}-orchest-api"
+        if "ORCHEST_CLUSTER" in os.environ
+        else "orchest-api"
+    )
 
     # Data directory for outputting to disk. Note that it uses the
     # base directory in which the function is called.
@@ -62,6 +62,7 @@ if [ ${#SERVICES[@]} -eq 0 ]; then
         "orchest-api"
         "orchest-sdk"
         "orchest-webserver"
+        "auth-server"
         "base-images-runnable"
     )
 fi
@@ -162,6 +163,17 @@ do
         REQ_DIR=$TEST_DIR/..
         REQ_FILE=$REQ_DIR/requirements.txt
     fi
+    if [ $SERVICE == "auth-server" ]; then
+
+        if [[ -z "${ORCHEST_TEST_DATABASE_HOST}" ]]; then
+            echo "Setting up local test database..."
+            setup_local_test_db
+        fi
+
+        TEST_DIR=$DIR/../services/auth-server/app
+        REQ_DIR=$TEST_DIR/..
+        REQ_FILE=$REQ_DIR/requirements.txt
+    fi
     if [ $SERVICE == "base-images-runnable" ]; then
         TEST_DIR=$DIR/../services/base-images/runnable-shared/runner
         REQ_DIR=$TEST_DIR
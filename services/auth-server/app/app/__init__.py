@@ -17,7 +17,10 @@ from flask import Flask, request
 from flask_migrate import Migrate
 from sqlalchemy_utils import create_database, database_exists
 
+from _orchest.internals import config as _config
+from _orchest.internals.utils import BasePathMiddleware
 from app.connections import db
+from app.oidc import register_oidc_views
 from app.views import register_views
 from config import CONFIG_CLASS
 
@@ -34,12 +37,19 @@ def create_app(config_class=None, to_migrate_db=False):
     app = Flask(__name__)
     app.config.from_object(config_class)
 
+    if _config.ORCHEST_BASE_PATH:
+        app.wsgi_app = BasePathMiddleware(app.wsgi_app, _config.ORCHEST_BASE_PATH)
+
     if not to_migrate_db:
         orchest_config = requests.get(
             f"http://{CONFIG_CLASS.ORCHEST_API_ADDRESS}/api/ctl/orchest-settings"
         ).json()
         app.config.update(orchest_config)
 
+        # Signing in through the OIDC provider implies authentication.
+        if app.config["OIDC_ISSUER_URL"]:
+            app.config["AUTH_ENABLED"] = True
+
     init_logging()
 
     if os.getenv("FLASK_ENV") == "development":
@@ -60,6 +70,7 @@ def create_app(config_class=None, to_migrate_db=False):
         return app
 
     register_views(app)
+    register_oidc_views(app)
 
     return app
 
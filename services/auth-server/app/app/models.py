@@ -25,6 +25,12 @@ class User(db.Model):
 
     is_admin = db.Column(db.Boolean, server_default=text("False"), nullable=False)
 
+    # The identity of the users signing in through the OIDC provider,
+    # the local users have none.
+    oidc_issuer = db.Column(db.String(255), nullable=True)
+
+    oidc_subject = db.Column(db.String(255), nullable=True)
+
     created = db.Column(
         db.DateTime,
         unique=False,
@@ -32,6 +38,8 @@ class User(db.Model):
         server_default=text("timezone('utc', now())"),
     )
 
+    __table_args__ = (db.UniqueConstraint("oidc_issuer", "oidc_subject"),)
+
 
 class Token(db.Model):
 
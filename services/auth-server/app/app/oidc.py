@@ -0,0 +1,277 @@
+"""Single sign-on through an OpenID Connect provider.
+
+The users are signed in with the authorization code flow. The ID token
+is received directly from the token endpoint of the provider over TLS,
+which authenticates the issuer in place of the token signature, see
+https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation.
+The signature is not verified, which is why the controller only accepts
+https issuer URLs.
+"""
+import base64
+import json
+import secrets
+import time
+import uuid
+from urllib.parse import urlencode
+
+import requests
+from flask import abort, redirect, request
+from itsdangerous import BadData, URLSafeTimedSerializer
+from werkzeug.security import generate_password_hash
+
+from _orchest.internals import config as _config
+from app.connections import db
+from app.models import Token, User
+from app.utils import is_local_url
+
+_discovery_cache = {}
+
+# The state of a login is kept in a cookie signed with the secret key,
+# for as long as the user has to sign in at the provider, in seconds.
+_LOGIN_COOKIE = "oidc_login"
+_LOGIN_MAX_AGE = 600
+
+
+def get_discovery(issuer_url):
+    """Returns the discovery document of the issuer, it is cached."""
+    if issuer_url not in _discovery_cache:
+        resp = requests.get(
+            issuer_url.rstrip("/") + "/.well-known/openid-configuration", timeout=10
+        )
+        resp.raise_for_status()
+        _discovery_cache[issuer_url] = resp.json()
+    return _discovery_cache[issuer_url]
+
+
+def decode_id_token(id_token):
+    """Returns the claims of the ID token, without verifying it."""
+    payload = id_token.split(".")[1]
+    payload += "=" * (-len(payload) % 4)
+    return json.loads(base64.urlsafe_b64decode(payload))
+
+
+def validate_claims(claims, issuer_url, client_id, nonce):
+    """Returns an error message if the ID token claims are invalid."""
+    if claims.get("iss", "").rstrip("/") != issuer_url.rstrip("/"):
+        return "The ID token has an unexpected issuer."
+
+    audience = claims.get("aud")
+    if isinstance(audience, str):
+        audience = [audience]
+    if client_id not in (audience or []):
+        return "The ID token is not issued for Orchest."
+
+    if claims.get("exp", 0) < time.time():
+        return "The ID token is expired."
+
+    if not nonce or claims.get("nonce") != nonce:
+        return "The ID token has an unexpected nonce."
+
+    if not claims.get("sub"):
+        return "The ID token has no subject."
+
+    return None
+
+
+def get_groups(claims, groups_claim):
+    groups = claims.get(groups_claim, [])
+    if isinstance(groups, str):
+        groups = [groups]
+    return groups
+
+
+def is_allowed(groups, allowed_groups):
+    """Returns whether the user is a member of the allowed groups, all
+    users are allowed if there are none."""
+    return not allowed_groups or bool(set(groups) & set(allowed_groups))
+
+
+def get_or_create_user(issuer, subject, username, is_admin):
+    """Returns the user of the OIDC identity, it is created on its first
+    login.
+
+    The identity is never bound to an existing user of the same name,
+    e.g. a local admin, in which case None is returned.
+    """
+    user = User.query.filter(
+        User.oidc_issuer == issuer, User.oidc_subject == subject
+    ).first()
+    if user is not None:
+        # The admins among the users of the provider are managed by it.
+        user.is_admin = is_admin
+        return user
+
+    if User.query.filter(User.username == username).first() is not None:
+        return None
+
+    # The users of the provider can not log in with a password.
+    user = User(
+        username=username,
+        password_hash=generate_password_hash(secrets.token_hex(32)),
+        uuid=str(uuid.uuid4()),
+        is_admin=is_admin,
+        oidc_issuer=issuer,
+        oidc_subject=subject,
+    )
+    db.session.add(user)
+    return user
+
+
+def register_oidc_views(app):
+    serializer = URLSafeTimedSerializer(app.config["SECRET_KEY"], salt="oidc-login")
+
+    def get_scheme():
+        # As forwarded by the ingress or proxy in front of the
+        # auth-server.
+        return request.headers.get("X-Forwarded-Proto", request.scheme)
+
+    def get_redirect_uri():
+        # The host of the request is controlled by the client, the one
+        # registered at the provider is the host of Orchest.
+        return (
+            f"{get_scheme()}://{_config.ORCHEST_FQDN}"
+            f"{_config.ORCHEST_BASE_PATH}/login/oidc/callback"
+        )
+
+    @app.route("/login/oidc", methods=["GET"])
+    def login_oidc():
+        if not app.config["OIDC_ISSUER_URL"]:
+            abort(404)
+
+        if not _config.ORCHEST_FQDN:
+            app.logger.error("Signing in through OIDC requires the host of Orchest.")
+            return "Login failed.", 500
+
+        discovery = get_discovery(app.config["OIDC_ISSUER_URL"])
+
+        state = secrets.token_urlsafe(32)
+        nonce = secrets.token_urlsafe(32)
+        scopes = ["openid"] + [
+            scope for scope in app.config["OIDC_SCOPES"].split() if scope != "openid"
+        ]
+        query = urlencode(
+            {
+                "response_type": "code",
+                "client_id": app.config["OIDC_CLIENT_ID"],
+                "redirect_uri": get_redirect_uri(),
+                "scope": " ".join(scopes),
+                "state": state,
+                "nonce": nonce,
+            }
+        )
+
+        redirect_url = request.args.get("redirect_url", request.script_root + "/")
+        if not is_local_url(redirect_url):
+            redirect_url = request.script_root + "/"
+
+        resp = redirect(discovery["authorization_endpoint"] + "?" + query)
+        resp.set_cookie(
+            _LOGIN_COOKIE,
+            serializer.dumps(
+                {"state": state, "nonce": nonce, "redirect_url": redirect_url}
+            ),
+            max_age=_LOGIN_MAX_AGE,
+            secure=get_scheme() == "https",
+            httponly=True,
+            samesite="Lax",
+        )
+        return resp
+
+    @app.route("/login/oidc/callback", methods=["GET"])
+    def login_oidc_callback():
+        if not app.config["OIDC_ISSUER_URL"]:
+            abort(404)
+
+        if "error" in request.args:
+            app.logger.error(
+                "OIDC login failed: %s %s",
+                request.args["error"],
+                request.args.get("error_description", ""),
+            )
+            return "Login failed.", 401
+
+        try:
+            login = serializer.loads(
+                request.cookies.get(_LOGIN_COOKIE, ""), max_age=_LOGIN_MAX_AGE
+            )
+        except BadData:
+            return "Invalid login state.", 400
+
+        state = login.get("state")
+        if not state or request.args.get("state") != state:
+            return "Invalid login state.", 400
+
+        discovery = get_discovery(app.config["OIDC_ISSUER_URL"])
+        try:
+            resp = requests.post(
+                discovery["token_endpoint"],
+                data={
+                    "grant_type": "authorization_code",
+                    "code": request.args.get("code", ""),
+                    "redirect_uri": get_redirect_uri(),
+                },
+                auth=(app.config["OIDC_CLIENT_ID"], app.config["OIDC_CLIENT_SECRET"]),
+                timeout=10,
+            )
+            resp.raise_for_status()
+            tokens = resp.json()
+            claims = decode_id_token(tokens["id_token"])
+        except Exception as e:
+            app.logger.error("Failed to exchange the OIDC code: %s", e)
+            return "Login failed.", 401
+
+        error = validate_claims(
+            claims,
+            app.config["OIDC_ISSUER_URL"],
+            app.config["OIDC_CLIENT_ID"],
+            login.get("nonce"),
+        )
+        if error is not None:
+            app.logger.error(error)
+            return error, 401
+
+        # Providers might only return the groups from the userinfo
+        # endpoint.
+        groups_claim = app.config["OIDC_GROUPS_CLAIM"]
+        if groups_claim not in claims and "userinfo_endpoint" in discovery:
+            try:
+                resp = requests.get(
+                    discovery["userinfo_endpoint"],
+                    headers={"Authorization": "Bearer " + tokens["access_token"]},
+                    timeout=10,
+                )
+                resp.raise_for_status()
+                claims = {**resp.json(), **claims}
+            except Exception as e:
+                app.logger.warning("Failed to get the OIDC userinfo: %s", e)
+
+        groups = get_groups(claims, groups_claim)
+        allowed_groups = app.config["OIDC_ALLOWED_GROUPS"]
+        if not is_allowed(groups, allowed_groups):
+            return "You are not allowed to access Orchest.", 403
+
+        username = claims.get(app.config["OIDC_USERNAME_CLAIM"]) or claims["sub"]
+        is_admin = bool(app.config["OIDC_ADMIN_GROUP"]) and (
+            app.config["OIDC_ADMIN_GROUP"] in groups
+        )
+
+        user = get_or_create_user(claims["iss"], claims["sub"], username, is_admin)
+        if user is None:
+            app.logger.error(
+                "The OIDC user %s can not sign in, the username is taken.", username
+            )
+            return "The username is taken by another user of Orchest.", 409
+
+        token = Token(user=user.uuid, token=str(secrets.token_hex(16)))
+        db.session.add(token)
+        db.session.commit()
+
+        redirect_url = login.get("redirect_url", "")
+        if not is_local_url(redirect_url):
+            redirect_url = request.script_root + "/"
+
+        resp = redirect(redirect_url)
+        resp.set_cookie("auth_token", token.token)
+        resp.set_cookie("auth_username", user.username)
+        resp.delete_cookie(_LOGIN_COOKIE)
+        return resp
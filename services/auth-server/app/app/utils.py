@@ -1,5 +1,7 @@
 import datetime
 import hashlib
+import posixpath
+from urllib.parse import urlsplit
 
 
 def get_hash(path):
@@ -14,6 +16,26 @@ def get_hash(path):
     return hasher.hexdigest()
 
 
+def is_local_url(url):
+    """Checks that the url is a path of this site, to redirect to.
+
+    Browsers treat backslashes as slashes and drop tabs and newlines in
+    urls, e.g. /\\evil.com is requested as //evil.com, which is another
+    site. The path is normalized to catch the same through dot segments.
+    """
+    if not url.startswith("/") or url.startswith("//") or "\\" in url:
+        return False
+
+    if any(ord(c) <= 0x20 or ord(c) == 0x7F for c in url):
+        return False
+
+    parts = urlsplit(url)
+    if parts.scheme or parts.netloc:
+        return False
+
+    return not posixpath.normpath(parts.path).startswith("//")
+
+
 def set_auth_cache(
     project_uuid_prefix, session_uuid_prefix, requires_authentication, auth_cache
 ):
@@ -9,7 +9,7 @@ from werkzeug.security import check_password_hash, generate_password_hash
 
 from app.connections import db
 from app.models import Token, User
-from app.utils import get_auth_cache, set_auth_cache
+from app.utils import get_auth_cache, is_local_url, set_auth_cache
 
 # This auth_cache is shared between requests
 # within the same Flask process
@@ -82,6 +82,18 @@ def register_views(app):
         if is_authenticated(request) and path == "":
             return handle_login(redirect_type="server")
 
+        # Users sign in through the OIDC provider, unless they ask for
+        # the local login, e.g. the admin created at setup.
+        if (
+            app.config["OIDC_ISSUER_URL"]
+            and path == ""
+            and "local" not in request.args
+        ):
+            url = request.script_root + "/login/oidc"
+            if request.query_string:
+                url += "?" + request.query_string.decode()
+            return redirect(url)
+
         return serve_static_or_dev(path)
 
     @app.route("/login/admin", methods=["GET"])
@@ -97,12 +109,19 @@ def register_views(app):
         # validate authentication through token
         if is_authenticated(request):
             return "", 200
-        else:
-            return "", 401
+
+        # Forward auth proxies, e.g. Traefik, return the response of this
+        # endpoint to the client, unlike nginx which redirects to its
+        # auth-signin itself, so the proxy asks for the redirect.
+        signin = request.args.get("signin", "")
+        # Only local paths, to not redirect to other sites.
+        if is_local_url(signin):
+            return redirect_response(signin)
+        return "", 401
 
     @app.route("/login/clear", methods=["GET"])
     def logout():
-        resp = redirect_response("/")
+        resp = redirect_response(request.script_root + "/")
         resp.set_cookie("auth_token", "")
         resp.set_cookie("auth_username", "")
         return resp
@@ -126,7 +145,9 @@ def register_views(app):
         # Returns a shallow mutable copy of the immutable
         # multi dict.
         request_args = request.args.copy()
-        redirect_url = request_args.pop("redirect_url", "/")
+        # The root of Orchest, which is the base path if Orchest is
+        # served under a path prefix.
+        redirect_url = request_args.pop("redirect_url", request.script_root + "/")
         query_args = "&".join(
             [arg + "=" + value for arg, value in request_args.items()]
         )
@@ -5,7 +5,9 @@ from _orchest.internals import config as _config
 
 class Config:
     DEBUG = False
-    SQLALCHEMY_DATABASE_URI = "postgresql://postgres@orchest-database/auth_server"
+    SQLALCHEMY_DATABASE_URI = (
+        f"postgresql://postgres@{_config.ORCHEST_DATABASE_ADDRESS}/auth_server"
+    )
 
     TOKEN_DURATION_HOURS = 24 * 14
 
@@ -23,6 +25,26 @@ class Config:
 
     SQLALCHEMY_TRACK_MODIFICATIONS = False
 
+    # Generated by the controller, signs the OIDC login cookies.
+    SECRET_KEY = os.environ.get("ORCHEST_SECRET_KEY")
+
+    # OpenID Connect single sign-on, enabled if the issuer is set. The
+    # users are created on their first login.
+    OIDC_ISSUER_URL = os.environ.get("ORCHEST_OIDC_ISSUER_URL", "")
+    OIDC_CLIENT_ID = os.environ.get("ORCHEST_OIDC_CLIENT_ID", "")
+    OIDC_CLIENT_SECRET = os.environ.get("ORCHEST_OIDC_CLIENT_SECRET", "")
+    OIDC_SCOPES = os.environ.get("ORCHEST_OIDC_SCOPES", "profile email groups")
+    OIDC_USERNAME_CLAIM = os.environ.get(
+        "ORCHEST_OIDC_USERNAME_CLAIM", "preferred_username"
+    )
+    OIDC_GROUPS_CLAIM = os.environ.get("ORCHEST_OIDC_GROUPS_CLAIM", "groups")
+    OIDC_ALLOWED_GROUPS = [
+        group
+        for group in os.environ.get("ORCHEST_OIDC_ALLOWED_GROUPS", "").split(",")
+        if group
+    ]
+    OIDC_ADMIN_GROUP = os.environ.get("ORCHEST_OIDC_ADMIN_GROUP", "")
+
 
 class DevelopmentConfig(Config):
     DEBUG = True
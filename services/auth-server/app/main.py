@@ -1,7 +1,9 @@
+from _orchest.internals.utils import check_secret_key
 from app import create_app
 from config import CONFIG_CLASS
 
 app = create_app(config_class=CONFIG_CLASS)
+check_secret_key(app)
 
 
 if __name__ == "__main__":
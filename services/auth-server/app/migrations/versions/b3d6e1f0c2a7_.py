@@ -0,0 +1,37 @@
+"""Add oidc_issuer and oidc_subject columns to users
+
+Revision ID: b3d6e1f0c2a7
+Revises: fe9a960dc117
+Create Date: 2026-10-19 12:41:07.318204
+
+"""
+import sqlalchemy as sa
+from alembic import op
+
+# revision identifiers, used by Alembic.
+revision = "b3d6e1f0c2a7"
+down_revision = "fe9a960dc117"
+branch_labels = None
+depends_on = None
+
+
+def upgrade():
+    op.add_column(
+        "users", sa.Column("oidc_issuer", sa.String(length=255), nullable=True)
+    )
+    op.add_column(
+        "users", sa.Column("oidc_subject", sa.String(length=255), nullable=True)
+    )
+    op.create_unique_constraint(
+        op.f("uq_users_oidc_issuer_oidc_subject"),
+        "users",
+        ["oidc_issuer", "oidc_subject"],
+    )
+
+
+def downgrade():
+    op.drop_constraint(
+        op.f("uq_users_oidc_issuer_oidc_subject"), "users", type_="unique"
+    )
+    op.drop_column("users", "oidc_subject")
+    op.drop_column("users", "oidc_issuer")
@@ -0,0 +1,61 @@
+import copy
+import os
+
+import pytest
+from flask_migrate import upgrade
+from sqlalchemy_utils import drop_database
+
+from app import create_app
+from app.connections import db
+from config import CONFIG_CLASS
+
+
+@pytest.fixture(scope="module")
+def test_app():
+    """Setup a flask application with a working db.
+
+    Expects a postgres database service to be running. A new database
+    will be created, it is dropped at the end of scope of the fixture.
+    """
+
+    config = copy.deepcopy(CONFIG_CLASS)
+
+    # Setup the DB URI.
+    db_host = os.environ.get("ORCHEST_TEST_DATABASE_HOST", "localhost")
+    db_port = os.environ.get("ORCHEST_TEST_DATABASE_PORT", "5432")
+    db_name = "test_db"
+    SQLALCHEMY_DATABASE_URI = f"postgresql://postgres@{db_host}:{db_port}/{db_name}"
+    config.SQLALCHEMY_DATABASE_URI = SQLALCHEMY_DATABASE_URI
+
+    config.TESTING = True
+
+    # The app only initializes the db, the views are not needed.
+    app = create_app(config, to_migrate_db=True)
+    with app.app_context():
+        upgrade()
+
+    yield app
+
+    drop_database(app.config["SQLALCHEMY_DATABASE_URI"])
+
+
+@pytest.fixture()
+def app_context(test_app):
+    """Pushes an app context.
+
+    Will delete all data in the db at the end of the scope of the
+    fixture.
+    """
+
+    with test_app.app_context():
+        yield
+
+        db.session.rollback()
+
+        # Remove all data, so that every test has access to a clean
+        # slate.
+        tables = db.engine.table_names()
+        tables = [t for t in tables if t != "alembic_version"]
+        tables = ",".join(tables)
+        db.engine.execute(f"TRUNCATE {tables};")
+        db.session.commit()
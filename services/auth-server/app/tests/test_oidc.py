@@ -0,0 +1,93 @@
+import time
+
+import pytest
+
+from app.connections import db
+from app.models import User
+from app.oidc import get_groups, get_or_create_user, is_allowed, validate_claims
+
+ISSUER_URL = "https://dex.example.com/dex"
+CLIENT_ID = "orchest"
+NONCE = "nonce"
+
+
+def get_claims(**kwargs):
+    claims = {
+        "iss": ISSUER_URL,
+        "sub": "CgNqYW5lEgVsb2NhbA",
+        "aud": CLIENT_ID,
+        "exp": time.time() + 60,
+        "nonce": NONCE,
+    }
+    claims.update(kwargs)
+    return claims
+
+
+@pytest.mark.parametrize(
+    "claims,nonce,valid",
+    [
+        (get_claims(), NONCE, True),
+        (get_claims(iss=ISSUER_URL + "/"), NONCE, True),
+        (get_claims(aud=["other", CLIENT_ID]), NONCE, True),
+        (get_claims(iss="https://evil.example.com/dex"), NONCE, False),
+        (get_claims(aud="other"), NONCE, False),
+        (get_claims(exp=time.time() - 60), NONCE, False),
+        (get_claims(), "other", False),
+        (get_claims(), None, False),
+        (get_claims(sub=""), NONCE, False),
+    ],
+)
+def test_validate_claims(claims, nonce, valid):
+    error = validate_claims(claims, ISSUER_URL, CLIENT_ID, nonce)
+
+    assert (error is None) == valid
+
+
+@pytest.mark.parametrize(
+    "groups,allowed_groups,allowed",
+    [
+        (get_groups({"groups": ["data-science"]}, "groups"), ["data-science"], True),
+        (get_groups({"groups": "data-science"}, "groups"), ["data-science"], True),
+        (get_groups({"groups": ["sales"]}, "groups"), ["data-science"], False),
+        (get_groups({}, "groups"), ["data-science"], False),
+        (get_groups({}, "groups"), [], True),
+    ],
+)
+def test_is_allowed(groups, allowed_groups, allowed):
+    assert is_allowed(groups, allowed_groups) == allowed
+
+
+def test_get_or_create_user_creates_user(app_context):
+    user = get_or_create_user(ISSUER_URL, "jane", "jane", False)
+
+    assert user.oidc_issuer == ISSUER_URL
+    assert user.oidc_subject == "jane"
+    assert User.query.filter(User.username == "jane").count() == 1
+
+
+def test_get_or_create_user_is_keyed_on_identity(app_context):
+    user = get_or_create_user(ISSUER_URL, "jane", "jane", False)
+
+    # The username claim changed at the provider.
+    assert get_or_create_user(ISSUER_URL, "jane", "jane.doe", True) is user
+    assert user.username == "jane"
+    assert user.is_admin
+
+
+def test_get_or_create_user_does_not_bind_local_user(app_context):
+    local_user = User(
+        username="admin", password_hash="hash", uuid="uuid", is_admin=True
+    )
+    db.session.add(local_user)
+    db.session.commit()
+
+    assert get_or_create_user(ISSUER_URL, "mallory", "admin", False) is None
+    assert local_user.is_admin
+    assert local_user.oidc_subject is None
+
+
+def test_get_or_create_user_does_not_bind_other_issuer(app_context):
+    get_or_create_user(ISSUER_URL, "jane", "jane", False)
+
+    assert get_or_create_user("https://other.example.com", "jane", "jane", True) is None
+    assert not User.query.filter(User.username == "jane").first().is_admin
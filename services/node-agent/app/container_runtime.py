@@ -1,7 +1,10 @@
 import asyncio
+import base64
 import json
 import logging
 import os
+import pty
+import shlex
 import time
 from asyncio import subprocess
 from enum import Enum
@@ -15,6 +18,7 @@ from _orchest.internals import config as _config
 class RuntimeType(Enum):
     Docker = "docker"
     Containerd = "containerd"
+    Crio = "cri-o"
 
 
 class ContainerRuntime(object):
@@ -31,6 +35,10 @@ class ContainerRuntime(object):
 
         self._aclient: Optional[aiodocker.Docker] = None
 
+        # The registry credentials in the docker config format, mounted
+        # by the controller.
+        self.registry_auth_file = os.getenv("REGISTRY_AUTH_FILE")
+
     @property
     def aclient(self):
         if self._aclient is None:
@@ -43,7 +51,7 @@ class ContainerRuntime(object):
             if self._aclient is not None:
                 await self._aclient.close()
                 self._aclient = None
-        elif self.container_runtime == RuntimeType.Containerd:
+        else:
             pass
 
     async def execute_cmd(self, **kwargs) -> Tuple[bool, Optional[str]]:
@@ -70,6 +78,64 @@ class ContainerRuntime(object):
 
         return returncode == 0, stdout
 
+    async def execute_cmd_with_password(
+        self, cmd: str, password: str
+    ) -> Tuple[bool, Optional[str]]:
+        """Run command in subprocess which prompts for a password.
+
+        ctr and crictl only read the password from a terminal, so the
+        command gets a pseudo terminal as stdin the password is written
+        to, which keeps it out of the command line of the process.
+
+        Returns:
+            Tuple of result and stdout of the command, the result is
+            True if the command where succfull.
+        """
+        master, slave = pty.openpty()
+        try:
+            process = await asyncio.create_subprocess_shell(
+                cmd, stdin=slave, stdout=subprocess.PIPE
+            )
+        finally:
+            os.close(slave)
+
+        try:
+            os.write(master, f"{password}\n".encode())
+            stdout, _ = await process.communicate()
+        finally:
+            os.close(master)
+
+        if stdout is not None:
+            stdout = stdout.decode().strip()
+
+        self.logger.debug(
+            f"excuted a command with returncide: {process.returncode} command: {cmd}"
+        )
+
+        return process.returncode == 0, stdout
+
+    def _get_registry_credentials(self, image_name: str) -> Optional[Tuple[str, str]]:
+        """Gets the credentials of the registry the image is pulled from.
+
+        Returns:
+            The username and password, or None if the registry does not
+            require credentials.
+        """
+        if self.registry_auth_file is None or not os.path.exists(
+            self.registry_auth_file
+        ):
+            return None
+
+        with open(self.registry_auth_file) as f:
+            auths = json.load(f).get("auths", {})
+
+        entry = auths.get(image_name.split("/", 1)[0])
+        if entry is None or "auth" not in entry:
+            return None
+
+        username, password = base64.b64decode(entry["auth"]).decode().split(":", 1)
+        return username, password
+
     async def image_exists(self, image_name: str) -> bool:
         """Checks for the existence of the named image using
         the configured container runtime.
@@ -88,7 +154,7 @@ class ContainerRuntime(object):
                 await self.aclient.images.inspect(image_name)
             except aiodocker.DockerError:
                 result = False
-        elif self.container_runtime == RuntimeType.Containerd:
+        elif self.container_runtime in [RuntimeType.Containerd, RuntimeType.Crio]:
             cmd = (
                 f"crictl -r unix://{self.container_runtime_socket} "
                 f"inspecti -q {image_name}"
@@ -113,10 +179,15 @@ class ContainerRuntime(object):
         result = True
         t0 = time.time()
 
+        credentials = self._get_registry_credentials(image_name)
+
         self._curr_pulling_imgs.add(image_name)
         if self.container_runtime == RuntimeType.Docker:
+            auth = None
+            if credentials is not None:
+                auth = {"username": credentials[0], "password": credentials[1]}
             try:
-                await self.aclient.images.pull(image_name)
+                await self.aclient.images.pull(image_name, auth=auth)
             except aiodocker.DockerError:
                 result = False
             finally:
@@ -126,7 +197,27 @@ class ContainerRuntime(object):
                 f"ctr -n k8s.io -a {self.container_runtime_socket} "
                 f"i pull {image_name} --skip-verify "
             )
-            result, _ = await self.execute_cmd(cmd=cmd)
+            if credentials is not None:
+                # ctr prompts for the password if only the user is given.
+                cmd += f"--user {shlex.quote(credentials[0])} "
+                result, _ = await self.execute_cmd_with_password(
+                    cmd, credentials[1]
+                )
+            else:
+                result, _ = await self.execute_cmd(cmd=cmd)
+        elif self.container_runtime == RuntimeType.Crio:
+            cmd = (
+                f"crictl -r unix://{self.container_runtime_socket} "
+                f"pull {image_name} "
+            )
+            if credentials is not None:
+                # crictl prompts for the password of the username.
+                cmd += f"--username {shlex.quote(credentials[0])} "
+                result, _ = await self.execute_cmd_with_password(
+                    cmd, credentials[1]
+                )
+            else:
+                result, _ = await self.execute_cmd(cmd=cmd)
 
         self._curr_pulling_imgs.remove(image_name)
 
@@ -160,7 +251,7 @@ class ContainerRuntime(object):
                         image_names.append(name)
             except aiodocker.DockerError:
                 pass
-        elif self.container_runtime == RuntimeType.Containerd:
+        elif self.container_runtime in [RuntimeType.Containerd, RuntimeType.Crio]:
             cmd = f"crictl -r unix://{self.container_runtime_socket} images -o=json"
             result, stdout = await self.execute_cmd(cmd=cmd)
             if result is True:
@@ -181,7 +272,7 @@ class ContainerRuntime(object):
                 await self.aclient.images.delete(image_name, force=True)
             except aiodocker.DockerError:
                 result = False
-        elif self.container_runtime == RuntimeType.Containerd:
+        elif self.container_runtime in [RuntimeType.Containerd, RuntimeType.Crio]:
             cmd = f"crictl -r unix://{self.container_runtime_socket} rmi {image_name}"
             result, _ = await self.execute_cmd(cmd=cmd)
 
@@ -45,7 +45,7 @@ def _get_orchest_version() -> Optional[str]:
 
 async def get_active_environment_images(session: aiohttp.ClientSession) -> Set[str]:
     """Gets the active environment images."""
-    endpoint = "http://orchest-api/api/environment-images/active"
+    endpoint = f"http://{_config.ORCHEST_API_ADDRESS}/api/environment-images/active"
     async with session.get(endpoint) as response:
         response_json = await response.json()
         active_images = response_json["active_environment_images"]
@@ -55,7 +55,9 @@ async def get_active_environment_images(session: aiohttp.ClientSession) -> Set[s
 
 async def get_active_custom_jupyter_images(session: aiohttp.ClientSession) -> Set[str]:
     """Gets the active custom jupyter images."""
-    endpoint = "http://orchest-api/api/ctl/orchest-images-to-pre-pull"
+    endpoint = (
+        f"http://{_config.ORCHEST_API_ADDRESS}/api/ctl/orchest-images-to-pre-pull"
+    )
     async with session.get(endpoint) as response:
         response_json = await response.json()
         pre_pull_images = response_json["pre_pull_images"]
@@ -1,6 +1,7 @@
 import asyncio
 import logging
 from enum import Enum
+from typing import List, Optional
 
 import aiohttp
 from container_runtime import ContainerRuntime
@@ -20,6 +21,7 @@ class ImagePuller(object):
         image_puller_log_level: str,
         image_puller_threadiness: int,
         orchest_api_host: str,
+        image_puller_images: Optional[List[str]] = None,
     ) -> None:
 
         """ImagePuller is started is responsible for pulling the
@@ -37,6 +39,8 @@ class ImagePuller(object):
             image_puller_log_level (str): The log level of the component
             orchest_api_host (str): The orchest-api url to be used for
                 fetching image names
+            image_puller_images (List[str]): Additional images to pull
+                along with the images fetched from the orchest-api.
         """
 
         self.interval = image_puller_interval
@@ -44,6 +48,7 @@ class ImagePuller(object):
         self.num_retries = image_puller_retries
         self.threadiness = image_puller_threadiness
         self.orchest_api_host = orchest_api_host
+        self.images = image_puller_images if image_puller_images is not None else []
         self.container_runtime = ContainerRuntime()
         self.logger = logging.getLogger("IMAGE_PULLER")
         self.logger.setLevel(image_puller_log_level)
@@ -54,7 +59,7 @@ class ImagePuller(object):
 
     async def get_image_names(self, queue: asyncio.Queue):
         """Fetches the image names by calling following endpoints
-        of the orchest-api.
+        of the orchest-api, in addition to the configured images.
             1. /ctl/orchest-images-to-pre-pull
             2. /environment-images/active
         Args:
@@ -65,6 +70,9 @@ class ImagePuller(object):
 
         async with aiohttp.ClientSession(trust_env=True) as session:
             while True:
+                for image_name in self.images:
+                    await queue.put(image_name)
+
                 try:
                     endpoint = (
                         f"{self.orchest_api_host}/api/ctl/orchest-images-to-pre-pull"
@@ -5,6 +5,8 @@ import logging
 from image_deleter import run as image_deleter_run
 from image_puller import ImagePuller, Policy
 
+from _orchest.internals import config as _config
+
 if __name__ == "__main__":
 
     logging.basicConfig(level=logging.DEBUG)
@@ -45,6 +47,13 @@ if __name__ == "__main__":
         default=3,
         type=int,
     )
+    parser.add_argument(
+        "--image-puller-image",
+        dest="image_puller_images",
+        action="append",
+        help="Specifies an additional image to pre-pull, can be repeated.",
+        default=[],
+    )
     parser.add_argument(
         "--image-puller-threadiness",
         dest="image_puller_threadiness",
@@ -56,8 +65,8 @@ if __name__ == "__main__":
         "--orchest-api-host",
         dest="orchest_api_host",
         nargs="?",
-        help="The the orchest api host. default http://orchest-api:80",
-        default="http://orchest-api:80",
+        help="The the orchest api host. default http://<cluster>-orchest-api:80",
+        default=f"http://{_config.ORCHEST_API_ADDRESS}:80",
     )
 
     arguments = vars(parser.parse_args())
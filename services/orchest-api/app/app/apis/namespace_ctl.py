@@ -1,8 +1,8 @@
 """API endpoints for unspecified orchest-api level information."""
 import os
-import shlex
-import subprocess
+from typing import Optional
 
+import requests
 from flask import current_app, request
 from flask_restx import Namespace, Resource
 from orchestcli import cmds
@@ -10,6 +10,8 @@ from orchestcli import cmds
 from _orchest.internals import config as _config
 from app import schema, utils
 from app.celery_app import make_celery
+from app.connections import db, k8s_custom_obj_api
+from app.core import environments, registry
 from config import CONFIG_CLASS
 
 ns = Namespace("ctl", description="Orchest-api internal control.")
@@ -26,15 +28,16 @@ class StartUpdate(Resource):
     )
     def post(self):
         try:
-            _run_update_in_venv(
+            _update_cluster_version(
                 namespace=_config.ORCHEST_NAMESPACE,
                 cluster_name=_config.ORCHEST_CLUSTER,
                 dev_mode=(os.getenv("FLASK_ENV") == "development"),
             )
-        except SystemExit:
+        except Exception as e:
             # This is a form of technical debt since we can't
             # distinguish if an update fails because there is no newer
             # version or a "real" failure.
+            current_app.logger.error(e)
             return {"message": "Failed to update."}, 500
 
         return {
@@ -96,6 +99,47 @@ class CleanupBuilderCache(Resource):
         return {}, 201
 
 
+@api.route("/registry-garbage-collection")
+class RegistryGarbageCollection(Resource):
+    @api.doc("start_registry_garbage_collection")
+    @ns.response(code=409, model=schema.dictionary, description="Ongoing builds")
+    def post(self):
+        """Prepares the registry for the garbage collection.
+
+        Used by the registry garbage collection job of the controller.
+        Puts the registry in maintenance, so that no images are pushed
+        during the garbage collection, and deletes the images which are
+        no longer referenced. Returns the repositories of which all the
+        images have been deleted, which the job removes from the
+        registry file system.
+        """
+        if utils.has_ongoing_image_builds():
+            return {"message": "Ongoing image builds."}, 409
+
+        registry.start_maintenance()
+        try:
+            environments.mark_env_images_that_can_be_removed()
+            utils.mark_custom_jupyter_images_to_be_removed()
+            db.session.commit()
+
+            _, repositories = environments.delete_unused_images_from_registry()
+        except Exception as e:
+            registry.stop_maintenance()
+            current_app.logger.error(e)
+            return {"message": f"{e}"}, 500
+
+        return {"repositories": repositories}, 200
+
+
+@api.route("/registry-garbage-collection/complete")
+class RegistryGarbageCollectionComplete(Resource):
+    @api.doc("complete_registry_garbage_collection")
+    def post(self):
+        """Takes the registry out of maintenance."""
+        registry.stop_maintenance()
+        return {}, 200
+
+
 @api.route("/orchest-settings")
 class OrchestSettings(Resource):
     @api.doc("get_orchest_settings")
@@ -153,89 +197,82 @@ class OrchestSettings(Resource):
         return resp, 200
 
 
-def _run_update_in_venv(namespace: str, cluster_name: str, dev_mode: bool):
-    """Runs `orchest update` in a virtualenv."""
+def _update_cluster_version(namespace: str, cluster_name: str, dev_mode: bool):
+    """Updates the Orchest Cluster to the latest version.
 
-    def run_cmds(**kwargs):
-        """Executes the provided commands.
+    The update is run by the orchest-controller, which is asked to
+    change the version of the cluster on behalf of the service account
+    of the orchest-api, so the orchest-api needs no cluster wide
+    permissions. The controller itself is updated through the CLI.
 
-        Basically runs and handles `subprocess.Popen(**kwargs)`
-
-        Args:
-            cmds: commands to execute.
+    Raises:
+        RuntimeError: If there is no newer version to update to or the
+            controller rejected the update.
+    """
+    cluster = k8s_custom_obj_api.get_namespaced_custom_object(
+        "orchest.io", "v1alpha1", namespace, "orchestclusters", cluster_name
+    )
+    curr_version = cluster["spec"]["orchest"]["version"]
 
-        Raises:
-            SystemExit: If `returncode` of the cmds execution is not
-                zero or fail_if_std_err is `True` and stderr is not
-                None.
-        """
-        process = subprocess.Popen(**kwargs)
-
-        if process.wait() != 0:
-            raise SystemExit(1)
-
-    if not dev_mode:
-        install_cmd = " && ".join(
-            [
-                "rm -rf /tmp/venv",
-                "python3 -m venv /tmp/venv",
-                "/tmp/venv/bin/pip install --upgrade orchest-cli",
-            ]
+    if dev_mode:
+        version = _get_dev_mode_version()
+    else:
+        webserver = cluster["spec"]["orchest"].get("orchestWebServer", {})
+        is_cloud = any(
+            env_var["name"] == "CLOUD"
+            and env_var.get("value") in ["True", "TRUE", "true"]
+            for env_var in webserver.get("env", [])
         )
-        # Use `shell=True` so we can use `&&` to chain commands.
-        run_cmds(args=install_cmd, shell=True)
+        version = cmds._fetch_latest_available_version(curr_version, is_cloud)
+
+    if version is None:
+        raise RuntimeError("Could not infer version to update to.")
+    elif version == curr_version:
+        raise RuntimeError(f"Orchest Cluster is already on version: {version}.")
+
+    with open(CONFIG_CLASS.SERVICE_ACCOUNT_TOKEN_FILE, "r") as f:
+        token = f.read().strip()
+
+    resp = requests.put(
+        f"{CONFIG_CLASS.ORCHEST_CONTROLLER_ADDRESS}/namespaces/{namespace}"
+        f"/clusters/{cluster_name}/version",
+        json={
+            "version": version,
+            "resourceVersion": cluster["metadata"]["resourceVersion"],
+        },
+        headers={"Authorization": f"Bearer {token}"},
+        timeout=10,
+    )
+    if resp.status_code != 202:
+        raise RuntimeError(f"The controller rejected the update: {resp.text}")
 
-    if dev_mode:
-        import yaml  # installed by orchest-cli
 
-        # Read the version to update to from the manifest. Without
-        # it you can't update in dev mode.
-        controller_deploy_path = (
-            "/orchest/services/orchest-controller/deploy/k8s/orchest-controller.yaml"
-        )
-        with open(controller_deploy_path, "r") as f:
-            txt_deploy_controller = f.read()
-
-        version = None
-        yml_deploy_controller = yaml.safe_load_all(txt_deploy_controller)
-        while True:
-            try:
-                obj = next(yml_deploy_controller)
-
-                if (
-                    obj is not None
-                    and obj["kind"] == "Deployment"
-                    # NOTE: We need to assume something to not change
-                    # in the controller deployment to be able to
-                    # distinguish it from other defined deployments in
-                    # the yaml file.
-                    and obj["metadata"]["name"] == "orchest-controller"
-                ):
-                    containers = obj["spec"]["template"]["spec"]["containers"]
-                    for container in containers:
-                        if container["name"] == "orchest-controller":
-                            version = container["image"].split(":")[-1]
-                    break
-            except StopIteration:
-                current_app.logger.error("Could not infer version to update to.")
-                raise SystemExit(1)
-
-        if version is None:
-            current_app.logger.error("Could not infer version to update to.")
-            raise SystemExit(1)
-
-        update_cmd = (
-            f"orchest update --dev --version={version}"
-            f" --no-watch --namespace={namespace} --cluster-name={cluster_name}"
-        )
+def _get_dev_mode_version() -> Optional[str]:
+    """Reads the version to update to from the controller manifest.
 
-        # `orchest update --dev` only works inside root level
-        # orchest folder
-        run_cmds(args=shlex.split(update_cmd), cwd="/orchest")
-    else:
-        update_cmd = (
-            "/tmp/venv/bin/orchest update"
-            f" --no-watch --namespace={namespace} --cluster-name={cluster_name}"
-        )
+    Without it you can't update in dev mode.
+    """
+    import yaml  # installed by orchest-cli
 
-        run_cmds(args=shlex.split(update_cmd))
+    controller_deploy_path = (
+        "/orchest/services/orchest-controller/deploy/k8s/orchest-controller.yaml"
+    )
+    with open(controller_deploy_path, "r") as f:
+        txt_deploy_controller = f.read()
+
+    for obj in yaml.safe_load_all(txt_deploy_controller):
+        if (
+            obj is not None
+            and obj["kind"] == "Deployment"
+            # NOTE: We need to assume something to not change in the
+            # controller deployment to be able to distinguish it from
+            # other defined deployments in the yaml file.
+            and obj["metadata"]["name"] == "orchest-controller"
+        ):
+            containers = obj["spec"]["template"]["spec"]["containers"]
+            for container in containers:
+                if container["name"] == "orchest-controller":
+                    return container["image"].split(":")[-1]
+            break
+
+    return None
@@ -1,4 +1,4 @@
-from typing import Dict, List, Optional, Set
+from typing import Dict, List, Optional, Set, Tuple
 
 from sqlalchemy import desc, func, tuple_
 
@@ -247,6 +247,78 @@ def get_active_environment_images() -> List[models.EnvironmentImage]:
     ).all()
 
 
+def delete_unused_images_from_registry() -> Tuple[bool, List[str]]:
+    """Deletes inactive env and custom Jupyter images from the registry.
+
+    Deleting an image only deletes its manifest, the registry garbage
+    collection needs to run to delete its layers. Should only be called
+    when no images are being pushed to the registry.
+
+    Returns:
+        Whether any image has been deleted and the repositories of which
+        all the tags have been deleted, which should be removed from the
+        registry file system after the garbage collection.
+    """
+    has_deleted_images = False
+    repositories_to_gc = []
+    repos = registry.get_list_of_repositories()
+
+    # Env images + custom Jupyter images.
+    repos_of_interest = [
+        repo
+        for repo in repos
+        if repo.startswith("orchest-env") or repo.startswith(_config.JUPYTER_IMAGE_NAME)
+    ]
+    active_env_images = get_active_environment_images()
+    active_env_image_names = set(
+        _config.ENVIRONMENT_IMAGE_NAME.format(
+            project_uuid=img.project_uuid, environment_uuid=img.environment_uuid
+        )
+        + f":{img.tag}"
+        for img in active_env_images
+    )
+    active_custom_jupyter_images = utils.get_active_custom_jupyter_images()
+    active_custom_jupyter_image_names = set(
+        f"{_config.JUPYTER_IMAGE_NAME}:{img.tag}"
+        for img in active_custom_jupyter_images
+    )
+
+    active_image_names = active_env_image_names.union(
+        active_custom_jupyter_image_names
+    )
+
+    # Go through all env image repos, for every tag, check if the
+    # image is in the active images, if not, delete it. If the
+    # image is not among the actives it means that it's either in
+    # the set of images where marked_for_removal = True, or the
+    # image isn't there at all, i.e. the project or environment has
+    # been deleted.
+    for repo in repos_of_interest:
+        tags = registry.get_tags_of_repository(repo)
+
+        all_tags_removed = True
+        for tag in tags:
+            name = f"{repo}:{tag}"
+            if name not in active_image_names:
+                logger.info(f"Deleting {name} from the registry.")
+                has_deleted_images = True
+                digest = registry.get_manifest_digest(repo, tag)
+                try:
+                    registry.delete_image_by_digest(
+                        repo, digest, run_garbage_collection=False
+                    )
+                except self_errors.ImageRegistryDeletionError as e:
+                    logger.warning(e)
+            else:
+                all_tags_removed = False
+                logger.info(f"Not deleting {name} from the registry.")
+
+        if all_tags_removed:
+            repositories_to_gc.append(repo)
+
+    return has_deleted_images, repositories_to_gc
+
+
 def _env_images_that_can_be_deleted(
     project_uuid: Optional[str] = None,
     environment_uuid: Optional[str] = None,
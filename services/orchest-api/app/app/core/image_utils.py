@@ -6,6 +6,7 @@ from _orchest.internals import config as _config
 from _orchest.internals.utils import get_userdir_relpath
 from app import errors, utils
 from app.connections import k8s_core_api, k8s_custom_obj_api
+from app.core import registry
 from config import CONFIG_CLASS
 
 # This way the builder pod is always scheduled on the same node as the
@@ -22,7 +23,12 @@ _registry_pod_affinity = {
                             "key": "app",
                             "operator": "In",
                             "values": ["docker-registry"],
-                        }
+                        },
+                        {
+                            "key": "release",
+                            "operator": "In",
+                            "values": [_config.REGISTRY_RELEASE],
+                        },
                     ]
                 },
                 "topologyKey": "kubernetes.io/hostname",
@@ -85,7 +91,7 @@ def _get_builder_cache_cleanup_workflow_manifest():
                 {
                     "name": "image-builder-cache-pvc",
                     "persistentVolumeClaim": {
-                        "claimName": "image-builder-cache-pvc",
+                        "claimName": _config.BUILDER_CACHE_PVC,
                     },
                 },
             ],
@@ -223,13 +229,13 @@ def _get_buildah_image_build_workflow_manifest(
                 {
                     "name": "userdir-pvc",
                     "persistentVolumeClaim": {
-                        "claimName": "userdir-pvc",
+                        "claimName": _config.USERDIR_PVC,
                     },
                 },
                 {
                     "name": "tls-secret",
                     "secret": {
-                        "secretName": "registry-tls-secret",
+                        "secretName": _config.REGISTRY_TLS_SECRET,
                         "items": [
                             {"key": "ca.crt", "path": "additional-ca-cert-bundle.crt"}
                         ],
@@ -239,6 +245,29 @@ def _get_buildah_image_build_workflow_manifest(
         },
     }
 
+    # Push with the registry credentials, if the registry requires them.
+    if CONFIG_CLASS.REGISTRY_CREDENTIALS_SECRET is not None:
+        manifest["spec"]["volumes"].append(
+            {
+                "name": "registry-credentials",
+                "secret": {
+                    "secretName": CONFIG_CLASS.REGISTRY_CREDENTIALS_SECRET,
+                    "items": [{"key": ".dockerconfigjson", "path": "auth.json"}],
+                },
+            }
+        )
+        container = manifest["spec"]["templates"][0]["container"]
+        container["volumeMounts"].append(
+            {
+                "name": "registry-credentials",
+                "mountPath": "/registry-credentials",
+                "readOnly": True,
+            }
+        )
+        container["env"] = [
+            {"name": "REGISTRY_AUTH_FILE", "value": "/registry-credentials/auth.json"}
+        ]
+
     # Mount docker.sock to pull from local docker daemon to enable
     # pulling base images of the form docker-daemon:<image>.
     if CONFIG_CLASS.DEV_MODE:
@@ -507,6 +536,9 @@ def build_image(
     Returns:
 
     """
+    # Images can't be pushed while the registry garbage collection runs.
+    registry.wait_for_maintenance()
+
     with open(complete_logs_path, "w") as complete_logs_file_object:
         try:
             ImageBuildSidecar(
@@ -28,9 +28,9 @@ from _orchest.internals.utils import (
     get_init_container_manifest,
     get_step_and_kernel_volumes_and_volume_mounts,
 )
-from app.connections import k8s_core_api, k8s_custom_obj_api
+from app.connections import k8s_custom_obj_api
 from app.types import PipelineDefinition, PipelineStepProperties, RunConfig
-from app.utils import get_logger
+from app.utils import get_logger, get_registry_ip
 from config import CONFIG_CLASS
 
 logger = get_logger()
@@ -446,9 +446,7 @@ def _step_to_workflow_manifest_task(
     # the container, and if the image is missing it will prompt a pull
     # which will fail because the FQDN can't be resolved by the local
     # engine on the node. K8S_TODO: fix this.
-    registry_ip = k8s_core_api.read_namespaced_service(
-        _config.REGISTRY, _config.ORCHEST_NAMESPACE
-    ).spec.cluster_ip
+    registry_ip = get_registry_ip()
 
     # The image of the step is the registry address plus the image name.
     image = (
@@ -4,7 +4,11 @@ Functions aren't a 1:1 mapping to the OCI API specs, but an abstracted
 version of what we actually need.
 """
 
-from typing import List, Optional
+import base64
+import json
+import os
+import time
+from typing import List, Optional, Tuple
 
 import requests
 from kubernetes import stream
@@ -19,18 +23,61 @@ logger = utils.get_logger()
 _VERIFY = CONFIG_CLASS.REGISTRY_TLS_CERT_BUNDLE
 
 
+def _get_registry_auth() -> Optional[Tuple[str, str]]:
+    """Gets the credentials of the registry from the auth file, if any.
+
+    The auth file is in the docker config format, e.g.
+    {"auths": {"<host>": {"auth": "<base64 of username:password>"}}}.
+    It is read on every request since the credentials can be rotated.
+    """
+    auth_file = CONFIG_CLASS.REGISTRY_AUTH_FILE
+    if auth_file is None or not os.path.exists(auth_file):
+        return None
+
+    with open(auth_file) as f:
+        auths = json.load(f).get("auths", {})
+
+    entry = auths.get(_config.REGISTRY_HOST)
+    if entry is None or "auth" not in entry:
+        return None
+
+    username, password = base64.b64decode(entry["auth"]).decode().split(":", 1)
+    return username, password
+
+
+def _get_repository_path(repository: str) -> str:
+    """Gets the path of the repository in the registry.
+
+    An external registry can require the repositories to be in a project
+    or under a prefix, e.g. harbor.example.com/orchest.
+    """
+    if _config.REGISTRY_REPOSITORY_PREFIX:
+        return f"{_config.REGISTRY_REPOSITORY_PREFIX}/{repository}"
+    return repository
+
+
 def get_list_of_repositories() -> List[str]:
     """Gets all repositories in the registry.
 
     At the moment this means environments and the user configured
-    jupyter image.
+    jupyter image. The repositories are returned without the prefix of
+    the registry, other repositories under the same host are skipped.
     """
+    prefix = _get_repository_path("")
     repos = []
     batch_size = 50
     next = f"/v2/_catalog?n={batch_size}"
     while next is not None:
-        resp = requests.get(f"{CONFIG_CLASS.REGISTRY_ADDRESS}{next}", verify=_VERIFY)
-        repos.extend(resp.json().get("repositories", []))
+        resp = requests.get(
+            f"{CONFIG_CLASS.REGISTRY_ADDRESS}{next}",
+            verify=_VERIFY,
+            auth=_get_registry_auth(),
+        )
+        repos.extend(
+            repo[len(prefix) :]
+            for repo in resp.json().get("repositories", [])
+            if repo.startswith(prefix)
+        )
         next = resp.links.get("next", {}).get("url")
     return repos
 
@@ -39,9 +86,13 @@ def get_tags_of_repository(repository: str) -> List[str]:
     """Gets all the tags of a repository."""
     tags = []
     batch_size = 50
-    next = f"/v2/{repository}/tags/list?n={batch_size}"
+    next = f"/v2/{_get_repository_path(repository)}/tags/list?n={batch_size}"
     while next is not None:
-        resp = requests.get(f"{CONFIG_CLASS.REGISTRY_ADDRESS}{next}", verify=_VERIFY)
+        resp = requests.get(
+            f"{CONFIG_CLASS.REGISTRY_ADDRESS}{next}",
+            verify=_VERIFY,
+            auth=_get_registry_auth(),
+        )
         # The "tags" entry can be missing if the repository has just
         # been created, None if there are no tags, or a list of strings.
         tags_batch = resp.json().get("tags", [])
@@ -57,8 +108,10 @@ def get_manifest(repository: str, tag: str) -> List[str]:
     Can be used, for example, to calculate total size of an image.
     """
     r = requests.get(
-        f"{CONFIG_CLASS.REGISTRY_ADDRESS}/v2/{repository}/manifests/{tag}",
+        f"{CONFIG_CLASS.REGISTRY_ADDRESS}/v2/{_get_repository_path(repository)}"
+        f"/manifests/{tag}",
         verify=_VERIFY,
+        auth=_get_registry_auth(),
         headers={"Accept": "application/vnd.docker.distribution.manifest.v2+json"},
     )
     return r.json()
@@ -73,8 +126,10 @@ def get_manifest_digest(repository: str, tag: str) -> Optional[str]:
     # 2.3 or later, the following header must be used when HEAD or
     # GET-ing the manifest to obtain the correct digest to delete.
     resp = requests.head(
-        f"{CONFIG_CLASS.REGISTRY_ADDRESS}/v2/{repository}/manifests/{tag}",
+        f"{CONFIG_CLASS.REGISTRY_ADDRESS}/v2/{_get_repository_path(repository)}"
+        f"/manifests/{tag}",
         verify=_VERIFY,
+        auth=_get_registry_auth(),
         headers={"Accept": "application/vnd.docker.distribution.manifest.v2+json"},
     )
     digest = resp.headers.get("Docker-Content-Digest")
@@ -94,8 +149,10 @@ def delete_image_by_digest(
     such deletion could end up deleting multiple tags.
     """
     resp = requests.delete(
-        f"{CONFIG_CLASS.REGISTRY_ADDRESS}/v2/{repository}/manifests/{digest}",
+        f"{CONFIG_CLASS.REGISTRY_ADDRESS}/v2/{_get_repository_path(repository)}"
+        f"/manifests/{digest}",
         verify=_VERIFY,
+        auth=_get_registry_auth(),
     )
     if resp.status_code not in [200, 202, 404]:
         raise errors.ImageRegistryDeletionError(resp)
@@ -128,7 +185,8 @@ def run_registry_garbage_collection(repositories: Optional[List[str]] = None) ->
         repositories = []
 
     pods = k8s_core_api.list_namespaced_pod(
-        _config.ORCHEST_NAMESPACE, label_selector="app=docker-registry"
+        _config.ORCHEST_NAMESPACE,
+        label_selector=f"app=docker-registry,release={_config.REGISTRY_RELEASE}",
     )
     for pod in pods.items:
         logger.info(f"Running garbage collection in pod: {pod.metadata.name}.")
@@ -169,3 +227,46 @@ def run_registry_garbage_collection(repositories: Optional[List[str]] = None) ->
                 tty=False,
             )
             logger.info(str(resp))
+
+
+def start_maintenance() -> None:
+    """Puts the registry in maintenance, no images are pushed until the
+    maintenance is stopped or times out.
+
+    Used by the controller to run the registry garbage collection, which
+    requires the registry not to be written to.
+    """
+    os.makedirs(os.path.dirname(CONFIG_CLASS.REGISTRY_MAINTENANCE_FILE), exist_ok=True)
+    with open(CONFIG_CLASS.REGISTRY_MAINTENANCE_FILE, "w") as f:
+        f.write(str(time.time()))
+
+
+def stop_maintenance() -> None:
+    """Takes the registry out of maintenance."""
+    try:
+        os.remove(CONFIG_CLASS.REGISTRY_MAINTENANCE_FILE)
+    except FileNotFoundError:
+        pass
+
+
+def is_in_maintenance() -> bool:
+    """Returns True if the registry is in maintenance.
+
+    A maintenance which has not been stopped in time, e.g. because the
+    garbage collection job got killed, is ignored.
+    """
+    try:
+        with open(CONFIG_CLASS.REGISTRY_MAINTENANCE_FILE) as f:
+            started_at = float(f.read())
+    except (FileNotFoundError, ValueError):
+        return False
+
+    return time.time() - started_at < CONFIG_CLASS.REGISTRY_MAINTENANCE_TIMEOUT
+
+
+def wait_for_maintenance() -> None:
+    """Blocks while the registry is in maintenance."""
+    if is_in_maintenance():
+        logger.info("Registry is in maintenance, waiting.")
+    while is_in_maintenance():
+        time.sleep(5)
@@ -353,22 +353,15 @@ def process_images_for_deletion(app) -> None:
         utils.mark_custom_jupyter_images_to_be_removed()
         db.session.commit()
 
+        # The controller runs the garbage collection itself, see the
+        # ctl/registry-garbage-collection endpoint.
+        if app.config["REGISTRY_GARBAGE_COLLECTION_MANAGED"]:
+            return
+
         # Don't queue the task if there are build tasks going, this is a
         # "cheap" way to avoid piling up multiple garbage collection
         # tasks while a build is ongoing.
-        if db.session.query(
-            db.session.query(models.EnvironmentImageBuild)
-            .filter(models.EnvironmentImageBuild.status.in_(["PENDING", "STARTED"]))
-            .exists()
-        ).scalar():
-            app.logger.info("Ongoing build, not queueing registry gc task.")
-            return
-
-        if db.session.query(
-            db.session.query(models.JupyterImageBuild)
-            .filter(models.JupyterImageBuild.status.in_(["PENDING", "STARTED"]))
-            .exists()
-        ).scalar():
+        if utils.has_ongoing_image_builds():
             app.logger.info("Ongoing build, not queueing registry gc task.")
             return
 
@@ -13,7 +13,6 @@ from typing import Any, Dict, Optional, Tuple
 from _orchest.internals import config as _config
 from _orchest.internals.utils import add_image_puller_if_needed, get_userdir_relpath
 from app import utils
-from app.connections import k8s_core_api
 from app.types import SessionConfig, SessionType
 from config import CONFIG_CLASS
 
@@ -354,14 +353,20 @@ def _get_jupyter_server_deployment_service_manifest(
                                     f'{metadata["name"]}'
                                 ),
                                 f"--notebook-dir={_config.PROJECT_DIR}",
-                                f'--ServerApp.base_url=/{metadata["name"]}',
+                                (
+                                    "--ServerApp.base_url="
+                                    f'{_config.ORCHEST_BASE_PATH}/{metadata["name"]}'
+                                ),
                             ],
                             "resources": {
                                 "requests": {"cpu": _config.USER_CONTAINERS_CPU_SHARES}
                             },
                             "startupProbe": {
                                 "httpGet": {
-                                    "path": f'/{metadata["name"]}/api',
+                                    "path": (
+                                        f"{_config.ORCHEST_BASE_PATH}/"
+                                        f'{metadata["name"]}/api'
+                                    ),
                                     "port": 8888,
                                 },
                                 "periodSeconds": 1,
@@ -407,7 +412,7 @@ def _get_jupyter_server_deployment_service_manifest(
                         "port": {"number": 80},
                     }
                 },
-                "path": f"/jupyter-server-{session_uuid}",
+                "path": f"{_config.ORCHEST_BASE_PATH}/jupyter-server-{session_uuid}",
                 "pathType": "Prefix",
             }
         ]
@@ -563,9 +568,7 @@ def _get_jupyter_enterprise_gateway_deployment_service_manifest(
     # run the container, and if the image is missing it will prompt
     # a pull which will fail because the FQDN can't be resolved by
     # the local engine on the node. K8S_TODO: fix this.
-    registry_ip = k8s_core_api.read_namespaced_service(
-        _config.REGISTRY, _config.ORCHEST_NAMESPACE
-    ).spec.cluster_ip
+    registry_ip = utils.get_registry_ip()
     environment = {
         "EG_MIRROR_WORKING_DIRS": "True",
         "EG_LIST_KERNELS": "True",
@@ -708,6 +711,10 @@ def _get_user_service_deployment_service_manifest(
     ingress_url = "service-" + service_config["name"] + "-" + session_uuid
     if is_pbp_enabled:
         ingress_url = "pbp-" + ingress_url
+    # The services are exposed under the base path Orchest is served
+    # at, which is also part of the path that pbp services receive.
+    if _config.ORCHEST_BASE_PATH:
+        ingress_url = _config.ORCHEST_BASE_PATH.lstrip("/") + "/" + ingress_url
 
     # Replace $BASE_PATH_PREFIX with service_base_url.  NOTE:
     # this substitution happens after service_config["name"] is read,
@@ -784,9 +791,7 @@ def _get_user_service_deployment_service_manifest(
         # run the container, and if the image is missing it will prompt
         # a pull which will fail because the FQDN can't be resolved by
         # the local engine on the node. K8S_TODO: fix this.
-        registry_ip = k8s_core_api.read_namespaced_service(
-            _config.REGISTRY, _config.ORCHEST_NAMESPACE
-        ).spec.cluster_ip
+        registry_ip = utils.get_registry_ip()
 
         image = image.replace(prefix, "")
         image = img_mappings[image]
@@ -900,14 +905,16 @@ def _get_user_service_deployment_service_manifest(
             # Needs to be the FQDN since the ingress ngin pod lives in
             # a different namespace.
             auth_url = (
-                f"http://auth-server.{_config.ORCHEST_NAMESPACE}.svc.cluster.local/auth"
+                f"http://{_config.ORCHEST_AUTH_SERVER_ADDRESS}."
+                f"{_config.ORCHEST_NAMESPACE}.svc.cluster.local"
+                f"{_config.ORCHEST_BASE_PATH}/auth"
             )
             ingress_metadata["annotations"][
                 "nginx.ingress.kubernetes.io/auth-url"
             ] = auth_url
             ingress_metadata["annotations"][
                 "nginx.ingress.kubernetes.io/auth-signin"
-            ] = "/login"
+            ] = f"{_config.ORCHEST_BASE_PATH}/login"
 
         ingress_rule = {}
         if _config.ORCHEST_FQDN is not None:
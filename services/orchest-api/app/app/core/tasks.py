@@ -13,7 +13,6 @@ from celery.utils.log import get_task_logger
 from _orchest.internals import config as _config
 from _orchest.internals.utils import copytree
 from app import create_app
-from app import errors as self_errors
 from app import utils
 from app.celery_app import make_celery
 from app.connections import k8s_custom_obj_api
@@ -158,7 +157,7 @@ def start_non_interactive_pipeline_run(
         pipeline_definition: A json description of the pipeline.
         run_config: Configuration of the run for the compute backend.
             Example: {
-                'userdir_pvc': 'userdir-pvc',
+                'userdir_pvc': 'orchest-cluster-userdir-pvc',
                 'project_dir': 'pipelines/uuid',
                 'env_uuid_to_image': {
                     'b6527b0b-bfcc-4aff-91d1-37f9dfd5d8e8':
@@ -297,63 +296,10 @@ def registry_garbage_collection(self) -> None:
     registry garbage collection is run if necessary.
     """
     with application.app_context():
-        has_deleted_images = False
-        repositories_to_gc = []
-        repos = registry.get_list_of_repositories()
-
-        # Env images + custom Jupyter images.
-        repos_of_interest = [
-            repo
-            for repo in repos
-            if repo.startswith("orchest-env")
-            or repo.startswith(_config.JUPYTER_IMAGE_NAME)
-        ]
-        active_env_images = environments.get_active_environment_images()
-        active_env_image_names = set(
-            _config.ENVIRONMENT_IMAGE_NAME.format(
-                project_uuid=img.project_uuid, environment_uuid=img.environment_uuid
-            )
-            + f":{img.tag}"
-            for img in active_env_images
-        )
-        active_custom_jupyter_images = utils.get_active_custom_jupyter_images()
-        active_custom_jupyter_image_names = set(
-            f"{_config.JUPYTER_IMAGE_NAME}:{img.tag}"
-            for img in active_custom_jupyter_images
-        )
-
-        active_image_names = active_env_image_names.union(
-            active_custom_jupyter_image_names
-        )
-
-        # Go through all env image repos, for every tag, check if the
-        # image is in the active images, if not, delete it. If the
-        # image is not among the actives it means that it's either in
-        # the set of images where marked_for_removal = True, or the
-        # image isn't there at all, i.e. the project or environment has
-        # been deleted.
-        for repo in repos_of_interest:
-            tags = registry.get_tags_of_repository(repo)
-
-            all_tags_removed = True
-            for tag in tags:
-                name = f"{repo}:{tag}"
-                if name not in active_image_names:
-                    logger.info(f"Deleting {name} from the registry.")
-                    has_deleted_images = True
-                    digest = registry.get_manifest_digest(repo, tag)
-                    try:
-                        registry.delete_image_by_digest(
-                            repo, digest, run_garbage_collection=False
-                        )
-                    except self_errors.ImageRegistryDeletionError as e:
-                        logger.warning(e)
-                else:
-                    all_tags_removed = False
-                    logger.info(f"Not deleting {name} from the registry.")
-
-            if all_tags_removed:
-                repositories_to_gc.append(repo)
+        (
+            has_deleted_images,
+            repositories_to_gc,
+        ) = environments.delete_unused_images_from_registry()
 
         if has_deleted_images or repositories_to_gc:
             registry.run_registry_garbage_collection(repositories_to_gc)
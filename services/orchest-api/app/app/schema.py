@@ -11,6 +11,7 @@ import sys
 
 from flask_restx import Model, Namespace, fields
 
+from _orchest.internals import config as _config
 from app import models, utils
 
 dictionary = Model("Dictionary", {})
@@ -48,9 +49,10 @@ pagination_data = Model(
 
 def _session_base_url(s) -> str:
     if isinstance(s, dict):
-        return "/jupyter-server-" + s["project_uuid"][:18] + s["pipeline_uuid"][:18]
+        session_uuid = s["project_uuid"][:18] + s["pipeline_uuid"][:18]
     else:
-        return "/jupyter-server-" + s.project_uuid[:18] + s.pipeline_uuid[:18]
+        session_uuid = s.project_uuid[:18] + s.pipeline_uuid[:18]
+    return f"{_config.ORCHEST_BASE_PATH}/jupyter-server-{session_uuid}"
 
 
 project = Model(
@@ -1,3 +1,4 @@
+import ipaddress
 import logging
 import os
 import time
@@ -221,6 +222,18 @@ def get_active_custom_jupyter_images() -> List[models.JupyterImage]:
     return custom_image
 
 
+def has_ongoing_image_builds() -> bool:
+    """Returns True if there are pending or started image builds."""
+    for model in [models.EnvironmentImageBuild, models.JupyterImageBuild]:
+        if db.session.query(
+            db.session.query(model)
+            .filter(model.status.in_(["PENDING", "STARTED"]))
+            .exists()
+        ).scalar():
+            return True
+    return False
+
+
 def get_jupyter_server_image_to_use() -> str:
     active_custom_images = get_active_custom_jupyter_images()
     if active_custom_images:
@@ -235,9 +248,20 @@ def get_jupyter_server_image_to_use() -> str:
 
 
 def get_registry_ip() -> str:
-    return k8s_core_api.read_namespaced_service(
+    """Returns the IP of the registry service as used in image names.
+
+    IPv6 addresses are enclosed in brackets. If an external registry is
+    used its address, including the repository prefix, is returned.
+    """
+    if _config.IMAGE_REGISTRY:
+        return _config.IMAGE_REGISTRY
+
+    registry_ip = k8s_core_api.read_namespaced_service(
         _config.REGISTRY, _config.ORCHEST_NAMESPACE
     ).spec.cluster_ip
+    if ipaddress.ip_address(registry_ip).version == 6:
+        return f"[{registry_ip}]"
+    return registry_ip
 
 
 def _set_celery_worker_parallelism_at_runtime(
@@ -0,0 +1,83 @@
+import pytest
+
+from _orchest.internals import config as _config
+from _orchest.internals.utils import add_image_puller_if_needed
+from app import utils
+from app.core import registry
+from config import CONFIG_CLASS
+
+
+class MockResponse:
+    def __init__(self, json, links=None):
+        self._json = json
+        self.links = links if links is not None else {}
+
+    def json(self):
+        return self._json
+
+
+@pytest.fixture()
+def external_registry(monkeypatch):
+    monkeypatch.setattr(_config, "IMAGE_REGISTRY", "harbor.example.com/orchest")
+    monkeypatch.setattr(_config, "REGISTRY_HOST", "harbor.example.com")
+    monkeypatch.setattr(_config, "REGISTRY_REPOSITORY_PREFIX", "orchest")
+    monkeypatch.setattr(CONFIG_CLASS, "REGISTRY_ADDRESS", "https://harbor.example.com")
+
+
+def test_get_registry_ip_external_registry(external_registry):
+    assert utils.get_registry_ip() == "harbor.example.com/orchest"
+
+
+def test_get_list_of_repositories_external_registry(external_registry, monkeypatch):
+    monkeypatch.setattr(
+        registry.requests,
+        "get",
+        lambda url, **kwargs: MockResponse(
+            {
+                "repositories": [
+                    "orchest/orchest-env-proj-env",
+                    "orchest/orchest-jupyter-server-user-configured",
+                    "other/orchest-env-proj-env",
+                ]
+            }
+        ),
+    )
+
+    assert registry.get_list_of_repositories() == [
+        "orchest-env-proj-env",
+        "orchest-jupyter-server-user-configured",
+    ]
+
+
+def test_get_tags_of_repository_external_registry(external_registry, monkeypatch):
+    urls = []
+
+    def get(url, **kwargs):
+        urls.append(url)
+        return MockResponse({"tags": ["1", "2"]})
+
+    monkeypatch.setattr(registry.requests, "get", get)
+
+    assert registry.get_tags_of_repository("orchest-env-proj-env") == ["1", "2"]
+    assert urls == [
+        "https://harbor.example.com/v2/orchest/orchest-env-proj-env/tags/list?n=50"
+    ]
+
+
+@pytest.mark.parametrize(
+    "image,registry_ip,expected",
+    [
+        ("10.0.0.1/orchest-env-proj-env:1", "10.0.0.1", True),
+        ("harbor.example.com/orchest/env:1", "harbor.example.com/orchest", True),
+        ("harbor.example.com/other/env:1", "harbor.example.com/orchest", False),
+        ("docker.io/orchest/jupyter-server:v1", "harbor.example.com/orchest", False),
+    ],
+)
+def test_add_image_puller_if_needed(image, registry_ip, expected):
+    manifest = {"spec": {"template": {"spec": {}}}}
+
+    add_image_puller_if_needed(
+        image, registry_ip, "containerd", "orchest/image-puller", manifest
+    )
+
+    assert ("initContainers" in manifest["spec"]["template"]["spec"]) == expected
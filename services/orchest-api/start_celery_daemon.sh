@@ -1,8 +1,8 @@
 #!/bin/bash
 
 set -e
-JP=$(curl http://orchest-api/api/ctl/orchest-settings -s | jq -r .MAX_JOB_RUNS_PARALLELISM)
-IP=$(curl http://orchest-api/api/ctl/orchest-settings -s | jq -r .MAX_INTERACTIVE_RUNS_PARALLELISM)
+JP=$(curl http://${ORCHEST_CLUSTER}-orchest-api/api/ctl/orchest-settings -s | jq -r .MAX_JOB_RUNS_PARALLELISM)
+IP=$(curl http://${ORCHEST_CLUSTER}-orchest-api/api/ctl/orchest-settings -s | jq -r .MAX_INTERACTIVE_RUNS_PARALLELISM)
 
 if [ -z "${JP}" ]; then
 	exit 11;
@@ -42,6 +42,9 @@ func NewControllerCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&controllerConfig.RabbitmqDefaultImage,
 		"rabbitmqImage", controllerConfig.RabbitmqDefaultImage, "The default rabbitmq image if not provided in CR")
 
+	cmd.PersistentFlags().StringVar(&controllerConfig.ProxyDefaultImage,
+		"proxyImage", controllerConfig.ProxyDefaultImage, "The default orchest-proxy image if not provided in CR")
+
 	cmd.PersistentFlags().StringVar(&controllerConfig.UserdirDefaultVolumeSize,
 		"userdirSize", controllerConfig.UserdirDefaultVolumeSize, "The default size for userdir pvc")
 
@@ -99,6 +102,9 @@ func NewControllerCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&addonsConfig.AssetDir,
 		"assetDir", addonsConfig.AssetDir, "The directory of assets")
 
+	cmd.PersistentFlags().StringVar(&addonsConfig.ChartCacheDir,
+		"chartCacheDir", addonsConfig.ChartCacheDir, "The directory remote helm charts are cached in")
+
 	cmd.PersistentFlags().StringVar(&addonsConfig.DefaultNamespace,
 		"namespace", addonsConfig.DefaultNamespace, "The default namespace for installing addons")
 
@@ -129,13 +135,15 @@ func runControllerCmd() error {
 	dsInformer := utils.NewDaemonSetInformer(informerFactory)
 	// Create Ingress Informer
 	ingInformer := utils.NewIngressInformer(informerFactory)
+	// Create Node Informer
+	nodeInformer := utils.NewNodeInformer(informerFactory)
 
 	oClusterInformer := utils.NewOrchestClusterInformer(oClient)
 
 	//Create OrchestCluster Informer
 	oComponentInformer := utils.NewOrchestComponentInformer(oClient)
 
-	addonManager := addons.NewAddonManager(kClient, addonsConfig)
+	addonManager := addons.NewAddonManager(kClient, gClient, addonsConfig)
 
 	oClusterController := orchestcluster.NewOrchestClusterController(kClient,
 		oClient,
@@ -144,6 +152,7 @@ func runControllerCmd() error {
 		controllerConfig,
 		oClusterInformer,
 		oComponentInformer,
+		nodeInformer,
 		addonManager)
 
 	oComponentController := orchestcomponent.NewOrchestComponentController(kClient,
@@ -154,9 +163,10 @@ func runControllerCmd() error {
 		svcInformer,
 		depInformer,
 		dsInformer,
-		ingInformer)
+		ingInformer,
+		nodeInformer)
 
-	server := server.NewServer(serverConfig, oClusterInformer)
+	server := server.NewServer(serverConfig, kClient, oClient, oClusterInformer, oComponentInformer)
 
 	// Start the addon manager
 	go addonManager.Run(stopCh)
@@ -174,6 +184,7 @@ func runControllerCmd() error {
 	go dsInformer.Informer().Run(stopCh)
 	go svcInformer.Informer().Run(stopCh)
 	go ingInformer.Informer().Run(stopCh)
+	go nodeInformer.Informer().Run(stopCh)
 
 	// Start webserver
 	go server.Run(stopCh)
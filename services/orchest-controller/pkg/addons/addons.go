@@ -3,16 +3,34 @@ package addons
 import (
 	"context"
 	"path"
+	"sync"
 
 	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/pkg/errors"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var (
 	// list of all addons
 	ArgoWorkflow   = "argo-workflow"
 	DockerRegistry = "docker-registry"
+	CertManager    = "cert-manager"
+	IngressNginx   = "ingress-nginx"
+
+	// The default charts of the addons which are not bundled with the controller
+	certManagerChart = orchestv1alpha1.ApplicationConfigHelm{
+		RepoURL: "https://charts.jetstack.io",
+		Chart:   "cert-manager",
+		Version: "v1.8.2",
+	}
+
+	ingressNginxChart = orchestv1alpha1.ApplicationConfigHelm{
+		RepoURL: "https://kubernetes.github.io/ingress-nginx",
+		Chart:   "ingress-nginx",
+		Version: "4.1.4",
+	}
 )
 
 type AddonsConfig struct {
@@ -22,6 +40,9 @@ type AddonsConfig struct {
 
 	AssetDir string
 
+	// The directory remote helm charts are cached in
+	ChartCacheDir string
+
 	DefaultNamespace string
 }
 
@@ -31,6 +52,8 @@ func NewDefaultAddonsConfig() AddonsConfig {
 
 		AssetDir: "/deploy",
 
+		ChartCacheDir: "/tmp/orchest/charts",
+
 		DefaultNamespace: "orchest",
 	}
 }
@@ -41,21 +64,42 @@ type Addon interface {
 	// Enable the addon. preInstallHooks should be called, before enabling the addon
 	Enable(ctx context.Context, preInstallHooks []PreInstallHookFn, namespace string, app *orchestv1alpha1.ApplicationSpec) error
 
-	// Uninstall the addon
-	Uninstall(ctx context.Context, namespace string) error
+	// Uninstall the addon deployed with the given application config
+	Uninstall(ctx context.Context, namespace string, app *orchestv1alpha1.ApplicationSpec) error
+}
+
+// StatusReporter is implemented by addons which report the state of their deployment
+type StatusReporter interface {
+	// Status returns the state of the addon deployed with the given application config, or nil
+	// if the addon is not deployed
+	Status(ctx context.Context, namespace string,
+		app *orchestv1alpha1.ApplicationSpec) (*orchestv1alpha1.ApplicationStatus, error)
 }
 
 // AddonManager holds the map of deployers
 type AddonManager struct {
-	config AddonsConfig
-	addons map[string]Addon
+	config     AddonsConfig
+	client     kubernetes.Interface
+	gClient    client.Client
+	chartCache *ChartCache
+	lock       sync.Mutex
+	addons     map[string]Addon
+
+	// applicationAddons holds the addons resolved for the applications, by namespace, name
+	// and kind of config
+	applicationAddons map[string]Addon
 }
 
-func NewAddonManager(client kubernetes.Interface, config AddonsConfig) *AddonManager {
+func NewAddonManager(client kubernetes.Interface, gClient client.Client, config AddonsConfig) *AddonManager {
 
 	addonManager := AddonManager{
-		config: config,
-		addons: make(map[string]Addon),
+		config:     config,
+		client:     client,
+		gClient:    gClient,
+		chartCache: NewChartCache(client, config.ChartCacheDir),
+		addons:     make(map[string]Addon),
+
+		applicationAddons: make(map[string]Addon),
 	}
 
 	addonManager.AddAddon(ArgoWorkflow,
@@ -68,6 +112,18 @@ func NewAddonManager(client kubernetes.Interface, config AddonsConfig) *AddonMan
 			path.Join(config.AssetDir, "thirdparty/docker-registry/helm"),
 			path.Join(config.AssetDir, "thirdparty/docker-registry/orchest-values.yaml")))
 
+	addonManager.AddAddon(CertManager,
+		NewDetectingAddon(client, CertManager, detectCertManager,
+			NewRemoteHelmDeployer(client, CertManager, &certManagerChart,
+				path.Join(config.AssetDir, "thirdparty/cert-manager/orchest-values.yaml"),
+				addonManager.chartCache)))
+
+	addonManager.AddAddon(IngressNginx,
+		NewDetectingAddon(client, IngressNginx, detectIngressNginx,
+			NewRemoteHelmDeployer(client, IngressNginx, &ingressNginxChart,
+				path.Join(config.AssetDir, "thirdparty/ingress-nginx/orchest-values.yaml"),
+				addonManager.chartCache)))
+
 	return &addonManager
 }
 
@@ -90,6 +146,9 @@ func (m *AddonManager) Run(stopCh <-chan struct{}) {
 // add an addon, if not already registred with the manager
 func (m *AddonManager) AddAddon(name string, addon Addon) {
 
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	// If already registred, log warning and return
 	if _, ok := m.addons[name]; ok {
 		klog.Warningf("addon %s is already registred with addon manager", name)
@@ -105,7 +164,7 @@ func (m *AddonManager) EnableAddon(ctx context.Context, addonName, namespace str
 	funcCtx, cancel := context.WithCancel(ctx)
 
 	go func(ctx context.Context, cancel context.CancelFunc) {
-		if addon, ok := m.addons[addonName]; ok {
+		if addon := m.Get(addonName); addon != nil {
 			// addon is registered with the addon manager
 			err := addon.Enable(ctx, nil, namespace, nil)
 			if err != nil {
@@ -113,7 +172,7 @@ func (m *AddonManager) EnableAddon(ctx context.Context, addonName, namespace str
 				cancel()
 			} else {
 				klog.Infof("addon is enabled %s", addonName)
-				defer addon.Uninstall(ctx, namespace)
+				defer addon.Uninstall(ctx, namespace, nil)
 			}
 		} else {
 			klog.Errorf("addon is not enabled with the addon manager addon: %s", addonName)
@@ -126,5 +185,46 @@ func (m *AddonManager) EnableAddon(ctx context.Context, addonName, namespace str
 
 // get a deployer by the name
 func (m *AddonManager) Get(name string) Addon {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	return m.addons[name]
 }
+
+// ResolveAddon returns the addon of the application in the namespace, if the application is
+// not one of the registered addons but defines a remote helm chart or manifests, an addon is
+// created for it. The addon is created again if the kind of config of the application changes.
+func (m *AddonManager) ResolveAddon(namespace string, app *orchestv1alpha1.ApplicationSpec) (Addon, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if addon, ok := m.addons[app.Name]; ok {
+		return addon, nil
+	}
+
+	var kind string
+	var newAddon func() Addon
+	if isRemoteChart(app.Config.Helm) {
+		kind = "helm"
+		newAddon = func() Addon {
+			return NewRemoteHelmDeployer(m.client, app.Name, nil, "", m.chartCache)
+		}
+	} else if app.Config.Manifests != nil {
+		kind = "manifests"
+		newAddon = func() Addon {
+			return NewPathDeployer(m.client, m.gClient, app.Name, m.config.AssetDir, "")
+		}
+	} else {
+		return nil, errors.Errorf("application %s is not a known addon and defines no chart or manifests", app.Name)
+	}
+
+	key := namespace + "/" + app.Name + "/" + kind
+	if addon, ok := m.applicationAddons[key]; ok {
+		return addon, nil
+	}
+
+	addon := newAddon()
+	m.applicationAddons[key] = addon
+	klog.V(2).Infof("addon %s of namespace %s is registred with addon manager", app.Name, namespace)
+	return addon, nil
+}
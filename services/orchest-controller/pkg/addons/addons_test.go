@@ -0,0 +1,51 @@
+package addons
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveAddon(t *testing.T) {
+
+	manager := NewAddonManager(fake.NewSimpleClientset(), nil, AddonsConfig{ChartCacheDir: t.TempDir()})
+
+	chartApp := &orchestv1alpha1.ApplicationSpec{
+		Name: "monitoring",
+		Config: orchestv1alpha1.ApplicationConfig{
+			Helm: &orchestv1alpha1.ApplicationConfigHelm{
+				RepoURL: "https://charts.example.com/stable",
+				Chart:   "monitoring",
+			},
+		},
+	}
+	manifestsApp := &orchestv1alpha1.ApplicationSpec{
+		Name: "monitoring",
+		Config: orchestv1alpha1.ApplicationConfig{
+			Manifests: &orchestv1alpha1.ApplicationConfigManifests{},
+		},
+	}
+
+	chartAddon, err := manager.ResolveAddon("orchest", chartApp)
+	assert.NoError(t, err)
+	assert.IsType(t, &HelmDeployer{}, chartAddon)
+
+	addon, err := manager.ResolveAddon("orchest", chartApp)
+	assert.NoError(t, err)
+	assert.Same(t, chartAddon, addon)
+
+	// The application of another namespace gets its own addon
+	addon, err = manager.ResolveAddon("default", chartApp)
+	assert.NoError(t, err)
+	assert.NotSame(t, chartAddon, addon)
+
+	// The addon follows the kind of config of the application
+	addon, err = manager.ResolveAddon("orchest", manifestsApp)
+	assert.NoError(t, err)
+	assert.IsType(t, &PathDeployer{}, addon)
+
+	_, err = manager.ResolveAddon("orchest", &orchestv1alpha1.ApplicationSpec{Name: "unknown"})
+	assert.Error(t, err)
+}
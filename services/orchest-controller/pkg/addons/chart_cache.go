@@ -0,0 +1,357 @@
+package addons
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/helm"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+var (
+	ociScheme = "oci://"
+
+	// The keys of the repository credentials secret
+	usernameKey = "username"
+	passwordKey = "password"
+
+	// The name of the file holding the checksum of the cached chart
+	checksumFile = "chart.sha256"
+
+	// The name of the temporary helm repository holding the credentials of a repository
+	credentialsRepoName = "orchest-chart-repo"
+
+	// The time the charts without a pinned version are cached for, after which the latest
+	// version is pulled again
+	unpinnedChartTTL = time.Hour
+
+	unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// ChartCache fetches remote helm charts and keeps them on the local disk, so the charts are
+// only downloaded once per version. The charts pulled with credentials are only shared by the
+// applications using the same credentials secret.
+type ChartCache struct {
+	client kubernetes.Interface
+	dir    string
+	lock   sync.Mutex
+}
+
+func NewChartCache(client kubernetes.Interface, dir string) *ChartCache {
+	return &ChartCache{
+		client: client,
+		dir:    dir,
+	}
+}
+
+// isRemoteChart returns true if the chart of the application needs to be fetched from a
+// repository or an OCI registry
+func isRemoteChart(config *orchestv1alpha1.ApplicationConfigHelm) bool {
+	return config != nil && config.RepoURL != ""
+}
+
+// Fetch returns the path of the chart archive in the cache, the chart is pulled from the
+// repository if it is not cached yet, if the cached archive does not match its checksum or if
+// the chart has no pinned version and was cached longer than unpinnedChartTTL ago.
+func (c *ChartCache) Fetch(ctx context.Context, namespace string,
+	config *orchestv1alpha1.ApplicationConfigHelm) (string, error) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	chartDir := c.chartDir(namespace, config)
+
+	// The outdated chart is used if the latest version can not be pulled
+	var staleChartPath string
+
+	chartPath, err := getCachedChart(chartDir, config.Digest)
+	if err == nil && config.Version == "" {
+		if err = checkUnpinnedChartAge(chartDir, time.Now()); err != nil {
+			staleChartPath = chartPath
+		}
+	}
+	if err == nil {
+		return chartPath, nil
+	}
+	klog.V(2).Infof("chart %s is not cached, reason: %v", config.Chart, err)
+
+	chartPath, err = c.pullIntoCache(ctx, namespace, chartDir, config)
+	if err != nil && staleChartPath != "" {
+		klog.Warningf("failed to pull the latest version of chart %s, using the cached one, error: %v",
+			config.Chart, err)
+		return staleChartPath, nil
+	}
+
+	return chartPath, err
+}
+
+// pullIntoCache pulls the chart and moves it into the chartDir of the cache
+func (c *ChartCache) pullIntoCache(ctx context.Context, namespace, chartDir string,
+	config *orchestv1alpha1.ApplicationConfigHelm) (string, error) {
+
+	// The chart is pulled into a temporary directory first, so a failed or partial pull
+	// never ends up in the cache
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return "", errors.Wrapf(err, "failed to create chart cache directory %s", c.dir)
+	}
+
+	tmpDir, err := os.MkdirTemp(c.dir, "pull-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = c.pull(ctx, namespace, tmpDir, config)
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := findChartArchive(tmpDir)
+	if err != nil {
+		return "", err
+	}
+
+	checksum, err := computeChecksum(archive)
+	if err != nil {
+		return "", err
+	}
+
+	if config.Digest != "" && !strings.EqualFold(normalizeDigest(config.Digest), checksum) {
+		return "", errors.Errorf("checksum mismatch for chart %s, expected %s got %s",
+			config.Chart, config.Digest, checksum)
+	}
+
+	if err = os.RemoveAll(chartDir); err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(chartDir), 0755); err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(filepath.Join(tmpDir, checksumFile), []byte(checksum), 0644)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write the chart checksum")
+	}
+
+	if err = os.Rename(tmpDir, chartDir); err != nil {
+		return "", errors.Wrapf(err, "failed to move chart %s into the cache", config.Chart)
+	}
+
+	return filepath.Join(chartDir, filepath.Base(archive)), nil
+}
+
+// pull downloads the chart into the dst directory
+func (c *ChartCache) pull(ctx context.Context, namespace, dst string,
+	config *orchestv1alpha1.ApplicationConfigHelm) error {
+
+	username, password, err := c.getCredentials(ctx, namespace, config.CredentialsSecret)
+	if err != nil {
+		return err
+	}
+
+	pullArgs := helm.NewHelmArgBuilder().WithPull()
+
+	// The credentials are only stored in temporary configs, which the chart is pulled through,
+	// so they are not reused by the pulls of other applications
+	var credentialsDir string
+	if username != "" {
+		credentialsDir, err = os.MkdirTemp(c.dir, "credentials-")
+		if err != nil {
+			return errors.Wrap(err, "failed to create temporary directory")
+		}
+		defer os.RemoveAll(credentialsDir)
+	}
+
+	// The passwords are passed on stdin, so they are not visible in the process arguments
+	if strings.HasPrefix(config.RepoURL, ociScheme) {
+		// OCI registries do not accept credentials on pull, login is required beforehand
+		if username != "" {
+			registryConfig := filepath.Join(credentialsDir, "registry.json")
+
+			host := strings.SplitN(strings.TrimPrefix(config.RepoURL, ociScheme), "/", 2)[0]
+			loginArgs := helm.NewHelmArgBuilder().WithRegistryLogin().
+				WithName(host).
+				WithPasswordStdin(username).
+				WithRegistryConfig(registryConfig)
+
+			_, err = helm.RunCommandWithInput(ctx, loginArgs.Build(), password)
+			if err != nil {
+				return errors.Wrapf(err, "failed to login to registry %s", host)
+			}
+
+			pullArgs.WithRegistryConfig(registryConfig)
+		}
+
+		pullArgs.WithRepository(strings.TrimSuffix(config.RepoURL, "/") + "/" + config.Chart)
+	} else if username != "" {
+		repoConfig := filepath.Join(credentialsDir, "repositories.yaml")
+		repoCache := filepath.Join(credentialsDir, "cache")
+
+		addArgs := helm.NewHelmArgBuilder().WithRepoAdd().
+			WithName(credentialsRepoName).
+			WithRepository(config.RepoURL).
+			WithPasswordStdin(username).
+			WithRepositoryConfig(repoConfig, repoCache)
+
+		_, err = helm.RunCommandWithInput(ctx, addArgs.Build(), password)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add repository %s", config.RepoURL)
+		}
+
+		pullArgs.WithRepository(credentialsRepoName+"/"+config.Chart).
+			WithRepositoryConfig(repoConfig, repoCache)
+	} else {
+		pullArgs.WithRepository(config.Chart).WithRepoURL(config.RepoURL)
+	}
+
+	if config.Version != "" {
+		pullArgs.WithChartVersion(config.Version)
+	}
+
+	pullArgs.WithDestination(dst)
+
+	klog.Infof("Pulling chart %s from %s", config.Chart, config.RepoURL)
+	_, err = helm.RunCommand(ctx, pullArgs.Build())
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull chart %s", config.Chart)
+	}
+
+	return nil
+}
+
+// getCredentials returns the username and password stored in the credentials secret
+func (c *ChartCache) getCredentials(ctx context.Context, namespace, secretName string) (string, string, error) {
+	if secretName == "" {
+		return "", "", nil
+	}
+
+	secret, err := c.client.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get repository credentials secret %s", secretName)
+	}
+
+	username, ok := secret.Data[usernameKey]
+	if !ok {
+		return "", "", errors.Errorf("repository credentials secret %s has no %s key", secretName, usernameKey)
+	}
+
+	return string(username), string(secret.Data[passwordKey]), nil
+}
+
+// chartDir returns the directory of the chart in the cache, the charts pulled with credentials
+// are kept apart per namespace and credentials secret
+func (c *ChartCache) chartDir(namespace string, config *orchestv1alpha1.ApplicationConfigHelm) string {
+	repo := config.RepoURL
+	if u, err := url.Parse(repo); err == nil && u.Host != "" {
+		repo = u.Host + u.Path
+	}
+
+	version := config.Version
+	if version == "" {
+		version = "latest"
+	}
+
+	scope := "public"
+	if config.CredentialsSecret != "" {
+		scope = filepath.Join("private",
+			unsafePathChars.ReplaceAllString(namespace, "_"),
+			unsafePathChars.ReplaceAllString(config.CredentialsSecret, "_"))
+	}
+
+	return filepath.Join(c.dir, scope,
+		unsafePathChars.ReplaceAllString(repo, "_"),
+		unsafePathChars.ReplaceAllString(config.Chart, "_"),
+		unsafePathChars.ReplaceAllString(version, "_"))
+}
+
+// getCachedChart returns the path of the cached chart archive if it is present and matches
+// both the stored checksum and the expected digest.
+func getCachedChart(chartDir, digest string) (string, error) {
+	archive, err := findChartArchive(chartDir)
+	if err != nil {
+		return "", err
+	}
+
+	stored, err := os.ReadFile(filepath.Join(chartDir, checksumFile))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the chart checksum")
+	}
+
+	checksum, err := computeChecksum(archive)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(string(stored)) != checksum {
+		return "", errors.Errorf("cached chart %s is corrupted", archive)
+	}
+
+	if digest != "" && !strings.EqualFold(normalizeDigest(digest), checksum) {
+		return "", errors.Errorf("cached chart %s does not match digest %s", archive, digest)
+	}
+
+	return archive, nil
+}
+
+// checkUnpinnedChartAge returns an error if the chart without a pinned version was cached
+// longer than unpinnedChartTTL ago, so the latest version is pulled again.
+func checkUnpinnedChartAge(chartDir string, now time.Time) error {
+	info, err := os.Stat(filepath.Join(chartDir, checksumFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to stat the chart checksum")
+	}
+
+	if now.Sub(info.ModTime()) > unpinnedChartTTL {
+		return errors.Errorf("cached chart in %s is older than %s", chartDir, unpinnedChartTTL)
+	}
+
+	return nil
+}
+
+// findChartArchive returns the first chart archive in the directory
+func findChartArchive(dir string) (string, error) {
+	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(archives) == 0 {
+		return "", errors.Errorf("no chart archive found in %s", dir)
+	}
+
+	return archives[0], nil
+}
+
+func computeChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", errors.Wrapf(err, "failed to compute checksum of %s", path)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// normalizeDigest strips the optional algorithm prefix of the digest
+func normalizeDigest(digest string) string {
+	return strings.TrimPrefix(digest, "sha256:")
+}
@@ -0,0 +1,148 @@
+package addons
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartCacheFetchCached(t *testing.T) {
+
+	config := &orchestv1alpha1.ApplicationConfigHelm{
+		RepoURL: "https://charts.example.com/stable",
+		Chart:   "minio",
+		Version: "4.0.2",
+	}
+
+	cache := NewChartCache(nil, t.TempDir())
+	chartDir := cache.chartDir("orchest", config)
+	require.NoError(t, os.MkdirAll(chartDir, 0755))
+
+	archive := filepath.Join(chartDir, "minio-4.0.2.tgz")
+	require.NoError(t, os.WriteFile(archive, []byte("chart"), 0644))
+
+	checksum, err := computeChecksum(archive)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, checksumFile), []byte(checksum), 0644))
+
+	tests := []struct {
+		name    string
+		digest  string
+		success bool
+	}{
+		{
+			name:    "without digest",
+			digest:  "",
+			success: true,
+		},
+		{
+			name:    "with matching digest",
+			digest:  checksum,
+			success: true,
+		},
+		{
+			name:    "with matching prefixed digest",
+			digest:  "sha256:" + checksum,
+			success: true,
+		},
+		{
+			name:    "with mismatching digest",
+			digest:  "0000",
+			success: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := getCachedChart(chartDir, test.digest)
+			if test.success {
+				assert.NoError(t, err)
+				assert.Equal(t, archive, path)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	path, err := cache.Fetch(context.Background(), "orchest", config)
+	assert.NoError(t, err)
+	assert.Equal(t, archive, path)
+}
+
+func TestChartCacheCorruptedChart(t *testing.T) {
+
+	chartDir := t.TempDir()
+	archive := filepath.Join(chartDir, "minio-4.0.2.tgz")
+	require.NoError(t, os.WriteFile(archive, []byte("chart"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, checksumFile), []byte("0000"), 0644))
+
+	_, err := getCachedChart(chartDir, "")
+	assert.Error(t, err)
+}
+
+func TestChartCacheDir(t *testing.T) {
+
+	cache := NewChartCache(nil, "/cache")
+
+	tests := []struct {
+		name   string
+		config *orchestv1alpha1.ApplicationConfigHelm
+		dir    string
+	}{
+		{
+			name: "helm repository",
+			config: &orchestv1alpha1.ApplicationConfigHelm{
+				RepoURL: "https://charts.example.com/stable",
+				Chart:   "minio",
+				Version: "4.0.2",
+			},
+			dir: "/cache/public/charts.example.com_stable/minio/4.0.2",
+		},
+		{
+			name: "oci registry without version",
+			config: &orchestv1alpha1.ApplicationConfigHelm{
+				RepoURL: "oci://registry.example.com:5000/charts",
+				Chart:   "monitoring",
+			},
+			dir: "/cache/public/registry.example.com_5000_charts/monitoring/latest",
+		},
+		{
+			name: "private helm repository",
+			config: &orchestv1alpha1.ApplicationConfigHelm{
+				RepoURL:           "https://charts.example.com/private",
+				Chart:             "minio",
+				Version:           "4.0.2",
+				CredentialsSecret: "chart-credentials",
+			},
+			dir: "/cache/private/orchest/chart-credentials/charts.example.com_private/minio/4.0.2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.dir, cache.chartDir("orchest", test.config))
+		})
+	}
+}
+
+func TestCheckUnpinnedChartAge(t *testing.T) {
+
+	chartDir := t.TempDir()
+	checksumPath := filepath.Join(chartDir, checksumFile)
+	require.NoError(t, os.WriteFile(checksumPath, []byte("0000"), 0644))
+
+	now := time.Now()
+	assert.NoError(t, checkUnpinnedChartAge(chartDir, now))
+
+	cachedAt := now.Add(-2 * unpinnedChartTTL)
+	require.NoError(t, os.Chtimes(checksumPath, cachedAt, cachedAt))
+	assert.Error(t, checkUnpinnedChartAge(chartDir, now))
+
+	assert.Error(t, checkUnpinnedChartAge(t.TempDir(), now))
+}
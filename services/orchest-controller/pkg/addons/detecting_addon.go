@@ -0,0 +1,99 @@
+package addons
+
+import (
+	"context"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/helm"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+var (
+	certManagerGroupVersion = "cert-manager.io/v1"
+)
+
+// DetectFn returns true if a compatible installation of the addon, which is not managed by
+// the controller, already exists in the cluster
+type DetectFn func(ctx context.Context, client kubernetes.Interface) (bool, error)
+
+// DetectingAddon wraps an addon and skips its installation if a compatible installation
+// already exists in the cluster
+type DetectingAddon struct {
+	Addon
+	name   string
+	client kubernetes.Interface
+	detect DetectFn
+}
+
+func NewDetectingAddon(client kubernetes.Interface, name string, detect DetectFn, addon Addon) Addon {
+	return &DetectingAddon{
+		Addon:  addon,
+		name:   name,
+		client: client,
+		detect: detect,
+	}
+}
+
+// Enable the addon, unless a compatible installation exists which is not managed by the controller
+func (d *DetectingAddon) Enable(ctx context.Context, preInstallHooks []PreInstallHookFn,
+	namespace string, app *orchestv1alpha1.ApplicationSpec) error {
+
+	// If the release is already managed by the controller, the addon is updated as usual,
+	// otherwise the detection would find the installation of the controller itself.
+	releaseName := getAppReleaseName(namespace, d.name, app)
+	if _, err := helm.GetReleaseConfig(ctx, releaseName, namespace); err != nil {
+		found, err := d.detect(ctx, d.client)
+		if err != nil {
+			return errors.Wrapf(err, "failed to detect existing installation of %s", d.name)
+		}
+
+		if found {
+			klog.Infof("A compatible %s is already installed, skipping installation", d.name)
+			return nil
+		}
+	}
+
+	return d.Addon.Enable(ctx, preInstallHooks, namespace, app)
+}
+
+// detectIngressNginx returns true if an IngressClass of ingress-nginx exists
+func detectIngressNginx(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	ingressClasses, err := client.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	for _, ingressClass := range ingressClasses.Items {
+		if ingressClass.Spec.Controller == controller.IngressClassController {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// detectCertManager returns true if the cert-manager API is served by the cluster
+func detectCertManager(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	_, err := client.Discovery().ServerResourcesForGroupVersion(certManagerGroupVersion)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Status returns the state of the wrapped addon, if it reports one
+func (d *DetectingAddon) Status(ctx context.Context, namespace string,
+	app *orchestv1alpha1.ApplicationSpec) (*orchestv1alpha1.ApplicationStatus, error) {
+	if reporter, ok := d.Addon.(StatusReporter); ok {
+		return reporter.Status(ctx, namespace, app)
+	}
+	return nil, nil
+}
@@ -8,8 +8,17 @@ import (
 
 	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
 
 	"github.com/orchest/orchest/services/orchest-controller/pkg/helm"
+	"github.com/pkg/errors"
+)
+
+var (
+	// The number of revisions helm keeps per release
+	helmHistoryMax = 10
+
+	helmTimeout = time.Second * 180
 )
 
 type HelmDeployer struct {
@@ -17,6 +26,9 @@ type HelmDeployer struct {
 	client     kubernetes.Interface
 	deployDir  string
 	valuesPath string
+	// The default remote chart of the addon, if nil the chart in deployDir is used
+	chart      *orchestv1alpha1.ApplicationConfigHelm
+	chartCache *ChartCache
 }
 
 func NewHelmDeployer(client kubernetes.Interface,
@@ -30,8 +42,58 @@ func NewHelmDeployer(client kubernetes.Interface,
 	}
 }
 
-func (d *HelmDeployer) getReleaseName(namespace string) string {
-	return fmt.Sprintf("%s-%s", namespace, d.name)
+// NewRemoteHelmDeployer returns a HelmDeployer which fetches its chart from a helm repository
+// or an OCI registry, the chart defined in the application config takes precedence over the
+// default chart
+func NewRemoteHelmDeployer(client kubernetes.Interface,
+	name string, chart *orchestv1alpha1.ApplicationConfigHelm,
+	valuesPath string, chartCache *ChartCache) Addon {
+	return &HelmDeployer{
+		name:       name,
+		client:     client,
+		valuesPath: valuesPath,
+		chart:      chart,
+		chartCache: chartCache,
+	}
+}
+
+// getReleaseName returns the release name of the application config, or the default
+// release name of the addon in the namespace
+func (d *HelmDeployer) getReleaseName(namespace string, app *orchestv1alpha1.ApplicationSpec) string {
+	return getAppReleaseName(namespace, d.name, app)
+}
+
+func getAppReleaseName(namespace, name string, app *orchestv1alpha1.ApplicationSpec) string {
+	if app != nil && app.Config.Helm != nil && app.Config.Helm.ReleaseName != "" {
+		return app.Config.Helm.ReleaseName
+	}
+	return GetReleaseName(namespace, name)
+}
+
+// GetReleaseName returns the name of the helm release of an application
+func GetReleaseName(namespace, name string) string {
+	return fmt.Sprintf("%s-%s", namespace, name)
+}
+
+// getChartPath returns the path of the chart to deploy, remote charts are fetched
+// into the chart cache first
+func (d *HelmDeployer) getChartPath(ctx context.Context, namespace string,
+	app *orchestv1alpha1.ApplicationSpec) (string, error) {
+
+	chart := d.chart
+	if app != nil && isRemoteChart(app.Config.Helm) {
+		chart = app.Config.Helm
+	}
+
+	if !isRemoteChart(chart) {
+		return d.deployDir, nil
+	}
+
+	if d.chartCache == nil {
+		return "", errors.Errorf("addon %s does not support remote charts", d.name)
+	}
+
+	return d.chartCache.Fetch(ctx, namespace, chart)
 }
 
 // Installs deployer if the config is changed
@@ -39,26 +101,42 @@ func (d *HelmDeployer) Enable(ctx context.Context, preInstallHooks []PreInstallH
 	namespace string,
 	app *orchestv1alpha1.ApplicationSpec) error {
 
-	releaseName := d.getReleaseName(namespace)
+	releaseName := d.getReleaseName(namespace, app)
+
+	chartPath, err := d.getChartPath(ctx, namespace, app)
+	if err != nil {
+		return err
+	}
+
+	// A release stuck in failed or pending state can not be upgraded, so it is rolled
+	// back to its last deployed revision first.
+	revision, err := helm.RecoverReleaseIfNeeded(ctx, d.client, releaseName, namespace, helmTimeout)
+	if err != nil {
+		return err
+	}
+	if revision != 0 {
+		klog.Infof("Release %s is rolled back to revision %d", releaseName, revision)
+	}
 
 	// Generate the deploy args
 	deployArgsBuilder := helm.NewHelmArgBuilder()
 	deployArgs := deployArgsBuilder.WithName(releaseName).
 		WithNamespace(namespace).
 		WithCreateNamespace().
-		WithAtomic().WithTimeout(time.Second * 180)
+		WithAtomic().WithTimeout(helmTimeout).
+		WithHistoryMax(helmHistoryMax)
 
 	if d.valuesPath != "" {
 		deployArgs.WithValuesFile(d.valuesPath)
 	}
 
-	if app.Config.Helm != nil && app.Config.Helm.Parameters != nil {
+	if app != nil && app.Config.Helm != nil && app.Config.Helm.Parameters != nil {
 		for _, parameter := range app.Config.Helm.Parameters {
 			deployArgs.WithSetValue(parameter.Name, parameter.Value)
 		}
 	}
 
-	deployArgs.WithRepository(d.deployDir)
+	deployArgs.WithRepository(chartPath)
 
 	// First, we need to check if there is already a release, and if yes get the manifests stored
 	// in helm-related secret, and if the manifest can not be found, we will deploy the release
@@ -79,12 +157,6 @@ func (d *HelmDeployer) Enable(ctx context.Context, preInstallHooks []PreInstallH
 			// There is no need for update, return without err
 			return nil
 		}
-
-		err = helm.RemoveHelmHistoryIfNeeded(ctx, d.client, releaseName, namespace)
-		if err != nil {
-			return err
-		}
-
 	}
 
 	for _, preInstall := range preInstallHooks {
@@ -94,12 +166,37 @@ func (d *HelmDeployer) Enable(ctx context.Context, preInstallHooks []PreInstallH
 		}
 	}
 
+	// With --atomic, helm rolls the release back if the upgrade fails, the rollback is
+	// visible in the status of the release
 	_, err = helm.RunCommand(ctx, deployArgs.WithUpgradeInstall().Build())
 	return err
 
 }
 
+// Status returns the state of the latest revision of the release
+func (d *HelmDeployer) Status(ctx context.Context, namespace string,
+	app *orchestv1alpha1.ApplicationSpec) (*orchestv1alpha1.ApplicationStatus, error) {
+	history, err := helm.GetReleaseHistory(ctx, d.getReleaseName(namespace, app), namespace, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(history) == 0 {
+		return nil, nil
+	}
+
+	latest := history[len(history)-1]
+	return &orchestv1alpha1.ApplicationStatus{
+		Name:         d.name,
+		Revision:     latest.Revision,
+		Status:       latest.Status,
+		RolledBackTo: latest.RolledBackTo(),
+		Description:  latest.Description,
+	}, nil
+}
+
 // Uninstall the addon
-func (d *HelmDeployer) Uninstall(ctx context.Context, namespace string) error {
-	return helm.RemoveRelease(ctx, d.getReleaseName(namespace), namespace)
+func (d *HelmDeployer) Uninstall(ctx context.Context, namespace string,
+	app *orchestv1alpha1.ApplicationSpec) error {
+	return helm.RemoveRelease(ctx, d.getReleaseName(namespace, app), namespace)
 }
@@ -3,131 +3,449 @@ package addons
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
 
 	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
 	"github.com/pkg/errors"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+var (
+	// The field manager used for server-side apply
+	fieldOwner = client.FieldOwner("orchest-controller")
+
+	// The keys of the inventory ConfigMap
+	inventoryObjectsKey = "objects"
+	inventoryHashKey    = "hash"
+
+	kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+)
+
+// objectRef identifies an object applied by the PathDeployer
+type objectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// PathDeployer deploys an application from plain kubernetes manifests, the manifests are
+// read from a directory or a ConfigMap and optionally built with kustomize. Objects are
+// applied with server-side apply and the applied objects are recorded in an inventory
+// ConfigMap, so objects removed from the manifests can be pruned.
 type PathDeployer struct {
-	name    string
-	objects []client.Object
-	gClient client.Client
+	name     string
+	root     string
+	assetDir string
+	client   kubernetes.Interface
+	gClient  client.Client
 }
 
-func NewPathDeployer(name, root string, gClient client.Client, scheme *runtime.Scheme) Addon {
+// NewPathDeployer returns a PathDeployer which deploys the manifests in root, unless the
+// application config points to other manifests
+func NewPathDeployer(client kubernetes.Interface, gClient client.Client,
+	name, assetDir, root string) Addon {
+	return &PathDeployer{
+		name:     name,
+		root:     root,
+		assetDir: assetDir,
+		client:   client,
+		gClient:  gClient,
+	}
+}
+
+func (d *PathDeployer) getInventoryName() string {
+	return fmt.Sprintf("%s-manifests", d.name)
+}
 
-	files := make([]*os.File, 0, 0)
+// Applies the manifests if they are changed and prunes the objects which are removed
+func (d *PathDeployer) Enable(ctx context.Context, preInstallHooks []PreInstallHookFn,
+	namespace string, app *orchestv1alpha1.ApplicationSpec) error {
 
-	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+	var config *orchestv1alpha1.ApplicationConfigManifests
+	if app != nil {
+		config = app.Config.Manifests
+	}
+
+	manifests, err := d.render(ctx, namespace, config)
+	if err != nil {
+		return err
+	}
+
+	objects, err := decodeObjects(manifests)
+	if err != nil {
+		return err
+	}
+
+	if len(objects) == 0 {
+		return errors.Errorf("no kubernetes resources found in the manifests of %s", d.name)
+	}
+
+	inventory, err := d.client.CoreV1().ConfigMaps(namespace).Get(ctx, d.getInventoryName(), metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get the inventory of %s", d.name)
+	}
+	if kerrors.IsNotFound(err) {
+		inventory = nil
+	}
+
+	hash := utils.ComputeHash(manifests)
+	if inventory != nil && inventory.Data[inventoryHashKey] == hash {
+		// The manifests are not changed, there is no need to apply them
+		return nil
+	}
+
+	for _, preInstall := range preInstallHooks {
+		err = preInstall(app)
 		if err != nil {
 			return err
 		}
+	}
 
-		if fi.IsDir() {
-			if path != root {
-				return filepath.SkipDir
-			}
-			return nil
+	newRefs := make([]objectRef, 0, len(objects))
+	for _, object := range objects {
+		if err = d.setDefaultNamespace(object, namespace); err != nil {
+			return err
 		}
 
-		file, err := os.Open(path)
+		err = d.gClient.Patch(ctx, object, client.Apply, fieldOwner, client.ForceOwnership)
 		if err != nil {
-			return errors.Wrapf(err, "failed to open file %s", path)
+			return errors.Wrapf(err, "failed to apply %s %s", object.GetKind(), object.GetName())
 		}
 
-		files = append(files, file)
+		newRefs = append(newRefs, objectRef{
+			APIVersion: object.GetAPIVersion(),
+			Kind:       object.GetKind(),
+			Namespace:  object.GetNamespace(),
+			Name:       object.GetName(),
+		})
+	}
+
+	if inventory != nil && (config == nil || config.Prune == nil || *config.Prune) {
+		oldRefs, err := getInventoryRefs(inventory)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range pruneCandidates(oldRefs, newRefs) {
+			klog.Infof("Pruning %s %s of application %s", ref.Kind, ref.Name, d.name)
+			if err = d.deleteObject(ctx, ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	return d.saveInventory(ctx, namespace, inventory, newRefs, hash)
+}
+
+// Uninstall the addon
+func (d *PathDeployer) Uninstall(ctx context.Context, namespace string,
+	_ *orchestv1alpha1.ApplicationSpec) error {
+
+	inventory, err := d.client.CoreV1().ConfigMaps(namespace).Get(ctx, d.getInventoryName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
 		return nil
-	})
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get the inventory of %s", d.name)
+	}
 
+	refs, err := getInventoryRefs(inventory)
 	if err != nil {
-		klog.Fatalf("failed to create PathDeployer, err: %v", err)
-	}
-
-	objects := make([]client.Object, 0)
-	codecFactory := serializer.NewCodecFactory(scheme)
-	decoder := codecFactory.UniversalDecoder(scheme.PrioritizedVersionsAllGroups()...)
-
-	for _, file := range files {
-		utf16bom := unicode.BOMOverride(unicode.UTF8.NewDecoder())
-		reader := transform.NewReader(file, utf16bom)
-
-		d := yaml.NewYAMLOrJSONDecoder(reader, 4096)
-		for {
-			ext := runtime.RawExtension{}
-			if err := d.Decode(&ext); err != nil {
-				if err == io.EOF {
-					break
-				}
-				klog.V(3).Infof("error parsing %v", err)
-				continue
-			}
+		return err
+	}
 
-			ext.Raw = bytes.TrimSpace(ext.Raw)
-			if len(ext.Raw) == 0 || bytes.Equal(ext.Raw, []byte("null")) {
-				continue
-			}
+	for _, ref := range refs {
+		if err = d.deleteObject(ctx, ref); err != nil {
+			return err
+		}
+	}
 
-			obj, _, err := decoder.Decode(ext.Raw, nil, nil)
-			if err != nil {
-				klog.V(3).Infof("unable to decode %v", err)
-				continue
-			}
+	err = d.client.CoreV1().ConfigMaps(namespace).Delete(ctx, d.getInventoryName(), metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
 
-			object := obj.(client.Object)
-			if object == nil {
-				klog.V(3).Infof("failed to cast to client.Object", obj)
-				continue
-			}
+	return nil
+}
 
-			objects = append(objects, object)
+// render returns the manifests of the application, built with kustomize if requested
+func (d *PathDeployer) render(ctx context.Context, namespace string,
+	config *orchestv1alpha1.ApplicationConfigManifests) ([]byte, error) {
 
+	root := d.root
+	if config != nil && config.Path != "" {
+		root = config.Path
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(d.assetDir, root)
 		}
+	}
 
+	if config != nil && config.ConfigMap != "" {
+		tmpDir, err := d.writeConfigMap(ctx, namespace, config.ConfigMap)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmpDir)
+		root = tmpDir
 	}
 
-	if len(objects) == 0 {
-		klog.Fatalf("failed to retrive kubernetes resources from path %s", root)
+	if root == "" {
+		return nil, errors.Errorf("application %s defines no path or ConfigMap for its manifests", d.name)
 	}
 
-	return &PathDeployer{
-		name:    name,
-		objects: objects,
-		gClient: gClient,
-		//path: path,
+	if config != nil && config.Kustomize != nil {
+		return runKustomize(ctx, filepath.Join(root, config.Kustomize.Overlay))
 	}
+
+	return readManifests(root)
 }
 
-// Installs deployer if the config is changed
-func (d *PathDeployer) Enable(ctx context.Context, preInstall []PreInstallHookFn,
-	namespace string, _ *orchestv1alpha1.ApplicationSpec) error {
+// writeConfigMap writes every key of the ConfigMap into a file of a temporary directory
+func (d *PathDeployer) writeConfigMap(ctx context.Context, namespace, name string) (string, error) {
 
-	for _, obj := range d.objects {
-		err := d.gClient.Create(ctx, obj, &client.CreateOptions{})
-		if err != nil && !kerrors.IsAlreadyExists(err) {
-			return err
+	configMap, err := d.client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the manifests ConfigMap %s", name)
+	}
+
+	tmpDir, err := os.MkdirTemp("", d.name+"-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temporary directory")
+	}
+
+	for key, value := range configMap.Data {
+		err = os.WriteFile(filepath.Join(tmpDir, filepath.Base(key)), []byte(value), 0644)
+		if err != nil {
+			os.RemoveAll(tmpDir)
+			return "", errors.Wrapf(err, "failed to write manifest %s", key)
 		}
 	}
+
+	return tmpDir, nil
+}
+
+// setDefaultNamespace sets the namespace of namespaced objects which do not define one
+func (d *PathDeployer) setDefaultNamespace(object *unstructured.Unstructured, namespace string) error {
+	if object.GetNamespace() != "" {
+		return nil
+	}
+
+	gvk := object.GroupVersionKind()
+	mapping, err := d.gClient.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the rest mapping of %s", gvk.String())
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		object.SetNamespace(namespace)
+	}
+
 	return nil
 }
 
-// Uninstall the addon
-func (d *PathDeployer) Uninstall(ctx context.Context, namespace string) error {
-	for _, obj := range d.objects {
-		err := d.gClient.Delete(ctx, obj, &client.DeleteOptions{})
-		if err != nil {
-			return err
+func (d *PathDeployer) deleteObject(ctx context.Context, ref objectRef) error {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	object.SetNamespace(ref.Namespace)
+	object.SetName(ref.Name)
+
+	err := d.gClient.Delete(ctx, object)
+	if err != nil && !kerrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return errors.Wrapf(err, "failed to delete %s %s", ref.Kind, ref.Name)
+	}
+
+	return nil
+}
+
+// saveInventory records the applied objects and the hash of the manifests
+func (d *PathDeployer) saveInventory(ctx context.Context, namespace string,
+	inventory *corev1.ConfigMap, refs []objectRef, hash string) error {
+
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+
+	if inventory == nil {
+		inventory = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      d.getInventoryName(),
+				Namespace: namespace,
+			},
 		}
+		inventory.Data = map[string]string{
+			inventoryObjectsKey: string(data),
+			inventoryHashKey:    hash,
+		}
+		_, err = d.client.CoreV1().ConfigMaps(namespace).Create(ctx, inventory, metav1.CreateOptions{})
+	} else {
+		inventory = inventory.DeepCopy()
+		inventory.Data = map[string]string{
+			inventoryObjectsKey: string(data),
+			inventoryHashKey:    hash,
+		}
+		_, err = d.client.CoreV1().ConfigMaps(namespace).Update(ctx, inventory, metav1.UpdateOptions{})
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to save the inventory of %s", d.name)
 	}
+
 	return nil
 }
+
+func getInventoryRefs(inventory *corev1.ConfigMap) ([]objectRef, error) {
+	refs := []objectRef{}
+	data, ok := inventory.Data[inventoryObjectsKey]
+	if !ok {
+		return refs, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), &refs); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the inventory %s", inventory.Name)
+	}
+
+	return refs, nil
+}
+
+// pruneCandidates returns the objects of the old inventory which are not in the new one
+func pruneCandidates(oldRefs, newRefs []objectRef) []objectRef {
+	keep := make(map[objectRef]struct{}, len(newRefs))
+	for _, ref := range newRefs {
+		keep[ref] = struct{}{}
+	}
+
+	prune := []objectRef{}
+	for _, ref := range oldRefs {
+		if _, ok := keep[ref]; !ok {
+			prune = append(prune, ref)
+		}
+	}
+
+	return prune
+}
+
+// readManifests concatenates the yaml and json files in the root directory
+func readManifests(root string) ([]byte, error) {
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the manifests directory %s", root)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	manifests := &bytes.Buffer{}
+	for _, name := range names {
+		if isKustomization(name) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read manifest %s", name)
+		}
+
+		manifests.WriteString("\n---\n")
+		manifests.Write(content)
+	}
+
+	return manifests.Bytes(), nil
+}
+
+func isKustomization(name string) bool {
+	for _, kustomization := range kustomizationFiles {
+		if name == kustomization {
+			return true
+		}
+	}
+	return false
+}
+
+// runKustomize builds the kustomization in dir
+func runKustomize(ctx context.Context, dir string) ([]byte, error) {
+
+	cmd := exec.CommandContext(ctx, "kustomize", "build", dir)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build kustomization %s: %s", dir, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// decodeObjects decodes a multi-document yaml or json stream into objects
+func decodeObjects(manifests []byte) ([]*unstructured.Unstructured, error) {
+
+	utf16bom := unicode.BOMOverride(unicode.UTF8.NewDecoder())
+	reader := transform.NewReader(bytes.NewReader(manifests), utf16bom)
+
+	objects := make([]*unstructured.Unstructured, 0)
+	d := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		content := map[string]interface{}{}
+		if err := d.Decode(&content); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "failed to parse the manifests")
+		}
+
+		if len(content) == 0 {
+			continue
+		}
+
+		object := &unstructured.Unstructured{Object: content}
+
+		// Lists are flattened, kustomize and kubectl output may contain them
+		if object.IsList() {
+			list, err := object.ToList()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse the manifests")
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+
+		if object.GetKind() == "" || object.GetName() == "" {
+			return nil, errors.Errorf("manifest is missing kind or name: %v", content)
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
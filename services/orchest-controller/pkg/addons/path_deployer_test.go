@@ -0,0 +1,91 @@
+package addons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeObjects(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		manifests string
+		objects   []string
+		success   bool
+	}{
+		{
+			name: "multiple documents",
+			manifests: `
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: first
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+`,
+			objects: []string{"first", "second"},
+			success: true,
+		},
+		{
+			name: "list",
+			manifests: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: first
+`,
+			objects: []string{"first"},
+			success: true,
+		},
+		{
+			name: "missing name",
+			manifests: `
+apiVersion: v1
+kind: ServiceAccount
+`,
+			success: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objects, err := decodeObjects([]byte(test.manifests))
+			if !test.success {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			names := []string{}
+			for _, object := range objects {
+				names = append(names, object.GetName())
+			}
+			assert.Equal(t, test.objects, names)
+		})
+	}
+}
+
+func TestPruneCandidates(t *testing.T) {
+
+	oldRefs := []objectRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "orchest", Name: "kept"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "orchest", Name: "removed"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "removed"},
+	}
+
+	newRefs := []objectRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "orchest", Name: "kept"},
+		{APIVersion: "v1", Kind: "Secret", Namespace: "orchest", Name: "added"},
+	}
+
+	assert.Equal(t, oldRefs[1:], pruneCandidates(oldRefs, newRefs))
+}
@@ -97,6 +97,17 @@ type OrchestComponentTemplate struct {
 	// Selector which must match a node's labels for the pod to be scheduled on that node.
 	// More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// If specified, the pods of the component tolerate these taints
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// PodSecurityContext of the pods of the component, if omitted a hardened default suited to
+	// the image of the component is used
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// SecurityContext of the containers of the component, if omitted a hardened default suited
+	// to the image of the component is used
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
 }
 
 type OrchestComponentStatus struct {
@@ -114,11 +125,55 @@ type OrchestComponentStatus struct {
 }
 
 type OrchestComponentSpec struct {
+	// ReconcilerName is the type of the component, e.g. orchest-api, which selects the
+	// reconciler of the component
 	ReconcilerName string `json:"reconcilerName,omitempty"`
 
 	OrchestHost *string `json:"orchestHost,omitempty"`
 
+	// OrchestHostAliases are the additional hostnames the component is exposed at
+	OrchestHostAliases []string `json:"orchestHostAliases,omitempty"`
+
+	// BasePath is the path prefix the component is exposed under
+	BasePath string `json:"basePath,omitempty"`
+
 	Template OrchestComponentTemplate `json:"template,omitempty"`
+
+	// ImageRegistry is the external image registry the component uses, if nil the
+	// in-cluster docker-registry is used
+	ImageRegistry *ImageRegistrySpec `json:"imageRegistry,omitempty"`
+
+	// TrustedCA selects the additional CA certificates the component trusts
+	TrustedCA *corev1.ConfigMapKeySelector `json:"trustedCA,omitempty"`
+
+	// TrustedCAHash is the hash of the trusted CA certificates, a change rolls out the component
+	TrustedCAHash string `json:"trustedCAHash,omitempty"`
+
+	// Authentication configures how auth-server authenticates the users
+	Authentication *AuthenticationSpec `json:"authentication,omitempty"`
+
+	// AuthenticationHash is the hash of the OIDC client credentials, a change rolls out the
+	// component
+	AuthenticationHash string `json:"authenticationHash,omitempty"`
+
+	// SecretKey selects the key of the generated application Secret holding the secret key
+	// the component signs its sessions and tokens with
+	SecretKey *corev1.SecretKeySelector `json:"secretKey,omitempty"`
+
+	// SecretKeyHash is the hash of the secret key, a change rolls out the component
+	SecretKeyHash string `json:"secretKeyHash,omitempty"`
+
+	// ImagePuller configures the image puller of the node-agent
+	ImagePuller *ImagePullerSpec `json:"imagePuller,omitempty"`
+
+	// TLS configures the TLS of the ingress of the component, SecretName is always set
+	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// Ingress configures the ingress controller exposing the component
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+
+	// Exposure configures how the component is exposed
+	Exposure *ExposureSpec `json:"exposure,omitempty"`
 }
 
 // +genclient
@@ -152,8 +207,66 @@ type OrchestSpec struct {
 
 	OrchestHost *string `json:"orchestHost,omitempty"`
 
+	// OrchestHostAliases are the additional hostnames Orchest is exposed at, e.g. the former
+	// hostname while the users move to the OrchestHost. The ingresses match all of them and
+	// the issued certificates include them, the OrchestHost has to be specified. The ingresses
+	// of the user sessions only match the OrchestHost.
+	OrchestHostAliases []string `json:"orchestHostAliases,omitempty"`
+
+	// BasePath is the path prefix Orchest is served under, e.g. /orchest to serve Orchest at
+	// https://platform.example.com/orchest/ next to other tools sharing the OrchestHost. The
+	// Jupyter servers and the services of the user sessions are served under it too.
+	BasePath string `json:"basePath,omitempty"`
+
 	Registry string `json:"registry,omitempty"`
 
+	// ImageRegistry is an external registry environment and Jupyter images are pushed to
+	// and pulled from. If specified, the in-cluster docker-registry is not deployed
+	ImageRegistry *ImageRegistrySpec `json:"imageRegistry,omitempty"`
+
+	// RegistryGarbageCollection configures the garbage collection of the in-cluster docker-registry
+	RegistryGarbageCollection *RegistryGarbageCollectionSpec `json:"registryGarbageCollection,omitempty"`
+
+	// TrustedCA selects the key of a ConfigMap in the OrchestCluster namespace holding additional
+	// PEM encoded CA certificates, e.g. of a TLS-intercepting proxy, which are trusted by all
+	// components in addition to the CA certificates of their images
+	TrustedCA *corev1.ConfigMapKeySelector `json:"trustedCA,omitempty"`
+
+	// GPU configures the detection of the GPU nodes of the cluster
+	GPU *GPUSpec `json:"gpu,omitempty"`
+
+	// HTTPProxy is the proxy of the outgoing HTTP requests of all components
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the proxy of the outgoing HTTPS requests of all components
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma separated list of hosts, domains and CIDRs which are not proxied,
+	// the in-cluster services are never proxied
+	NoProxy string `json:"noProxy,omitempty"`
+
+	// NetworkPolicy configures the NetworkPolicies which restrict the traffic between the user
+	// pods and the Orchest services
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// TLS configures the TLS of the Orchest ingresses, if nil the ingresses serve plain HTTP
+	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// Ingress configures the ingress controller exposing the Orchest services, if nil the
+	// IngressClass of ingress-nginx is detected
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+
+	// Exposure configures how Orchest is exposed, if nil Orchest is exposed by Ingresses
+	Exposure *ExposureSpec `json:"exposure,omitempty"`
+
+	// PodSecurity configures the Pod Security Admission levels the namespace of the
+	// OrchestCluster is labeled with
+	PodSecurity *PodSecuritySpec `json:"podSecurity,omitempty"`
+
+	// Authentication configures how the users are authenticated, if nil they are users of
+	// auth-server with passwords
+	Authentication *AuthenticationSpec `json:"authentication,omitempty"`
+
 	Version string `json:"version,omitempty"`
 
 	Env []corev1.EnvVar `json:"env,omitempty"`
@@ -171,17 +284,266 @@ type OrchestSpec struct {
 	CeleryWorker OrchestComponentTemplate `json:"celeryWorker,omitempty"`
 
 	// If specified, node-agent for this cluster will be deployed with this configuration
-	NodeAgent OrchestComponentTemplate `json:"nodeAgent,omitempty"`
+	NodeAgent NodeAgentSpec `json:"nodeAgent,omitempty"`
 
 	// If specified, auth-server for this cluster will be deployed with this configuration
 	AuthServer OrchestComponentTemplate `json:"authServer,omitempty"`
 }
 
+// NodeAgentSpec describes the node-agent, which runs on every node to pre-pull images
+type NodeAgentSpec struct {
+	OrchestComponentTemplate `json:",inline"`
+
+	// ImagePuller configures the image puller of the node-agent
+	ImagePuller *ImagePullerSpec `json:"imagePuller,omitempty"`
+}
+
+// ImagePullerSpec describes how the node-agent pre-pulls the images on the nodes
+type ImagePullerSpec struct {
+	// Interval is the number of seconds between fetching the images to pull, defaults to 60
+	Interval *int32 `json:"interval,omitempty"`
+	// Policy is either IfNotPresent, which pulls only images missing on the node, or
+	// Always, defaults to IfNotPresent
+	Policy corev1.PullPolicy `json:"policy,omitempty"`
+	// Retries is the number of attempts to pull an image, defaults to 3
+	Retries *int32 `json:"retries,omitempty"`
+	// Images are pre-pulled on every node in addition to the images of Orchest
+	Images []string `json:"images,omitempty"`
+	// LogLevel is the log level of the image puller, defaults to INFO
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+// GPUSpec describes how the GPU nodes of the cluster are detected, a node is a GPU node if it
+// advertises the GPU resource or is labeled with orchest.io/gpu=true
+type GPUSpec struct {
+	// ResourceName is the extended resource advertised by the GPU nodes, defaults to nvidia.com/gpu
+	ResourceName string `json:"resourceName,omitempty"`
+}
+
+// NetworkPolicySpec describes the NetworkPolicies of the OrchestCluster, each policy is
+// enabled separately
+type NetworkPolicySpec struct {
+	// Databases allows only the Orchest services using them to reach orchest-database and
+	// rabbitmq-server
+	Databases bool `json:"databases,omitempty"`
+	// Ingress allows only the ingress controller and the Orchest services to reach
+	// orchest-webserver and auth-server, and additionally the user pods to reach orchest-api,
+	// which is queried by the SDK
+	Ingress bool `json:"ingress,omitempty"`
+	// IngressControllerNamespace is the namespace of the ingress controller, if omitted the
+	// ingress-nginx or Traefik pods of any namespace are allowed, or any pod if the services
+	// are exposed by a Gateway
+	IngressControllerNamespace string `json:"ingressControllerNamespace,omitempty"`
+	// UserEgress restricts the egress of the user pods, i.e. the sessions, kernels, services
+	// and pipeline steps, to the cluster DNS, the Kubernetes API, orchest-api, the other user
+	// pods and AllowedEgressCIDRs
+	UserEgress bool `json:"userEgress,omitempty"`
+	// AllowedEgressCIDRs are reachable by the user pods if UserEgress is enabled
+	AllowedEgressCIDRs []string `json:"allowedEgressCIDRs,omitempty"`
+}
+
+// PodSecuritySpec describes the Pod Security Admission levels of the namespace of the
+// OrchestCluster, each one of privileged, baseline or restricted. The levels which are not
+// specified are defaulted, unless the namespace is already labeled with them
+type PodSecuritySpec struct {
+	// Enforce is the level above which pods are rejected, only privileged is accepted and it
+	// is the default. node-agent mounts the container runtime socket of the nodes, and the image
+	// builds and sessions of the users run privileged containers or mount the socket too, which
+	// only the privileged level allows
+	Enforce string `json:"enforce,omitempty"`
+	// Audit is the level above which pods are recorded in the audit log, defaults to restricted
+	Audit string `json:"audit,omitempty"`
+	// Warn is the level above which the creation of pods returns a warning, defaults to baseline
+	Warn string `json:"warn,omitempty"`
+}
+
+// TLSSpec describes the certificate of the Orchest ingresses, which is either an existing
+// Secret, issued by cert-manager if IssuerRef is specified, or self-signed if SelfSigned is true
+type TLSSpec struct {
+	// SecretName is the name of the kubernetes.io/tls Secret in the OrchestCluster namespace
+	// holding the certificate. If IssuerRef or SelfSigned is specified the Secret is managed by
+	// the controller and SecretName defaults to <cluster>-ingress-tls
+	SecretName string `json:"secretName,omitempty"`
+
+	// IssuerRef references the cert-manager Issuer or ClusterIssuer issuing the certificate of
+	// the OrchestHost, cert-manager renews the certificate
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// SelfSigned generates a self-signed certificate of the OrchestHost, which is renewed
+	// before it expires
+	SelfSigned bool `json:"selfSigned,omitempty"`
+
+	// DisableRedirect disables the redirect of HTTP requests to HTTPS, the redirect is only
+	// configured for ingress-nginx, Traefik and Gateways redirect on their entrypoints
+	DisableRedirect bool `json:"disableRedirect,omitempty"`
+}
+
+// AuthenticationSpec describes how auth-server authenticates the users
+type AuthenticationSpec struct {
+	// OIDC authenticates the users with an OpenID Connect provider
+	OIDC *OIDCSpec `json:"oidc,omitempty"`
+}
+
+// OIDCSpec describes the OpenID Connect provider authenticating the users. The users are
+// signed in with the authorization code flow and created on their first login, the admins
+// are the members of the AdminGroup. The OrchestHost has to be specified, the redirect url
+// registered at the provider is <scheme>://<OrchestHost><BasePath>/login/oidc/callback.
+type OIDCSpec struct {
+	// IssuerURL is the URL of the provider, which serves the discovery document at
+	// <issuerURL>/.well-known/openid-configuration
+	IssuerURL string `json:"issuerURL"`
+
+	// ClientSecret is the name of the Secret holding the client credentials registered at the
+	// provider in its client-id and client-secret keys
+	ClientSecret string `json:"clientSecret"`
+
+	// Scopes are requested in addition to openid, they default to profile, email and groups
+	Scopes []string `json:"scopes,omitempty"`
+
+	// UsernameClaim is the claim of the username, it defaults to preferred_username
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+
+	// GroupsClaim is the claim of the groups of the user, it defaults to groups
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+
+	// AllowedGroups are the groups whose members can sign in, if empty all users can
+	AllowedGroups []string `json:"allowedGroups,omitempty"`
+
+	// AdminGroup is the group whose members are the admins of Orchest
+	AdminGroup string `json:"adminGroup,omitempty"`
+}
+
+// ExposureMode is the way Orchest is exposed
+type ExposureMode string
+
+const (
+	// IngressExposureMode exposes Orchest with Ingresses, or HTTPRoutes
+	IngressExposureMode ExposureMode = "ingress"
+	// NodePortExposureMode exposes the built-in proxy with a NodePort Service
+	NodePortExposureMode ExposureMode = "nodePort"
+	// LoadBalancerExposureMode exposes the built-in proxy with a LoadBalancer Service
+	LoadBalancerExposureMode ExposureMode = "loadBalancer"
+	// ProxyExposureMode exposes the built-in proxy with a ClusterIP Service, e.g. to put
+	// an external reverse proxy or a port-forward in front of it
+	ProxyExposureMode ExposureMode = "proxy"
+)
+
+// ExposureSpec describes how Orchest is exposed. Apart from the ingress mode, Orchest is
+// exposed by a built-in reverse proxy which authenticates the requests with auth-server,
+// routes them to the Orchest services and the Jupyter servers of the sessions, and is exposed
+// by a Service of the mode. The services of the user sessions are only exposed by Ingresses.
+type ExposureSpec struct {
+	// Mode defaults to ingress
+	Mode ExposureMode `json:"mode,omitempty"`
+
+	// NodePort is the port of the nodes the proxy is exposed at in the nodePort mode, if
+	// omitted a port is allocated
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// ServiceAnnotations are added to the Service of the proxy, e.g. to configure the load
+	// balancer
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// Proxy is the template of the built-in proxy, its image defaults to nginx
+	Proxy OrchestComponentTemplate `json:"proxy,omitempty"`
+}
+
+// IngressProvider is the type of the ingress controller exposing the Orchest services
+type IngressProvider string
+
+const (
+	// NginxIngressProvider exposes the services with Ingresses of ingress-nginx
+	NginxIngressProvider IngressProvider = "nginx"
+	// TraefikIngressProvider exposes the services with Ingresses of Traefik, the
+	// authentication is done by ForwardAuth Middlewares
+	TraefikIngressProvider IngressProvider = "traefik"
+	// GatewayIngressProvider exposes the services with HTTPRoutes of the Gateway API
+	GatewayIngressProvider IngressProvider = "gateway"
+)
+
+// IngressSpec describes how the Orchest services are exposed
+type IngressSpec struct {
+	// Provider is the type of the ingress controller, defaults to nginx
+	Provider IngressProvider `json:"provider,omitempty"`
+
+	// ClassName is the IngressClass of the Ingresses, if omitted the IngressClass of the
+	// provider is detected
+	ClassName string `json:"className,omitempty"`
+
+	// Annotations are added to the Ingresses or HTTPRoutes, and take precedence over the
+	// annotations set by the controller
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Gateway configures the HTTPRoutes if the provider is gateway
+	Gateway *GatewaySpec `json:"gateway,omitempty"`
+
+	// InstallController installs ingress-nginx, unless a compatible ingress-nginx is already
+	// installed. It is only supported by the nginx provider.
+	InstallController bool `json:"installController,omitempty"`
+}
+
+// GatewaySpec describes the HTTPRoutes of the Orchest services
+type GatewaySpec struct {
+	// ParentRefs are the Gateways the HTTPRoutes attach to
+	ParentRefs []GatewayReference `json:"parentRefs"`
+
+	// AuthFilter is the extension filter of the Gateway implementation which authenticates
+	// the requests with auth-server, the Gateway API has no standard filter for it
+	AuthFilter *ExtensionReference `json:"authFilter"`
+}
+
+// GatewayReference references a Gateway, or a listener of it
+type GatewayReference struct {
+	Name string `json:"name"`
+	// Namespace of the Gateway, defaults to the OrchestCluster namespace
+	Namespace string `json:"namespace,omitempty"`
+	// SectionName is the name of the listener of the Gateway
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// ExtensionReference references an extension filter of an HTTPRoute
+type ExtensionReference struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+}
+
+// IssuerReference references a cert-manager issuer
+type IssuerReference struct {
+	Name string `json:"name"`
+	// Kind is either Issuer or ClusterIssuer, defaults to Issuer
+	Kind string `json:"kind,omitempty"`
+}
+
+// ImageRegistrySpec describes an external image registry
+type ImageRegistrySpec struct {
+	// URL of the registry, including an optional project or repository prefix,
+	// e.g. harbor.example.com/orchest
+	URL string `json:"url"`
+	// CredentialsSecret is the name of a secret of type kubernetes.io/dockerconfigjson in the
+	// OrchestCluster namespace, holding the credentials to push and pull images
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// CABundle selects the key of a ConfigMap in the OrchestCluster namespace holding the CA
+	// certificate of the registry, required if the registry certificate is not publicly trusted
+	CABundle *corev1.ConfigMapKeySelector `json:"caBundle,omitempty"`
+}
+
+// RegistryGarbageCollectionSpec describes the periodic garbage collection of the in-cluster
+// docker-registry, which deletes the layers of images no longer referenced
+type RegistryGarbageCollectionSpec struct {
+	// Schedule of the garbage collection in the cron format, defaults to "0 3 * * *"
+	Schedule string `json:"schedule,omitempty"`
+	// Suspend disables the garbage collection
+	Suspend bool `json:"suspend,omitempty"`
+}
+
 // Partially borrowed from argocd
 // ApplicationConfig contains all required information about the source of an application
 type ApplicationConfig struct {
 	// Helm holds helm specific options
 	Helm *ApplicationConfigHelm `json:"helm,omitempty"`
+	// Manifests holds the options of applications deployed from plain kubernetes manifests
+	Manifests *ApplicationConfigManifests `json:"manifests,omitempty"`
 }
 
 // ApplicationConfigHelm holds helm specific options
@@ -190,8 +552,45 @@ type ApplicationConfigHelm struct {
 	Values []string `json:"values,omitempty"`
 	// Parameters is a list of Helm parameters which are passed to the helm template command upon manifest generation
 	Parameters []HelmParameter `json:"parameters,omitempty"`
-	// ReleaseName is the Helm release name to use. If omitted it will use the application name
+	// ReleaseName is the Helm release name to use. If omitted it will use the namespace and the
+	// application name
 	ReleaseName string `json:"releaseName,omitempty"`
+	// RepoURL is the URL of the Helm repository or the OCI registry (prefixed with oci://) to fetch
+	// the chart from. If omitted, the chart bundled with the controller is used
+	RepoURL string `json:"repoURL,omitempty"`
+	// Chart is the name of the chart in the repository
+	Chart string `json:"chart,omitempty"`
+	// Version is the version of the chart, if omitted the latest version is used
+	Version string `json:"version,omitempty"`
+	// Digest is the expected sha256 checksum of the chart archive, if specified the fetched chart
+	// is verified against it
+	Digest string `json:"digest,omitempty"`
+	// CredentialsSecret is the name of the secret in the OrchestCluster namespace holding the
+	// username and password of the repository
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// ApplicationConfigManifests holds the options of applications deployed from plain manifests,
+// either Path or ConfigMap has to be specified
+type ApplicationConfigManifests struct {
+	// Path is the directory of the manifests, relative paths are resolved against the
+	// asset directory of the controller
+	Path string `json:"path,omitempty"`
+	// ConfigMap is the name of the ConfigMap in the OrchestCluster namespace holding the manifests,
+	// each key of the ConfigMap is a file
+	ConfigMap string `json:"configMap,omitempty"`
+	// Kustomize builds the manifests with kustomize before applying them
+	Kustomize *ApplicationConfigKustomize `json:"kustomize,omitempty"`
+	// Prune indicates whether objects removed from the manifests are deleted from the cluster,
+	// defaults to true
+	Prune *bool `json:"prune,omitempty"`
+}
+
+// ApplicationConfigKustomize holds kustomize specific options
+type ApplicationConfigKustomize struct {
+	// Overlay is the directory of the overlay to build, relative to the manifests root.
+	// If omitted the manifests root is built
+	Overlay string `json:"overlay,omitempty"`
 }
 
 // HelmParameter is a parameter that's passed to helm template during manifest generation
@@ -240,6 +639,67 @@ type Condition struct {
 	LastTransitionTime metav1.Time         `json:"lastTransitionTime,omitempty"`
 }
 
+// ApplicationStatus defines the observed state of a deployed application
+type ApplicationStatus struct {
+	// Name of the application
+	Name string `json:"name,omitempty"`
+	// Revision is the current revision of the application release
+	Revision int `json:"revision,omitempty"`
+	// Status is the status of the current revision, e.g. deployed or failed
+	Status string `json:"status,omitempty"`
+	// RolledBackTo is the revision the release was rolled back to, if the current
+	// revision is the result of a rollback
+	RolledBackTo int `json:"rolledBackTo,omitempty"`
+	// Description of the current revision
+	Description string `json:"description,omitempty"`
+}
+
+// RegistryStatus defines the observed state of the in-cluster docker-registry
+type RegistryStatus struct {
+	// CertificateExpiry is the time the registry TLS certificates expire
+	CertificateExpiry *metav1.Time `json:"certificateExpiry,omitempty"`
+	// LastCertificateRenewal is the time the registry TLS certificates were last renewed
+	LastCertificateRenewal *metav1.Time `json:"lastCertificateRenewal,omitempty"`
+	// LastGarbageCollection is the time the last garbage collection completed
+	LastGarbageCollection *metav1.Time `json:"lastGarbageCollection,omitempty"`
+	// ReclaimedBytes is the space reclaimed by the last garbage collection
+	ReclaimedBytes int64 `json:"reclaimedBytes,omitempty"`
+}
+
+// TLSStatus describes the certificate of the Orchest ingresses
+type TLSStatus struct {
+	// CertificateExpiry is the time the certificate expires
+	CertificateExpiry *metav1.Time `json:"certificateExpiry,omitempty"`
+	// LastCertificateRenewal is the time the self-signed certificate was last renewed
+	LastCertificateRenewal *metav1.Time `json:"lastCertificateRenewal,omitempty"`
+}
+
+// ContainerRuntimeStatus describes a container runtime of the nodes of the cluster
+type ContainerRuntimeStatus struct {
+	// Name of the container runtime, e.g. containerd
+	Name string `json:"name,omitempty"`
+	// SocketPath is the path of the socket of the container runtime on the nodes
+	SocketPath string `json:"socketPath,omitempty"`
+	// Nodes is the number of nodes running the container runtime
+	Nodes int `json:"nodes,omitempty"`
+}
+
+// GPUStatus describes the GPU nodes of the cluster
+type GPUStatus struct {
+	// ResourceName is the extended resource the GPU nodes are detected by
+	ResourceName string `json:"resourceName,omitempty"`
+	// Nodes are the GPU nodes of the cluster
+	Nodes []GPUNodeStatus `json:"nodes,omitempty"`
+}
+
+// GPUNodeStatus describes a GPU node
+type GPUNodeStatus struct {
+	// Name of the node
+	Name string `json:"name"`
+	// GPUs is the number of allocatable GPUs of the node, zero if the node is only labeled
+	GPUs int64 `json:"gpus"`
+}
+
 // OrchestClusterStatus defines the status of OrchestCluster
 type OrchestClusterStatus struct {
 	// The generation observed by the controller.
@@ -256,6 +716,29 @@ type OrchestClusterStatus struct {
 
 	Version string `json:"version,omitempty"`
 
+	// Applications holds the observed state of the deployed applications
+	Applications []ApplicationStatus `json:"applications,omitempty"`
+
+	// Registry holds the observed state of the in-cluster docker-registry
+	Registry *RegistryStatus `json:"registry,omitempty"`
+
+	// ContainerRuntimes are the container runtimes of the nodes, the runtime of the most
+	// nodes first, which is the one sessions and pipeline runs use
+	ContainerRuntimes []ContainerRuntimeStatus `json:"containerRuntimes,omitempty"`
+
+	// GPU holds the GPU nodes of the cluster, nil if there are none
+	GPU *GPUStatus `json:"gpu,omitempty"`
+
+	// TLS holds the observed state of the certificate of the Orchest ingresses
+	TLS *TLSStatus `json:"tls,omitempty"`
+
+	// URL is the URL Orchest is reachable at once the cluster is running
+	URL string `json:"url,omitempty"`
+
+	// URLs are all URLs Orchest is reachable at, i.e. the URL and the ones of the
+	// OrchestHostAliases
+	URLs []string `json:"urls,omitempty"`
+
 	LastHeartbeatTime metav1.Time `json:"lastHeartbeatTime,omitempty"`
 }
 
@@ -66,11 +66,16 @@ type Configuration struct {
 	// configuring Subject Alt Names on the certificates.
 	DNSName string
 
-	// IP address of the certificate
-	IP string
+	// IPs are the addresses of the registry service, one per IP family of the service.
+	// Each of them is added to the certificate Subject Alt Names.
+	IPs []string
 
 	// ContourServiceName holds the name of the docker-registry service name.
 	RegistryServiceName string
+
+	// DNSNames, if specified, are the host names the certificate is issued for instead of
+	// the names of the registry service, e.g. the host of the Orchest ingress.
+	DNSNames []string
 }
 
 // Certificates contains a set of Certificates as []byte each holding
@@ -97,14 +102,20 @@ func GenerateCerts(config *Configuration) (*Certificates, error) {
 		return nil, err
 	}
 
-	registryCert, registryKey, err := newCert(caCertPEM,
-		caKeyPEM,
-		expiry,
-		config.IP,
-		stringOrDefault(config.RegistryServiceName, DefaultRegistryServiceName),
-		stringOrDefault(config.Namespace, DefaultNamespace),
-		stringOrDefault(config.DNSName, DefaultDNSName),
-	)
+	var registryCert, registryKey []byte
+	if len(config.DNSNames) > 0 {
+		registryCert, registryKey, err = signCert(caCertPEM, caKeyPEM, expiry, config.IPs,
+			config.DNSNames[0], config.DNSNames)
+	} else {
+		registryCert, registryKey, err = newCert(caCertPEM,
+			caKeyPEM,
+			expiry,
+			config.IPs,
+			stringOrDefault(config.RegistryServiceName, DefaultRegistryServiceName),
+			stringOrDefault(config.Namespace, DefaultNamespace),
+			stringOrDefault(config.DNSName, DefaultDNSName),
+		)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +131,23 @@ func GenerateCerts(config *Configuration) (*Certificates, error) {
 // ("contour" or "envoy"), and the Kubernetes namespace the service will run in (because
 // of the Kubernetes DNS schema.)
 // The return values are cert, key, err.
-func newCert(caCertPEM, caKeyPEM []byte, expiry time.Time, IP, service, namespace, dnsname string) ([]byte, []byte, error) {
+func newCert(caCertPEM, caKeyPEM []byte, expiry time.Time, IPs []string, service, namespace, dnsname string) ([]byte, []byte, error) {
+	return signCert(caCertPEM, caKeyPEM, expiry, IPs, service, serviceNames(service, namespace, dnsname))
+}
+
+// signCert generates a new keypair for the common name and the DNS names, signed by the CA
+// keypair. The return values are cert, key, err.
+func signCert(caCertPEM, caKeyPEM []byte, expiry time.Time, IPs []string, commonName string,
+	dnsNames []string) ([]byte, []byte, error) {
+
+	ipAddresses := make([]net.IP, 0, len(IPs))
+	for _, IP := range IPs {
+		ip := net.ParseIP(IP)
+		if ip == nil {
+			return nil, nil, fmt.Errorf("invalid IP address %q", IP)
+		}
+		ipAddresses = append(ipAddresses, ip)
+	}
 
 	caKeyPair, err := tls.X509KeyPair(caCertPEM, caKeyPEM)
 	if err != nil {
@@ -144,17 +171,17 @@ func newCert(caCertPEM, caKeyPEM []byte, expiry time.Time, IP, service, namespac
 	template := &x509.Certificate{
 		SerialNumber: newSerial(now),
 		Subject: pkix.Name{
-			CommonName: service,
+			CommonName: commonName,
 		},
 		NotBefore:    now.UTC().AddDate(0, 0, -1),
 		NotAfter:     expiry.UTC(),
 		SubjectKeyId: bigIntHash(newKey.N),
-		IPAddresses:  []net.IP{net.ParseIP(IP)},
+		IPAddresses:  ipAddresses,
 		KeyUsage: x509.KeyUsageDigitalSignature |
 			x509.KeyUsageDataEncipherment |
 			x509.KeyUsageKeyEncipherment |
 			x509.KeyUsageContentCommitment,
-		DNSNames: serviceNames(IP, service, namespace, dnsname),
+		DNSNames: dnsNames,
 	}
 	newCert, err := x509.CreateCertificate(rand.Reader, template, caCert, &newKey.PublicKey, caKey)
 	if err != nil {
@@ -230,9 +257,8 @@ func bigIntHash(n *big.Int) []byte {
 	return h.Sum(nil)
 }
 
-func serviceNames(IP, service, namespace, dnsname string) []string {
+func serviceNames(service, namespace, dnsname string) []string {
 	return []string{
-		IP,
 		service,
 		fmt.Sprintf("%s.%s", service, namespace),
 		fmt.Sprintf("%s.%s.svc", service, namespace),
@@ -253,3 +279,61 @@ func uint32OrDefault(val uint, defaultval uint) uint {
 	}
 	return defaultval
 }
+
+// GetCertificateExpiry returns the earliest expiry time of the given PEM encoded certificates.
+func GetCertificateExpiry(certsPEM ...[]byte) (time.Time, error) {
+	var expiry time.Time
+	for _, certPEM := range certsPEM {
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			return time.Time{}, fmt.Errorf("failed to decode certificate from PEM form")
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+
+	if expiry.IsZero() {
+		return time.Time{}, fmt.Errorf("no certificate provided")
+	}
+
+	return expiry, nil
+}
+
+// GetCertificateIPs returns the IP addresses in the Subject Alt Names of the given PEM
+// encoded certificate.
+func GetCertificateIPs(certPEM []byte) ([]net.IP, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode certificate from PEM form")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return cert.IPAddresses, nil
+}
+
+// GetCertificateDNSNames returns the DNS names in the Subject Alt Names of the given PEM
+// encoded certificate.
+func GetCertificateDNSNames(certPEM []byte) ([]string, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode certificate from PEM form")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return cert.DNSNames, nil
+}
@@ -17,6 +17,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
@@ -26,10 +27,9 @@ import (
 
 func TestGenerateCerts(t *testing.T) {
 	type testcase struct {
-		config             *Configuration
-		wantContourDNSName string
-		wantEnvoyDNSName   string
-		wantError          error
+		config              *Configuration
+		wantRegistryDNSName string
+		wantError           error
 	}
 
 	run := func(t *testing.T, name string, tc testcase) {
@@ -58,38 +58,31 @@ func TestGenerateCerts(t *testing.T) {
 			ok := roots.AppendCertsFromPEM(got.CACertificate)
 			require.Truef(t, ok, "Failed to set up CA cert for testing, maybe it's an invalid PEM")
 
-			err = verifyCert(got.ContourCertificate, roots, tc.wantContourDNSName, currentTime)
-			assert.NoErrorf(t, err, "Validating %s failed", name)
-
-			err = verifyCert(got.EnvoyCertificate, roots, tc.wantEnvoyDNSName, currentTime)
+			err = verifyCert(got.RegistryCertificate, roots, tc.wantRegistryDNSName, currentTime)
 			assert.NoErrorf(t, err, "Validating %s failed", name)
 		})
 	}
 
 	run(t, "no configuration - use defaults", testcase{
-		config:             &Configuration{},
-		wantContourDNSName: "contour",
-		wantEnvoyDNSName:   "envoy",
-		wantError:          nil,
+		config:              &Configuration{},
+		wantRegistryDNSName: "docker-registry",
+		wantError:           nil,
 	})
 
-	run(t, "custom service names", testcase{
+	run(t, "custom service name", testcase{
 		config: &Configuration{
-			ContourServiceName: "customcontour",
-			EnvoyServiceName:   "customenvoy",
+			RegistryServiceName: "customregistry",
 		},
-		wantContourDNSName: "customcontour",
-		wantEnvoyDNSName:   "customenvoy",
-		wantError:          nil,
+		wantRegistryDNSName: "customregistry",
+		wantError:           nil,
 	})
 
 	run(t, "custom namespace", testcase{
 		config: &Configuration{
 			Namespace: "customnamespace",
 		},
-		wantContourDNSName: "contour",
-		wantEnvoyDNSName:   "envoy",
-		wantError:          nil,
+		wantRegistryDNSName: "docker-registry.customnamespace.svc",
+		wantError:           nil,
 	})
 
 	run(t, "custom lifetime", testcase{
@@ -99,18 +92,40 @@ func TestGenerateCerts(t *testing.T) {
 			// the certs as of a time after the default expiration.
 			Lifetime: DefaultCertificateLifetime * 2,
 		},
-		wantContourDNSName: "contour",
-		wantEnvoyDNSName:   "envoy",
-		wantError:          nil,
+		wantRegistryDNSName: "docker-registry",
+		wantError:           nil,
 	})
 
 	run(t, "custom dns name", testcase{
 		config: &Configuration{
-			DNSName: "project.contour",
+			DNSName: "project.orchest",
+		},
+		wantRegistryDNSName: "docker-registry.orchest.svc.project.orchest",
+		wantError:           nil,
+	})
+
+	run(t, "ipv6 service", testcase{
+		config: &Configuration{
+			IPs: []string{"fd00::a"},
+		},
+		wantRegistryDNSName: "fd00::a",
+		wantError:           nil,
+	})
+
+	run(t, "dual-stack service", testcase{
+		config: &Configuration{
+			IPs: []string{"10.96.0.10", "fd00::a"},
+		},
+		wantRegistryDNSName: "10.96.0.10",
+		wantError:           nil,
+	})
+
+	run(t, "host names", testcase{
+		config: &Configuration{
+			DNSNames: []string{"orchest.example.com", "www.orchest.example.com"},
 		},
-		wantContourDNSName: "contour",
-		wantEnvoyDNSName:   "envoy",
-		wantError:          nil,
+		wantRegistryDNSName: "www.orchest.example.com",
+		wantError:           nil,
 	})
 }
 
@@ -119,30 +134,35 @@ func TestGeneratedCertsValid(t *testing.T) {
 	now := time.Now()
 	expiry := now.Add(24 * 365 * time.Hour)
 
-	cacert, cakey, err := newCA("contour", expiry)
+	cacert, cakey, err := newCA("orchest", expiry)
 	require.NoErrorf(t, err, "Failed to generate CA cert")
 
-	contourcert, _, err := newCert(cacert, cakey, expiry, "contour", "projectcontour", "cluster.local")
-	require.NoErrorf(t, err, "Failed to generate Contour cert")
+	registrycert, _, err := newCert(cacert, cakey, expiry, []string{"10.96.0.10", "fd00::a"}, "docker-registry", "orchest", "cluster.local")
+	require.NoErrorf(t, err, "Failed to generate registry cert")
 
 	roots := x509.NewCertPool()
 	ok := roots.AppendCertsFromPEM(cacert)
 	require.Truef(t, ok, "Failed to set up CA cert for testing, maybe it's an invalid PEM")
 
-	envoycert, _, err := newCert(cacert, cakey, expiry, "envoy", "projectcontour", "cluster.local")
-	require.NoErrorf(t, err, "Failed to generate Envoy cert")
-
 	tests := map[string]struct {
 		cert    []byte
 		dnsname string
 	}{
-		"contour cert": {
-			cert:    contourcert,
-			dnsname: "contour",
+		"registry service name": {
+			cert:    registrycert,
+			dnsname: "docker-registry",
+		},
+		"registry fqdn": {
+			cert:    registrycert,
+			dnsname: "docker-registry.orchest.svc.cluster.local",
 		},
-		"envoy cert": {
-			cert:    envoycert,
-			dnsname: "envoy",
+		"registry ipv4": {
+			cert:    registrycert,
+			dnsname: "10.96.0.10",
+		},
+		"registry ipv6": {
+			cert:    registrycert,
+			dnsname: "fd00::a",
 		},
 	}
 
@@ -155,6 +175,49 @@ func TestGeneratedCertsValid(t *testing.T) {
 
 }
 
+func TestGetCertificateExpiry(t *testing.T) {
+
+	now := time.Now()
+	caExpiry := now.Add(48 * time.Hour)
+	certExpiry := now.Add(24 * time.Hour)
+
+	cacert, cakey, err := newCA("orchest", caExpiry)
+	require.NoErrorf(t, err, "Failed to generate CA cert")
+
+	registrycert, _, err := newCert(cacert, cakey, certExpiry, []string{"10.96.0.10"}, "docker-registry", "orchest", "cluster.local")
+	require.NoErrorf(t, err, "Failed to generate registry cert")
+
+	expiry, err := GetCertificateExpiry(cacert, registrycert)
+	assert.NoError(t, err)
+	assert.Equal(t, certExpiry.UTC().Truncate(time.Second), expiry.UTC())
+
+	_, err = GetCertificateExpiry([]byte("invalid"))
+	assert.Error(t, err)
+
+	_, err = GetCertificateExpiry()
+	assert.Error(t, err)
+}
+
+func TestGetCertificateIPs(t *testing.T) {
+
+	expiry := time.Now().Add(24 * time.Hour)
+
+	cacert, cakey, err := newCA("orchest", expiry)
+	require.NoErrorf(t, err, "Failed to generate CA cert")
+
+	registrycert, _, err := newCert(cacert, cakey, expiry, []string{"10.96.0.10", "fd00::a"}, "docker-registry", "orchest", "cluster.local")
+	require.NoErrorf(t, err, "Failed to generate registry cert")
+
+	ips, err := GetCertificateIPs(registrycert)
+	require.NoError(t, err)
+	require.Len(t, ips, 2)
+	assert.True(t, ips[0].Equal(net.ParseIP("10.96.0.10")))
+	assert.True(t, ips[1].Equal(net.ParseIP("fd00::a")))
+
+	_, _, err = newCert(cacert, cakey, expiry, []string{"invalid"}, "docker-registry", "orchest", "cluster.local")
+	assert.Error(t, err)
+}
+
 func verifyCert(certPEM []byte, roots *x509.CertPool, dnsname string, currentTime time.Time) error {
 	block, _ := pem.Decode(certPEM)
 	if block == nil {
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// The label of the nodes holding the name of their container runtime, the node-agent of
+	// each runtime is scheduled on the nodes by it
+	ContainerRuntimeLabelKey = "orchest.io/container-runtime"
+
+	// Supported container runtimes and their default socket paths
+	containerRuntimeSockets = map[string]string{
+		"docker":     "/var/run/docker.sock",
+		"containerd": "/var/run/containerd/containerd.sock",
+		"cri-o":      "/var/run/crio/crio.sock",
+	}
+)
+
+// ContainerRuntime describes a container runtime of the cluster and the nodes running it
+type ContainerRuntime struct {
+	Name       string
+	SocketPath string
+	Nodes      []string
+}
+
+// GetNodeContainerRuntime returns the name of the container runtime of the node, e.g. cri-o
+// for a node reporting cri-o://1.24.1
+func GetNodeContainerRuntime(node *corev1.Node) string {
+	return strings.Split(node.Status.NodeInfo.ContainerRuntimeVersion, ":")[0]
+}
+
+// IsContainerRuntimeSupported returns true if the container runtime is supported
+func IsContainerRuntimeSupported(runtime string) bool {
+	_, ok := containerRuntimeSockets[runtime]
+	return ok
+}
+
+// IsNodeContainerRuntimeLabeled returns true if the node is labeled with its container runtime,
+// or if its container runtime is not supported and therefore never labeled
+func IsNodeContainerRuntimeLabeled(node *corev1.Node) bool {
+	runtime := GetNodeContainerRuntime(node)
+	return !IsContainerRuntimeSupported(runtime) || node.Labels[ContainerRuntimeLabelKey] == runtime
+}
+
+// DetectContainerRuntimes returns the supported container runtimes of the nodes, the runtime of
+// the most nodes first. Nodes with an unsupported container runtime are skipped, no node-agent
+// can run on them. The socket path of a runtime is taken from the kubeadm annotation of its
+// nodes if present, otherwise the default socket path of the runtime is used.
+func DetectContainerRuntimes(nodes []*corev1.Node) []ContainerRuntime {
+
+	// The nodes of a lister are unordered, sorting them keeps the detected socket path stable
+	nodes = append([]*corev1.Node{}, nodes...)
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	runtimes := map[string]*ContainerRuntime{}
+	annotated := map[string]bool{}
+	for _, node := range nodes {
+
+		name := GetNodeContainerRuntime(node)
+		if !IsContainerRuntimeSupported(name) {
+			klog.V(2).Infof("container runtime %q of node %s is not supported", name, node.Name)
+			continue
+		}
+
+		runtime, ok := runtimes[name]
+		if !ok {
+			runtime = &ContainerRuntime{
+				Name:       name,
+				SocketPath: containerRuntimeSockets[name],
+			}
+			runtimes[name] = runtime
+		}
+
+		// The socket path of the first annotated node is used, the annotation of docker nodes
+		// points to the CRI shim instead of the docker socket
+		socket, ok := node.Annotations[KubeAdmCRISocketAnnotationKey]
+		if ok && name != "docker" && !annotated[name] {
+			runtime.SocketPath = strings.TrimPrefix(socket, "unix://")
+			annotated[name] = true
+		}
+
+		runtime.Nodes = append(runtime.Nodes, node.Name)
+	}
+
+	result := make([]ContainerRuntime, 0, len(runtimes))
+	for _, runtime := range runtimes {
+		result = append(result, *runtime)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Nodes) != len(result[j].Nodes) {
+			return len(result[i].Nodes) > len(result[j].Nodes)
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDetectContainerRuntimes(t *testing.T) {
+
+	getNode := func(name, runtimeVersion, socket string) *corev1.Node {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{
+					ContainerRuntimeVersion: runtimeVersion,
+				},
+			},
+		}
+		if socket != "" {
+			node.Annotations = map[string]string{
+				KubeAdmCRISocketAnnotationKey: socket,
+			}
+		}
+		return node
+	}
+
+	testCases := []struct {
+		name     string
+		nodes    []*corev1.Node
+		expected []ContainerRuntime
+	}{
+		{
+			name: "homogeneous containerd",
+			nodes: []*corev1.Node{
+				getNode("node-1", "containerd://1.6.4", ""),
+				getNode("node-2", "containerd://1.6.4", ""),
+			},
+			expected: []ContainerRuntime{
+				{
+					Name:       "containerd",
+					SocketPath: "/var/run/containerd/containerd.sock",
+					Nodes:      []string{"node-1", "node-2"},
+				},
+			},
+		},
+		{
+			name: "mixed runtimes, most nodes first",
+			nodes: []*corev1.Node{
+				getNode("node-1", "docker://20.10.9", "/var/run/dockershim.sock"),
+				getNode("node-2", "cri-o://1.24.1", "unix:///run/crio/crio.sock"),
+				getNode("node-3", "cri-o://1.24.1", ""),
+			},
+			expected: []ContainerRuntime{
+				{
+					Name:       "cri-o",
+					SocketPath: "/run/crio/crio.sock",
+					Nodes:      []string{"node-2", "node-3"},
+				},
+				{
+					Name:       "docker",
+					SocketPath: "/var/run/docker.sock",
+					Nodes:      []string{"node-1"},
+				},
+			},
+		},
+		{
+			name: "unsupported runtime is skipped",
+			nodes: []*corev1.Node{
+				getNode("node-1", "rkt://1.30.0", ""),
+				getNode("node-2", "containerd://1.6.4", ""),
+			},
+			expected: []ContainerRuntime{
+				{
+					Name:       "containerd",
+					SocketPath: "/var/run/containerd/containerd.sock",
+					Nodes:      []string{"node-2"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, DetectContainerRuntimes(test.nodes))
+		})
+	}
+}
+
+func TestIsNodeContainerRuntimeLabeled(t *testing.T) {
+
+	getNode := func(runtimeVersion string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-1",
+				Labels: labels,
+			},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{
+					ContainerRuntimeVersion: runtimeVersion,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		node     *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "unlabeled node",
+			node:     getNode("containerd://1.6.4", nil),
+			expected: false,
+		},
+		{
+			name:     "labeled node",
+			node:     getNode("cri-o://1.24.1", map[string]string{ContainerRuntimeLabelKey: "cri-o"}),
+			expected: true,
+		},
+		{
+			name:     "node with a changed container runtime",
+			node:     getNode("containerd://1.6.4", map[string]string{ContainerRuntimeLabelKey: "docker"}),
+			expected: false,
+		},
+		{
+			name:     "node with an unsupported container runtime",
+			node:     getNode("rkt://1.30.0", nil),
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsNodeContainerRuntimeLabeled(test.node))
+		})
+	}
+}
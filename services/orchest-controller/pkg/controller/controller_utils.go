@@ -30,6 +30,7 @@ var (
 	AuthServer        = "auth-server"
 	OrchestWebserver  = "orchest-webserver"
 	NodeAgent         = "node-agent"
+	OrchestProxy      = "orchest-proxy"
 
 	// PVC names
 	UserDirName    = "userdir-pvc"
@@ -50,6 +51,8 @@ var (
 	PrefixPathType         = netsv1.PathType("Prefix")
 	IngressClassController = "k8s.io/ingress-nginx"
 
+	TraefikIngressClassController = "traefik.io/ingress-controller"
+
 	// Labels and Annotations
 	OrchestHashLabelKey    = "orchest.io/orchest-hash"
 	DeploymentHashLabelKey = "orchest.io/deployment-hash"
@@ -62,6 +65,14 @@ var (
 	// Runtime annotations
 	KubeAdmCRISocketAnnotationKey           = "kubeadm.alpha.kubernetes.io/cri-socket"
 	ContainerRuntimeSocketPathAnnotationKey = "orchest.io/container-runtime-socket"
+
+	// The keys of the OIDC client credentials in the OIDC client Secret
+	OIDCClientIDKey     = "client-id"
+	OIDCClientSecretKey = "client-secret"
+
+	// GPU nodes
+	GPULabelKey        = "orchest.io/gpu"
+	DefaultGPUResource = "nvidia.com/gpu"
 )
 
 // AddFinalizer adds specified finalizer string to object
@@ -182,6 +193,12 @@ func UpsertObject(ctx context.Context, generalClient client.Client, object clien
 	return nil
 }
 
+// GetResourceName returns the name of an object of an OrchestCluster, the objects of a cluster
+// are prefixed with its name, so several clusters can run in the same namespace.
+func GetResourceName(clusterName, resourceName string) string {
+	return clusterName + "-" + resourceName
+}
+
 func GetMetadata(resourceName, hash string,
 	object client.Object, kind schema.GroupVersionKind) metav1.ObjectMeta {
 
@@ -244,20 +261,16 @@ func GetOrchestLabelSelector(object client.Object) (labels.Selector, error) {
 	return selector, nil
 }
 
-func GetRbacManifest(metadata metav1.ObjectMeta) []client.Object {
+// GetRbacManifest returns the namespaced Role, RoleBinding and ServiceAccount of a component,
+// the Role grants the rules in the namespace of the component only
+func GetRbacManifest(metadata metav1.ObjectMeta, rules []rbacv1.PolicyRule) []client.Object {
 
-	clusterRole := &rbacv1.ClusterRole{
+	role := &rbacv1.Role{
 		ObjectMeta: metadata,
-		Rules: []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{"*"},
-				Resources: []string{"*"},
-				Verbs:     []string{"*"},
-			},
-		},
+		Rules:      rules,
 	}
 
-	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+	roleBinding := &rbacv1.RoleBinding{
 		ObjectMeta: metadata,
 		Subjects: []rbacv1.Subject{
 			{
@@ -269,7 +282,7 @@ func GetRbacManifest(metadata metav1.ObjectMeta) []client.Object {
 		RoleRef: rbacv1.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
 			Name:     metadata.Name,
-			Kind:     "ClusterRole",
+			Kind:     "Role",
 		},
 	}
 
@@ -278,8 +291,8 @@ func GetRbacManifest(metadata metav1.ObjectMeta) []client.Object {
 	}
 
 	return []client.Object{
-		clusterRole,
-		clusterRoleBinding,
+		role,
+		roleBinding,
 		serviceAccount,
 	}
 }
@@ -0,0 +1,201 @@
+package orchestcluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// The secret holding the secrets generated for the applications of Orchest, it is owned by
+	// the OrchestCluster so it is kept while the cluster is paused or updated
+	applicationSecrets = "application-secrets"
+
+	// The keys of the secret keys of the components in the application secrets
+	secretKeys = map[string]string{
+		controller.OrchestWebserver: "orchest-webserver-secret-key",
+		controller.OrchestApi:       "orchest-api-secret-key",
+		controller.AuthServer:       "auth-server-secret-key",
+	}
+
+	// If present on the OrchestCluster, the application secrets are rotated
+	RotateApplicationSecretsAnnotationKey = "orchest.io/rotate-application-secrets"
+)
+
+// ensureApplicationSecrets makes sure the application secrets exist, the missing secrets, e.g.
+// of components added by an update, are generated and all of them if their rotation is
+// requested. The components using a changed secret are rolled out.
+func (occ *OrchestClusterController) ensureApplicationSecrets(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	_, rotate := orchest.GetAnnotations()[RotateApplicationSecretsAnnotationKey]
+
+	secretName := controller.GetResourceName(orchest.Name, applicationSecrets)
+	oldSecret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	var oldData map[string][]byte
+	if exists {
+		oldData = oldSecret.Data
+	}
+
+	if rotate {
+		klog.Infof("Rotating the application secrets of OrchestCluster %s", orchest.Name)
+	}
+
+	data, err := generateApplicationSecrets(oldData, rotate)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		_, err = occ.Client().CoreV1().Secrets(orchest.Namespace).Create(ctx,
+			getApplicationSecret(orchest, data), metav1.CreateOptions{})
+	} else if !reflect.DeepEqual(oldData, data) {
+		oldSecret = oldSecret.DeepCopy()
+		oldSecret.Data = data
+		_, err = occ.Client().CoreV1().Secrets(orchest.Namespace).Update(ctx, oldSecret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the application secrets of %s", orchest.Name)
+	}
+
+	err = occ.ensureSecretKeys(ctx, orchest, data)
+	if err != nil {
+		return err
+	}
+
+	if rotate {
+		_, err = controller.RemoveAnnotation(ctx, occ.gClient, orchest, RotateApplicationSecretsAnnotationKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureSecretKeys updates the secret key hash of the components if their secret key changed,
+// which rolls out their pods to use the new secret key.
+func (occ *OrchestClusterController) ensureSecretKeys(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, data map[string][]byte) error {
+
+	// Components are recreated with the current hash while the cluster is not running
+	if orchest.Status == nil || orchest.Status.Phase != orchestv1alpha1.Running {
+		return nil
+	}
+
+	components, err := GetOrchestComponents(ctx, orchest, occ.oComponentLister)
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		if component.Spec.SecretKey == nil {
+			continue
+		}
+
+		hash := utils.ComputeHash(data[component.Spec.SecretKey.Key])
+		if component.Spec.SecretKeyHash == hash {
+			continue
+		}
+
+		klog.Infof("Secret key of %s changed, rolling out %s", orchest.Name, component.Name)
+
+		component = component.DeepCopy()
+		component.Spec.SecretKeyHash = hash
+		_, err = occ.oClient.OrchestV1alpha1().OrchestComponents(orchest.Namespace).Update(ctx, component, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to roll out %s", component.Name)
+		}
+	}
+
+	return nil
+}
+
+// getSecretKeyHash returns the hash of the secret key of the component, or an empty string if
+// the component has no secret key.
+func (occ *OrchestClusterController) getSecretKeyHash(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, name string) (string, error) {
+
+	key, ok := secretKeys[name]
+	if !ok {
+		return "", nil
+	}
+
+	secretName := controller.GetResourceName(orchest.Name, applicationSecrets)
+	secret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the application secrets %s", secretName)
+	}
+
+	return utils.ComputeHash(secret.Data[key]), nil
+}
+
+// getSecretKeySelector returns the selector of the secret key of the component, or nil if the
+// component has no secret key
+func getSecretKeySelector(orchest *orchestv1alpha1.OrchestCluster, name string) *corev1.SecretKeySelector {
+
+	key, ok := secretKeys[name]
+	if !ok {
+		return nil
+	}
+
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{
+			Name: controller.GetResourceName(orchest.Name, applicationSecrets),
+		},
+		Key: key,
+	}
+}
+
+// generateApplicationSecrets returns the application secrets, the secrets missing in data are
+// generated, and all of them if rotate is true.
+func generateApplicationSecrets(data map[string][]byte, rotate bool) (map[string][]byte, error) {
+
+	newData := make(map[string][]byte, len(secretKeys))
+	for key, value := range data {
+		newData[key] = value
+	}
+
+	for _, key := range secretKeys {
+		if _, ok := newData[key]; ok && !rotate {
+			continue
+		}
+
+		secretBytes := make([]byte, 32)
+		if _, err := rand.Read(secretBytes); err != nil {
+			return nil, errors.Wrapf(err, "failed to generate %s", key)
+		}
+		newData[key] = []byte(hex.EncodeToString(secretBytes))
+	}
+
+	return newData, nil
+}
+
+func getApplicationSecret(orchest *orchestv1alpha1.OrchestCluster, data map[string][]byte) *corev1.Secret {
+
+	metadata := controller.GetMetadata(applicationSecrets, fmt.Sprint(orchest.Generation),
+		orchest, OrchestClusterKind)
+	metadata.Name = controller.GetResourceName(orchest.Name, applicationSecrets)
+
+	return &corev1.Secret{
+		ObjectMeta: metadata,
+		Type:       corev1.SecretTypeOpaque,
+		Data:       data,
+	}
+}
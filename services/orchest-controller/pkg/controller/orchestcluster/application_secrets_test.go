@@ -0,0 +1,50 @@
+package orchestcluster
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGenerateApplicationSecrets(t *testing.T) {
+
+	data, err := generateApplicationSecrets(nil, false)
+	assert.NoError(t, err)
+	assert.Len(t, data, len(secretKeys))
+	for _, key := range secretKeys {
+		assert.Len(t, data[key], 64)
+	}
+
+	// The existing secrets are kept, the missing ones are generated
+	oldData := map[string][]byte{
+		secretKeys[controller.OrchestApi]: []byte("api"),
+		"custom":                          []byte("custom"),
+	}
+	data, err = generateApplicationSecrets(oldData, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "api", string(data[secretKeys[controller.OrchestApi]]))
+	assert.Equal(t, "custom", string(data["custom"]))
+	assert.Len(t, data[secretKeys[controller.AuthServer]], 64)
+	assert.Len(t, oldData, 2)
+
+	// All of them are rotated
+	data, err = generateApplicationSecrets(oldData, true)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "api", string(data[secretKeys[controller.OrchestApi]]))
+}
+
+func TestGetSecretKeySelector(t *testing.T) {
+
+	orchest := &orchestv1alpha1.OrchestCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: "orchest"},
+	}
+
+	selector := getSecretKeySelector(orchest, controller.OrchestWebserver)
+	assert.Equal(t, "cluster-1-application-secrets", selector.Name)
+	assert.Equal(t, "orchest-webserver-secret-key", selector.Key)
+
+	assert.Nil(t, getSecretKeySelector(orchest, controller.Rabbitmq))
+}
@@ -0,0 +1,250 @@
+package orchestcluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// The interval the OIDC client Secrets are checked for changes at
+	authenticationCheckPeriod = time.Minute
+
+	// The timeout of the request of the discovery document of the OIDC issuer
+	oidcIssuerTimeout = 10 * time.Second
+)
+
+// validateAuthentication validates the authentication configuration of the OrchestCluster
+func validateAuthentication(authentication *orchestv1alpha1.AuthenticationSpec,
+	orchestHost *string) error {
+
+	oidc := authentication.OIDC
+	if oidc == nil {
+		return nil
+	}
+
+	// The auth-server builds the redirect url of the provider from the host, rather than from
+	// the headers of the requests
+	if orchestHost == nil || *orchestHost == "" {
+		return errors.New("the orchest host has to be specified to sign in through OIDC")
+	}
+
+	issuerURL, err := url.Parse(oidc.IssuerURL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid issuer url %s", oidc.IssuerURL)
+	}
+
+	// The auth-server does not verify the signature of the ID tokens, they are authenticated
+	// by the TLS connection to the token endpoint of the issuer
+	if issuerURL.Scheme != "https" || issuerURL.Host == "" {
+		return errors.Errorf("the issuer url %s has to be an absolute https url", oidc.IssuerURL)
+	}
+
+	if oidc.ClientSecret == "" {
+		return errors.New("the client secret has to be specified")
+	}
+
+	for _, group := range oidc.AllowedGroups {
+		if group == "" {
+			return errors.New("the allowed groups can not be empty")
+		}
+	}
+
+	return nil
+}
+
+// checkOIDCIssuer fetches the discovery document of the OIDC issuer and verifies the issuer
+// it advertises is the configured one, as the auth-server rejects the tokens otherwise.
+func checkOIDCIssuer(ctx context.Context, client *http.Client, issuerURL string) error {
+
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+
+	ctx, cancel := context.WithTimeout(ctx, oidcIssuerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the request of %s", discoveryURL)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "the OIDC issuer %s is not reachable", issuerURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("the OIDC issuer %s returned %s for its discovery document",
+			issuerURL, resp.Status)
+	}
+
+	var discovery struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return errors.Wrapf(err, "failed to decode the discovery document of %s", issuerURL)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuerURL, "/") {
+		return errors.Errorf("the OIDC issuer %s advertises the issuer %s", issuerURL, discovery.Issuer)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return errors.Errorf("the discovery document of %s has no authorization or token endpoint",
+			issuerURL)
+	}
+
+	return nil
+}
+
+// getOIDCHTTPClient returns the client the OIDC issuer is requested with, it trusts the
+// trusted CA certificates of the OrchestCluster in addition to the system ones.
+func (occ *OrchestClusterController) getOIDCHTTPClient(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (*http.Client, error) {
+
+	ca := orchest.Spec.Orchest.TrustedCA
+	if ca == nil {
+		return http.DefaultClient, nil
+	}
+
+	configMap, err := occ.Client().CoreV1().ConfigMaps(orchest.Namespace).Get(ctx, ca.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the trusted CA ConfigMap %s", ca.Name)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	pool.AppendCertsFromPEM([]byte(configMap.Data[ca.Key]))
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// validateOIDC verifies the OIDC client Secret exists and the issuer is reachable, the issuer
+// is only checked while the OrchestCluster is not running to not request it on every sync.
+func (occ *OrchestClusterController) validateOIDC(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	oidc := orchest.Spec.Orchest.Authentication.OIDC
+
+	if _, err := occ.getAuthenticationHash(ctx, orchest); err != nil {
+		return err
+	}
+
+	if orchest.Status != nil && orchest.Status.Phase == orchestv1alpha1.Running {
+		return nil
+	}
+
+	client, err := occ.getOIDCHTTPClient(ctx, orchest)
+	if err != nil {
+		return err
+	}
+
+	return checkOIDCIssuer(ctx, client, oidc.IssuerURL)
+}
+
+// getAuthenticationHash returns the hash of the OIDC client credentials of the OrchestCluster,
+// or an empty string if OIDC is not configured.
+func (occ *OrchestClusterController) getAuthenticationHash(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (string, error) {
+
+	authentication := orchest.Spec.Orchest.Authentication
+	if authentication == nil || authentication.OIDC == nil {
+		return "", nil
+	}
+
+	name := authentication.OIDC.ClientSecret
+	secret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the OIDC client secret %s", name)
+	}
+
+	for _, key := range []string{controller.OIDCClientIDKey, controller.OIDCClientSecretKey} {
+		if len(secret.Data[key]) == 0 {
+			return "", errors.Errorf("the OIDC client secret %s has no key %s", name, key)
+		}
+	}
+
+	return utils.ComputeHash(secret.Data), nil
+}
+
+// checkAuthentications restarts the auth-server of all OrchestClusters whose OIDC client
+// credentials changed
+func (occ *OrchestClusterController) checkAuthentications() {
+
+	ctx, cancel := context.WithTimeout(context.Background(), authenticationCheckPeriod)
+	defer cancel()
+
+	orchests, err := occ.oClusterLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list OrchestClusters, error: %v", err)
+		return
+	}
+
+	for _, orchest := range orchests {
+		err = occ.ensureAuthentication(ctx, orchest)
+		if err != nil {
+			klog.Errorf("failed to check the authentication of OrchestCluster %s, error: %v",
+				orchest.Name, err)
+		}
+	}
+}
+
+// ensureAuthentication updates the authentication hash of the auth-server if the OIDC client
+// credentials changed, which rolls out its pod to read the new credentials.
+func (occ *OrchestClusterController) ensureAuthentication(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	// The auth-server is recreated with the current hash while the cluster is not running
+	if orchest.Spec.Orchest.Authentication == nil || orchest.Status == nil ||
+		orchest.Status.Phase != orchestv1alpha1.Running {
+		return nil
+	}
+
+	hash, err := occ.getAuthenticationHash(ctx, orchest)
+	if err != nil {
+		return err
+	}
+
+	components, err := GetOrchestComponents(ctx, orchest, occ.oComponentLister)
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		if component.Spec.ReconcilerName != controller.AuthServer ||
+			component.Spec.AuthenticationHash == hash {
+			continue
+		}
+
+		klog.Infof("OIDC client of OrchestCluster %s changed, rolling out %s", orchest.Name, component.Name)
+
+		component = component.DeepCopy()
+		component.Spec.AuthenticationHash = hash
+		_, err = occ.oClient.OrchestV1alpha1().OrchestComponents(orchest.Namespace).Update(ctx, component, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to roll out %s", component.Name)
+		}
+	}
+
+	return nil
+}
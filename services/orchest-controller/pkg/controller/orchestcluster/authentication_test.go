@@ -0,0 +1,107 @@
+package orchestcluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAuthentication(t *testing.T) {
+
+	testCases := []struct {
+		name          string
+		oidc          *orchestv1alpha1.OIDCSpec
+		orchestHost   string
+		expectedError bool
+	}{
+		{
+			name: "no oidc",
+		},
+		{
+			name: "valid",
+			oidc: &orchestv1alpha1.OIDCSpec{
+				IssuerURL:     "https://dex.example.com/dex",
+				ClientSecret:  "orchest-oidc",
+				AllowedGroups: []string{"data-science"},
+				AdminGroup:    "admins",
+			},
+			orchestHost: "orchest.example.com",
+		},
+		{
+			name:          "no orchest host",
+			oidc:          &orchestv1alpha1.OIDCSpec{IssuerURL: "https://dex.example.com", ClientSecret: "orchest-oidc"},
+			expectedError: true,
+		},
+		{
+			name:          "relative issuer url",
+			oidc:          &orchestv1alpha1.OIDCSpec{IssuerURL: "/dex", ClientSecret: "orchest-oidc"},
+			orchestHost:   "orchest.example.com",
+			expectedError: true,
+		},
+		{
+			name:          "http issuer url",
+			oidc:          &orchestv1alpha1.OIDCSpec{IssuerURL: "http://dex.example.com/dex", ClientSecret: "orchest-oidc"},
+			orchestHost:   "orchest.example.com",
+			expectedError: true,
+		},
+		{
+			name:          "no client secret",
+			oidc:          &orchestv1alpha1.OIDCSpec{IssuerURL: "https://dex.example.com"},
+			orchestHost:   "orchest.example.com",
+			expectedError: true,
+		},
+		{
+			name: "empty allowed group",
+			oidc: &orchestv1alpha1.OIDCSpec{
+				IssuerURL:     "https://dex.example.com",
+				ClientSecret:  "orchest-oidc",
+				AllowedGroups: []string{""},
+			},
+			orchestHost:   "orchest.example.com",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateAuthentication(&orchestv1alpha1.AuthenticationSpec{OIDC: test.oidc},
+				&test.orchestHost)
+			assert.Equal(t, test.expectedError, err != nil)
+		})
+	}
+}
+
+func TestCheckOIDCIssuer(t *testing.T) {
+
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dex/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": issuer + "/auth",
+			"token_endpoint":         issuer + "/token",
+		})
+	}))
+	defer server.Close()
+
+	issuer = server.URL + "/dex"
+	assert.NoError(t, checkOIDCIssuer(context.Background(), server.Client(), issuer))
+	assert.NoError(t, checkOIDCIssuer(context.Background(), server.Client(), issuer+"/"))
+
+	// The issuer advertised by the discovery document has to match
+	assert.Error(t, checkOIDCIssuer(context.Background(), server.Client(), server.URL+"/other"))
+
+	issuer = "https://dex.example.com/dex"
+	assert.Error(t, checkOIDCIssuer(context.Background(), server.Client(), server.URL+"/dex"))
+
+	server.Close()
+	assert.Error(t, checkOIDCIssuer(context.Background(), server.Client(), server.URL+"/dex"))
+}
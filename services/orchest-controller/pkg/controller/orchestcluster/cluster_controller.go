@@ -20,7 +20,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	corelister "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,6 +38,8 @@ type ControllerConfig struct {
 	PostgresDefaultImage string
 	// Rabbitmq default image to use if not provided
 	RabbitmqDefaultImage string
+	// orchest-proxy default image to use if not provided
+	ProxyDefaultImage string
 	// Orchest Default version to use if not provided
 	OrchestDefaultVersion string
 	// celery-worker default image to use if not provided
@@ -74,6 +79,7 @@ func NewDefaultControllerConfig() ControllerConfig {
 	return ControllerConfig{
 		PostgresDefaultImage:      "postgres:13.1",
 		RabbitmqDefaultImage:      "rabbitmq:3",
+		ProxyDefaultImage:         "nginx:1.25-alpine",
 		OrchestDefaultVersion:     version.Version,
 		CeleryWorkerImageName:     "orchest/celery-worker",
 		OrchestApiImageName:       "orchest/orchest-api",
@@ -87,25 +93,21 @@ func NewDefaultControllerConfig() ControllerConfig {
 			"ORCHEST_HOST_GID":  "1",
 		},
 		OrchestApiDefaultEnvVars: map[string]string{
-			"ORCHEST_GPU_ENABLED_INSTANCE": "FALSE",
-			"FLASK_ENV":                    "production",
+			"FLASK_ENV": "production",
 		},
 
 		OrchestWebserverDefaultEnvVars: map[string]string{
-			"ORCHEST_GPU_ENABLED_INSTANCE": "FALSE",
-			"FLASK_ENV":                    "production",
-			"ORCHEST_PORT":                 "8000",
-			"USERDIR_PVC":                  "userdir-pvc",
-			"HOST_CONFIG_DIR":              "/var/lib/orchest/config",
-			"HOST_REPO_DIR":                "/var/lib/orchest/repo",
-			"HOST_OS":                      "linux",
+			"FLASK_ENV":       "production",
+			"ORCHEST_PORT":    "8000",
+			"HOST_CONFIG_DIR": "/var/lib/orchest/config",
+			"HOST_REPO_DIR":   "/var/lib/orchest/repo",
+			"HOST_OS":         "linux",
 		},
 		AuthServerDefaultEnvVars: map[string]string{
 			"FLASK_ENV": "production",
 		},
 		CeleryWorkerDefaultEnvVars: map[string]string{
-			"ORCHEST_GPU_ENABLED_INSTANCE": "FALSE",
-			"FLASK_ENV":                    "production",
+			"FLASK_ENV": "production",
 		},
 		OrchestDatabaseDefaultEnvVars: map[string]string{
 			"PGDATA":                    "/userdir/.orchest/database/data",
@@ -152,6 +154,8 @@ type OrchestClusterController struct {
 
 	oComponentLister orchestlisters.OrchestComponentLister
 
+	nodeLister corelister.NodeLister
+
 	addonManager *addons.AddonManager
 }
 
@@ -163,6 +167,7 @@ func NewOrchestClusterController(kClient kubernetes.Interface,
 	config ControllerConfig,
 	oClusterInformer orchestinformers.OrchestClusterInformer,
 	oComponentInformer orchestinformers.OrchestComponentInformer,
+	nodeInformer coreinformers.NodeInformer,
 	addonManager *addons.AddonManager,
 ) *OrchestClusterController {
 
@@ -202,6 +207,15 @@ func NewOrchestClusterController(kClient kubernetes.Interface,
 	informerSyncedList = append(informerSyncedList, oComponentInformer.Informer().HasSynced)
 	occ.oComponentLister = oComponentInformer.Lister()
 
+	// Node event handlers
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    occ.addNode,
+		UpdateFunc: occ.updateNode,
+		DeleteFunc: occ.deleteNode,
+	})
+	informerSyncedList = append(informerSyncedList, nodeInformer.Informer().HasSynced)
+	occ.nodeLister = nodeInformer.Lister()
+
 	ctrl.InformerSyncedList = informerSyncedList
 	ctrl.SyncHandler = occ.syncOrchestCluster
 	ctrl.ControleeGetter = occ.getOrchestCluster
@@ -211,6 +225,24 @@ func NewOrchestClusterController(kClient kubernetes.Interface,
 	return &occ
 }
 
+// Run starts the controller and the loops which periodically check the state of the
+// OrchestClusters, it will not return until stopCh is closed.
+func (occ *OrchestClusterController) Run(stopCh <-chan struct{}) {
+
+	go func() {
+		if !cache.WaitForCacheSync(stopCh, occ.InformerSyncedList...) {
+			return
+		}
+		go wait.Until(occ.checkRegistryGarbageCollections, registryGCCheckPeriod, stopCh)
+		go wait.Until(occ.checkTrustedCAs, trustedCACheckPeriod, stopCh)
+		go wait.Until(occ.checkAuthentications, authenticationCheckPeriod, stopCh)
+		go wait.Until(occ.checkIngressCertificates, ingressCertCheckPeriod, stopCh)
+		wait.Until(occ.checkRegistryCertificates, registryCertCheckPeriod, stopCh)
+	}()
+
+	occ.Controller.Run(stopCh)
+}
+
 func (occ *OrchestClusterController) addOrchestCluster(obj interface{}) {
 	oc := obj.(*orchestv1alpha1.OrchestCluster)
 	klog.V(4).Infof("Adding OrchestCluster %s", oc.Name)
@@ -315,6 +347,12 @@ func (occ *OrchestClusterController) syncOrchestCluster(ctx context.Context, key
 
 	}
 
+	// The objects of previous versions of the controller are migrated before anything is deployed
+	migrated, err := occ.migrateLegacyObjects(ctx, orchest)
+	if !migrated || err != nil {
+		return err
+	}
+
 	ok, err := occ.validateOrchestCluster(ctx, orchest)
 	if err != nil {
 		klog.Error(err)
@@ -339,6 +377,12 @@ func (occ *OrchestClusterController) syncOrchestCluster(ctx context.Context, key
 		return nil
 	}
 
+	// The application secrets are generated on the first install and rotated on request
+	err = occ.ensureApplicationSecrets(ctx, orchest)
+	if err != nil {
+		return err
+	}
+
 	return occ.manageOrchestCluster(ctx, orchest)
 }
 
@@ -347,18 +391,129 @@ func (occ *OrchestClusterController) validateOrchestCluster(ctx context.Context,
 	var err error
 	if orchest.Spec.Orchest.Resources.StorageClassName != "" {
 		_, err := occ.Client().StorageV1().StorageClasses().Get(ctx, orchest.Spec.Orchest.Resources.StorageClassName, metav1.GetOptions{})
-		if err != nil && kerrors.IsNotFound(err) {
+		if kerrors.IsNotFound(err) {
+			klog.Errorf("the storage class %s is not found", orchest.Spec.Orchest.Resources.StorageClassName)
+			return false, nil
+		} else if err != nil {
+			return false, errors.Wrap(err, "failed to get the storage class")
+		}
+	}
+
+	if registry := orchest.Spec.Orchest.ImageRegistry; registry != nil {
+		if registry.URL == "" {
+			klog.Errorf("the url of the image registry of OrchestCluster %s is not specified", orchest.Name)
+			return false, nil
+		}
+
+		if registry.CredentialsSecret != "" {
+			_, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, registry.CredentialsSecret, metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				klog.Errorf("the image registry credentials secret %s is not found", registry.CredentialsSecret)
+				return false, nil
+			} else if err != nil {
+				return false, errors.Wrap(err, "failed to get the image registry credentials secret")
+			}
+		}
+	}
+
+	if ca := orchest.Spec.Orchest.TrustedCA; ca != nil {
+		_, err := occ.Client().CoreV1().ConfigMaps(orchest.Namespace).Get(ctx, ca.Name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			klog.Errorf("the trusted CA ConfigMap %s is not found", ca.Name)
+			return false, nil
+		} else if err != nil {
+			return false, errors.Wrap(err, "failed to get the trusted CA ConfigMap")
+		}
+	}
+
+	if imagePuller := orchest.Spec.Orchest.NodeAgent.ImagePuller; imagePuller != nil {
+		if err := validateImagePuller(imagePuller); err != nil {
+			klog.Errorf("the image puller of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
+			return false, nil
+		}
+	}
+
+	if tls := orchest.Spec.Orchest.TLS; tls != nil {
+		if err := validateTLS(tls, orchest.Spec.Orchest.OrchestHost); err != nil {
+			klog.Errorf("the tls of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
+			return false, nil
+		}
+
+		if tls.IssuerRef == nil && !tls.SelfSigned {
+			_, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, tls.SecretName, metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				klog.Errorf("the ingress TLS secret %s is not found", tls.SecretName)
+				return false, nil
+			} else if err != nil {
+				return false, errors.Wrap(err, "failed to get the ingress TLS secret")
+			}
+		}
+	}
+
+	if ingress := orchest.Spec.Orchest.Ingress; ingress != nil {
+		if err := validateIngress(ingress); err != nil {
+			klog.Errorf("the ingress of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
+			return false, nil
+		}
+	}
+
+	if err := validateOrchestHostAliases(orchest); err != nil {
+		klog.Errorf("the host aliases of OrchestCluster %s are invalid, error: %v", orchest.Name, err)
+		return false, nil
+	}
+
+	if err := validateBasePath(orchest.Spec.Orchest.BasePath); err != nil {
+		klog.Errorf("the base path of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
+		return false, nil
+	}
+
+	if exposure := orchest.Spec.Orchest.Exposure; exposure != nil {
+		if err := validateExposure(exposure, orchest.Spec.Orchest.TLS); err != nil {
+			klog.Errorf("the exposure of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
+			return false, nil
+		}
+	}
+
+	if authentication := orchest.Spec.Orchest.Authentication; authentication != nil {
+		if err := validateAuthentication(authentication, orchest.Spec.Orchest.OrchestHost); err != nil {
+			klog.Errorf("the authentication of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
+			return false, nil
+		}
+
+		if authentication.OIDC != nil {
+			if err := occ.validateOIDC(ctx, orchest); err != nil {
+				klog.Errorf("the OIDC of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
+				return false, nil
+			}
+		}
+	}
+
+	if podSecurity := orchest.Spec.Orchest.PodSecurity; podSecurity != nil {
+		if err := validatePodSecurity(podSecurity); err != nil {
+			klog.Errorf("the pod security of OrchestCluster %s is invalid, error: %v", orchest.Name, err)
 			return false, nil
 		}
 	}
 
 	// Detect runtime environment
-	runtime, socketPath, err := detectContainerRuntime(ctx, occ.Client(), orchest)
+	runtimes, err := occ.detectContainerRuntimes(ctx, orchest)
+	if err != nil {
+		return false, err
+	}
+
+	err = occ.updateContainerRuntimeStatus(ctx, orchest, runtimes)
 	if err != nil {
 		return false, err
 	}
-	occ.config.OrchestDefaultEnvVars["CONTAINER_RUNTIME"] = runtime
-	occ.config.OrchestDefaultEnvVars["CONTAINER_RUNTIME_SOCKET"] = socketPath
+
+	err = occ.ensureGPUs(ctx, orchest)
+	if err != nil {
+		return false, err
+	}
+
+	// Sessions and pipeline runs use the container runtime of the most nodes
+	occ.config.OrchestDefaultEnvVars["CONTAINER_RUNTIME"] = runtimes[0].Name
+	occ.config.OrchestDefaultEnvVars["CONTAINER_RUNTIME_SOCKET"] = runtimes[0].SocketPath
 	occ.config.OrchestDefaultEnvVars["CONTAINER_RUNTIME_IMAGE"] = utils.GetFullImageName(orchest.Spec.Orchest.Registry,
 		"image-puller", occ.config.OrchestDefaultVersion)
 
@@ -419,9 +574,13 @@ func (occ *OrchestClusterController) setDefaultIfNotSpecified(ctx context.Contex
 		}, false)
 	changed = changed || envChanged
 
-	if copy.Spec.Orchest.OrchestHost != nil {
+	if host := copy.Spec.Orchest.OrchestHost; host != nil && *host != "" {
 		envChanged := utils.UpsertEnvVariable(&copy.Spec.Orchest.Env,
-			map[string]string{"ORCHEST_FQDN": *copy.Spec.Orchest.OrchestHost}, true)
+			map[string]string{"ORCHEST_FQDN": *host}, true)
+		changed = changed || envChanged
+	} else {
+		// The host is removed, the components should not be exposed at the former one
+		envChanged := utils.RemoveEnvVariable(&copy.Spec.Orchest.Env, "ORCHEST_FQDN")
 		changed = changed || envChanged
 	}
 
@@ -441,6 +600,15 @@ func (occ *OrchestClusterController) setDefaultIfNotSpecified(ctx context.Contex
 		occ.config.OrchestApiDefaultEnvVars, false)
 	changed = changed || envChanged
 
+	// orchest-api doesn't run the registry garbage collection if the controller does
+	registryGCManaged := "False"
+	if isRegistryGCEnabled(copy) {
+		registryGCManaged = "True"
+	}
+	envChanged = utils.UpsertEnvVariable(&copy.Spec.Orchest.OrchestApi.Env,
+		map[string]string{"ORCHEST_REGISTRY_GARBAGE_COLLECTION_MANAGED": registryGCManaged}, true)
+	changed = changed || envChanged
+
 	// Orchest-Webserver configs
 	newImage, update = isUpdateRequired(copy, controller.OrchestWebserver, copy.Spec.Orchest.OrchestWebServer.Image)
 	if update {
@@ -519,6 +687,12 @@ func (occ *OrchestClusterController) setDefaultIfNotSpecified(ctx context.Contex
 		copy.Spec.RabbitMq.Env = utils.GetEnvVarFromMap(occ.config.RabbitmqDefaultEnvVars)
 	}
 
+	// orchest-proxy configs
+	if isProxyEnabled(copy) && copy.Spec.Orchest.Exposure.Proxy.Image == "" {
+		changed = true
+		copy.Spec.Orchest.Exposure.Proxy.Image = occ.config.ProxyDefaultImage
+	}
+
 	if copy.Spec.Orchest.Resources.UserDirVolumeSize == "" {
 		changed = true
 		copy.Spec.Orchest.Resources.UserDirVolumeSize = occ.config.UserdirDefaultVolumeSize
@@ -534,12 +708,12 @@ func (occ *OrchestClusterController) setDefaultIfNotSpecified(ctx context.Contex
 		copy.Spec.Applications = occ.config.DefaultApplications
 	}
 
-	// set docker-registry default values
+	// set docker-registry default values, unless an external registry is used
 	for i := 0; i < len(copy.Spec.Applications); i++ {
 		app := &copy.Spec.Applications[i]
-		if app.Name == addons.DockerRegistry {
+		if app.Name == addons.DockerRegistry && copy.Spec.Orchest.ImageRegistry == nil {
 
-			registryChanged, err := setRegistryServiceIP(ctx, occ.Client(), copy.Namespace, app)
+			registryChanged, err := setRegistryServiceIP(ctx, occ.Client(), copy, app)
 			if err != nil {
 				klog.Error(err)
 				return changed, err
@@ -567,8 +741,15 @@ func (occ *OrchestClusterController) ensureThirdPartyDependencies(ctx context.Co
 	orchest *orchestv1alpha1.OrchestCluster) (err error) {
 
 	updateConditionPreInstall := func(app *orchestv1alpha1.ApplicationSpec) error {
-		err = occ.updateCondition(ctx, orchest.Namespace, orchest.Name,
-			orchestv1alpha1.OrchestClusterEvent(fmt.Sprintf("Deploying %s", app.Name)))
+		event := orchestv1alpha1.OrchestClusterEvent(fmt.Sprintf("Deploying %s", app.Name))
+		switch app.Name {
+		case addons.CertManager:
+			event = orchestv1alpha1.DeployingCertManager
+		case addons.IngressNginx:
+			event = orchestv1alpha1.DeployingNginxIngress
+		}
+
+		err = occ.updateCondition(ctx, orchest.Namespace, orchest.Name, event)
 		if err != nil {
 			klog.Error(err)
 			return err
@@ -587,12 +768,12 @@ func (occ *OrchestClusterController) ensureThirdPartyDependencies(ctx context.Co
 			return errors.Wrapf(err, "failed to update orchest with registry service ip  %q", orchest.Name)
 		}
 
-		serviceIP, err := getRegistryServiceIP(&app.Config)
+		serviceIPs, err := getRegistryServiceIPs(&app.Config)
 		if err != nil {
 			return err
 		}
 
-		err = registryCertgen(ctx, occ.Client(), serviceIP, orchest)
+		err = registryCertgen(ctx, occ.Client(), serviceIPs, orchest, false)
 		if err != nil {
 			klog.Error(err)
 			return err
@@ -601,16 +782,41 @@ func (occ *OrchestClusterController) ensureThirdPartyDependencies(ctx context.Co
 		return nil
 	}
 
-	for _, application := range orchest.Spec.Applications {
+	for _, application := range getApplications(orchest) {
+		// The in-cluster registry is not needed if an external registry is used
+		if application.Name == addons.DockerRegistry && orchest.Spec.Orchest.ImageRegistry != nil {
+			continue
+		}
+
 		preInstallHooks := []addons.PreInstallHookFn{
 			updateConditionPreInstall,
 		}
 
+		app := &application
 		if application.Name == addons.DockerRegistry {
 			preInstallHooks = append(preInstallHooks, registryPreInstall)
+
+			app, err = occ.withRegistryCredentials(ctx, orchest, withRegistryNames(orchest, app))
+			if err != nil {
+				klog.Error(err)
+				return err
+			}
+		}
+
+		addon, err := occ.addonManager.ResolveAddon(orchest.Namespace, app)
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+
+		err = addon.Enable(ctx, preInstallHooks, orchest.Namespace, app)
+
+		// The status is updated even if enabling failed, so rollbacks of failed
+		// upgrades are reported
+		if statusErr := occ.updateApplicationStatus(ctx, orchest, addon, app); statusErr != nil {
+			klog.Error(statusErr)
 		}
 
-		err = occ.addonManager.Get(application.Name).Enable(ctx, preInstallHooks, orchest.Namespace, &application)
 		if err != nil {
 			klog.Error(err)
 			return err
@@ -656,6 +862,11 @@ func (occ *OrchestClusterController) manageOrchestCluster(ctx context.Context, o
 		return
 	}
 
+	err = occ.ensureRegistryGarbageCollection(ctx, fmt.Sprint(orchest.Generation), orchest)
+	if err != nil {
+		return err
+	}
+
 	// If endPhase is Paused or nextPhase is Pausing the cluster should be paused first
 	if endPhase == orchestv1alpha1.Stopped || nextPhase == orchestv1alpha1.Stopping {
 		stopped, err = occ.stopOrchest(ctx, orchest)
@@ -681,6 +892,21 @@ func (occ *OrchestClusterController) manageOrchestCluster(ctx context.Context, o
 		return err
 	}
 
+	err = occ.ensureNetworkPolicies(ctx, generation, orchest)
+	if err != nil {
+		return err
+	}
+
+	err = occ.ensurePodSecurityLabels(ctx, orchest)
+	if err != nil {
+		return err
+	}
+
+	err = occ.ensureIngressCertificate(ctx, generation, orchest)
+	if err != nil {
+		return err
+	}
+
 	// Deploy and Update
 	components, err := GetOrchestComponents(ctx, orchest, occ.oComponentLister)
 	if err != nil {
@@ -688,6 +914,10 @@ func (occ *OrchestClusterController) manageOrchestCluster(ctx context.Context, o
 	}
 
 	for _, componentName := range orderOfDeployment {
+		if componentName == controller.OrchestProxy && !isProxyEnabled(orchest) {
+			continue
+		}
+
 		component, ok := components[componentName]
 		if ok {
 			// If component is not ready, the key will be requeued to be checked later
@@ -709,7 +939,25 @@ func (occ *OrchestClusterController) manageOrchestCluster(ctx context.Context, o
 				return errors.Wrapf(err, "failed to update status while changing the state to DeployingOrchest")
 			}
 
+			trustedCAHash, err := occ.getTrustedCAHash(ctx, orchest)
+			if err != nil {
+				return err
+			}
+
 			newComponent := getOrchestComponent(componentName, generation, componentTemplate, orchest)
+			newComponent.Spec.TrustedCAHash = trustedCAHash
+
+			newComponent.Spec.SecretKeyHash, err = occ.getSecretKeyHash(ctx, orchest, componentName)
+			if err != nil {
+				return err
+			}
+
+			if componentName == controller.AuthServer {
+				newComponent.Spec.AuthenticationHash, err = occ.getAuthenticationHash(ctx, orchest)
+				if err != nil {
+					return err
+				}
+			}
 
 			// Creating Orchest Component
 			_, err = occ.oClient.OrchestV1alpha1().OrchestComponents(orchest.Namespace).
@@ -718,6 +966,11 @@ func (occ *OrchestClusterController) manageOrchestCluster(ctx context.Context, o
 		}
 	}
 
+	err = occ.ensureURL(ctx, orchest)
+	if err != nil {
+		return err
+	}
+
 	stopped = true
 	return err
 }
@@ -761,8 +1014,8 @@ func (occ *OrchestClusterController) stopOrchest(ctx context.Context, orchest *o
 func (occ *OrchestClusterController) ensurePvc(ctx context.Context, curHash, name, size string, orchest *orchestv1alpha1.OrchestCluster) error {
 
 	// Retrive the created pvcs
-	oldPvc, err := occ.Client().CoreV1().PersistentVolumeClaims(orchest.Namespace).Get(ctx, name, metav1.GetOptions{})
 	newPvc := getPersistentVolumeClaim(name, size, curHash, orchest)
+	oldPvc, err := occ.Client().CoreV1().PersistentVolumeClaims(orchest.Namespace).Get(ctx, newPvc.Name, metav1.GetOptions{})
 	// userdir is not created or is removed, we have to recreate it
 	if err != nil && kerrors.IsNotFound(err) {
 		_, err := occ.Client().CoreV1().PersistentVolumeClaims(orchest.Namespace).Create(ctx, newPvc, metav1.CreateOptions{})
@@ -792,11 +1045,13 @@ func (occ *OrchestClusterController) ensureRbacs(ctx context.Context, hash strin
 
 	objects := make([]client.Object, 0, 6)
 	apiMetadata := controller.GetMetadata(controller.OrchestApi, hash, orchest, OrchestClusterKind)
+	apiMetadata.Name = controller.GetResourceName(orchest.Name, controller.OrchestApi)
 	// Get the rbac manifest
-	objects = append(objects, controller.GetRbacManifest(apiMetadata)...)
+	objects = append(objects, controller.GetRbacManifest(apiMetadata, orchestApiPolicyRules)...)
 
 	celeryMetadata := controller.GetMetadata(controller.CeleryWorker, hash, orchest, OrchestClusterKind)
-	objects = append(objects, controller.GetRbacManifest(celeryMetadata)...)
+	celeryMetadata.Name = controller.GetResourceName(orchest.Name, controller.CeleryWorker)
+	objects = append(objects, controller.GetRbacManifest(celeryMetadata, orchestApiPolicyRules)...)
 
 	for _, obj := range objects {
 		err := controller.UpsertObject(ctx, occ.gClient, obj)
@@ -805,6 +1060,13 @@ func (occ *OrchestClusterController) ensureRbacs(ctx context.Context, hash strin
 		}
 	}
 
+	for _, name := range []string{controller.OrchestApi, controller.CeleryWorker} {
+		err := occ.deleteLegacyClusterRbac(ctx, name, orchest)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -874,6 +1136,65 @@ func (occ *OrchestClusterController) updateCondition(ctx context.Context,
 	return occ.updateClusterCondition(ctx, orchest, event)
 }
 
+// updateApplicationStatus updates the status of the application in the OrchestCluster, if the
+// addon reports one
+func (occ *OrchestClusterController) updateApplicationStatus(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, addon addons.Addon, app *orchestv1alpha1.ApplicationSpec) error {
+
+	reporter, ok := addon.(addons.StatusReporter)
+	if !ok {
+		return nil
+	}
+
+	appStatus, err := reporter.Status(ctx, orchest.Namespace, app)
+	if err != nil || appStatus == nil {
+		return err
+	}
+	name := app.Name
+	appStatus.Name = name
+
+	orchest, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).Get(ctx, orchest.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get OrchestCluster")
+	}
+
+	if orchest.Status == nil {
+		return nil
+	}
+
+	applications := make([]orchestv1alpha1.ApplicationStatus, 0, len(orchest.Status.Applications)+1)
+	found := false
+	for _, application := range orchest.Status.Applications {
+		if application.Name == name {
+			if application == *appStatus {
+				// The status is not changed
+				return nil
+			}
+			application = *appStatus
+			found = true
+		}
+		applications = append(applications, application)
+	}
+
+	if !found {
+		applications = append(applications, *appStatus)
+	}
+
+	if appStatus.RolledBackTo != 0 {
+		klog.Warningf("Application %s of OrchestCluster %s is rolled back to revision %d",
+			name, orchest.Name, appStatus.RolledBackTo)
+	}
+
+	orchest.Status.Applications = applications
+
+	_, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).UpdateStatus(ctx, orchest, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the status of application %s", name)
+	}
+
+	return nil
+}
+
 // UpdateClusterCondition function will export each condition into the cluster custom resource
 func (occ *OrchestClusterController) updateClusterCondition(ctx context.Context,
 	orchest *orchestv1alpha1.OrchestCluster,
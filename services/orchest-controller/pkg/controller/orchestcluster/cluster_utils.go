@@ -1,10 +1,9 @@
 package orchestcluster
 
 import (
-	"bytes"
-	"net"
+	"fmt"
+	"os"
 	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/orchest/orchest/services/orchest-controller/pkg/addons"
@@ -19,6 +18,8 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
@@ -32,24 +33,65 @@ var (
 		controller.AuthServer,
 		controller.OrchestWebserver,
 		controller.NodeAgent,
+		controller.OrchestProxy,
 	}
 
+	// The segments of the base path, the paths of the components are appended to it
+	basePathRegex = regexp.MustCompile(`^(/[A-Za-z0-9._-]+)+/?$`)
+
 	legacyDefaultDomain = "index.docker.io"
 	defaultDomain       = "docker.io"
 	// Registry helm parameters
-	registryServiceIP = "service.clusterIP"
+	registryServiceIP      = "service.clusterIP"
+	registryServiceIPs     = "service.clusterIPs"
+	registryIPFamilyPolicy = "service.ipFamilyPolicy"
+	registryFullname       = "fullnameOverride"
+	registryTLSSecretName  = "tlsSecretName"
 )
 
+// getRegistryName returns the name of the in-cluster registry of the OrchestCluster, which
+// names the service, the deployment and the volume of the registry
+func getRegistryName(orchest *orchestv1alpha1.OrchestCluster) string {
+	return controller.GetResourceName(orchest.Name, addons.DockerRegistry)
+}
+
+// getRegistryReleaseName returns the name of the helm release of the in-cluster registry
+func getRegistryReleaseName(orchest *orchestv1alpha1.OrchestCluster) string {
+	return addons.GetReleaseName(orchest.Namespace, getRegistryName(orchest))
+}
+
+// withRegistryNames returns a copy of the registry application, which deploys the registry
+// of the OrchestCluster under the names of the cluster
+func withRegistryNames(orchest *orchestv1alpha1.OrchestCluster,
+	app *orchestv1alpha1.ApplicationSpec) *orchestv1alpha1.ApplicationSpec {
+
+	app = app.DeepCopy()
+	if app.Config.Helm == nil {
+		app.Config.Helm = &orchestv1alpha1.ApplicationConfigHelm{}
+	}
+
+	app.Config.Helm.ReleaseName = getRegistryReleaseName(orchest)
+	setHelmParameter(app.Config.Helm, registryFullname, getRegistryName(orchest))
+	setHelmParameter(app.Config.Helm, registryTLSSecretName,
+		controller.GetResourceName(orchest.Name, registryTLSSecret))
+
+	return app
+}
+
 // This function is borrowed from projectcountour
+// registryCertgen generates the registry certificates, existing certificates are only
+// replaced if renew is true.
 func registryCertgen(ctx context.Context,
 	client kubernetes.Interface,
-	serviceIP string,
-	orchest *orchestv1alpha1.OrchestCluster) error {
+	serviceIPs []string,
+	orchest *orchestv1alpha1.OrchestCluster,
+	renew bool) error {
 	generatedCerts, err := certs.GenerateCerts(
 		&certs.Configuration{
-			IP:        serviceIP,
-			Lifetime:  365,
-			Namespace: orchest.Namespace,
+			IPs:                 serviceIPs,
+			Lifetime:            365,
+			Namespace:           orchest.Namespace,
+			RegistryServiceName: getRegistryName(orchest),
 		})
 	if err != nil {
 		klog.Error("failed to generate certificates")
@@ -58,7 +100,8 @@ func registryCertgen(ctx context.Context,
 
 	owner := *metav1.NewControllerRef(orchest, OrchestClusterKind)
 
-	if err := utils.OutputCerts(ctx, orchest.Namespace, owner, client, generatedCerts); err != nil {
+	secretName := controller.GetResourceName(orchest.Name, registryTLSSecret)
+	if err := utils.OutputCerts(ctx, orchest.Namespace, secretName, owner, client, generatedCerts, renew); err != nil {
 		klog.Errorf("failed output certificates, error: %v", err)
 		return err
 	}
@@ -70,6 +113,7 @@ func getPersistentVolumeClaim(name, volumeSize, hash string,
 	orchest *orchestv1alpha1.OrchestCluster) *corev1.PersistentVolumeClaim {
 
 	metadata := controller.GetMetadata(name, hash, orchest, OrchestClusterKind)
+	metadata.Name = controller.GetResourceName(orchest.Name, name)
 
 	accessMode := corev1.ReadWriteMany
 	if orchest.Spec.SingleNode != nil && *orchest.Spec.SingleNode {
@@ -195,10 +239,15 @@ func GetOrchestComponents(ctx context.Context,
 		return nil, errors.Wrapf(err, "failed to get components of OrchestCluser=%s", orchest.Name)
 	}
 
-	// now we iterate over the all the resources
+	// The components are mapped by their reconciler name, components created by previous
+	// versions of the controller are named after their reconciler
 	componentMap := make(map[string]*orchestv1alpha1.OrchestComponent, len(components))
 	for _, component := range components {
-		componentMap[component.Name] = component
+		name := component.Spec.ReconcilerName
+		if name == "" {
+			name = component.Name
+		}
+		componentMap[name] = component
 	}
 
 	return componentMap, nil
@@ -222,7 +271,12 @@ func GetComponentTemplate(name string, orchest *orchestv1alpha1.OrchestCluster)
 	case controller.OrchestWebserver:
 		componentTemplate = orchest.Spec.Orchest.OrchestWebServer.DeepCopy()
 	case controller.NodeAgent:
-		componentTemplate = orchest.Spec.Orchest.NodeAgent.DeepCopy()
+		componentTemplate = orchest.Spec.Orchest.NodeAgent.OrchestComponentTemplate.DeepCopy()
+	case controller.OrchestProxy:
+		if orchest.Spec.Orchest.Exposure == nil {
+			return nil, errors.Errorf("the exposure of OrchestCluster %s is not specified", orchest.Name)
+		}
+		componentTemplate = orchest.Spec.Orchest.Exposure.Proxy.DeepCopy()
 	default:
 		return nil, errors.Errorf("unrecognized component name %s", name)
 	}
@@ -235,229 +289,433 @@ func getOrchestComponent(name, hash string,
 	orchest *orchestv1alpha1.OrchestCluster) *orchestv1alpha1.OrchestComponent {
 
 	metadata := controller.GetMetadata(name, hash, orchest, OrchestClusterKind)
+	metadata.Name = controller.GetResourceName(orchest.Name, name)
+
+	var gpu *orchestv1alpha1.GPUStatus
+	if orchest.Status != nil {
+		gpu = orchest.Status.GPU
+	}
 
-	env := utils.MergeEnvVars(orchest.Spec.Orchest.Env, template.Env)
+	env := utils.MergeEnvVars(orchest.Spec.Orchest.Env, template.Env,
+		getGPUEnvVars(name, gpu), getProxyEnvVars(orchest), getUserDirEnvVars(name, orchest),
+		getBasePathEnvVars(name, orchest))
 	template.Env = env
 
-	return &orchestv1alpha1.OrchestComponent{
+	component := &orchestv1alpha1.OrchestComponent{
 		ObjectMeta: metadata,
 		Spec: orchestv1alpha1.OrchestComponentSpec{
-			OrchestHost: orchest.Spec.Orchest.OrchestHost,
-			Template:    *template,
+			OrchestHost:        orchest.Spec.Orchest.OrchestHost,
+			OrchestHostAliases: orchest.Spec.Orchest.OrchestHostAliases,
+			BasePath:           getBasePath(orchest),
+			Template:           *template,
+			ReconcilerName:     name,
+			ImageRegistry:      orchest.Spec.Orchest.ImageRegistry,
+			TrustedCA:          orchest.Spec.Orchest.TrustedCA,
+			TLS:                getIngressTLS(orchest),
+			Ingress:            orchest.Spec.Orchest.Ingress,
+			Exposure:           orchest.Spec.Orchest.Exposure,
+			SecretKey:          getSecretKeySelector(orchest, name),
 		},
 	}
 
-}
+	if name == controller.AuthServer {
+		component.Spec.Authentication = orchest.Spec.Orchest.Authentication.DeepCopy()
+	}
 
-// getRegistryServiceIP retrives the defined registry service IP from config
-func getRegistryServiceIP(config *orchestv1alpha1.ApplicationConfig) (string, error) {
-	for _, param := range config.Helm.Parameters {
-		if param.Name == registryServiceIP {
-			return param.Value, nil
-		}
+	if name == controller.NodeAgent {
+		component.Spec.ImagePuller = orchest.Spec.Orchest.NodeAgent.ImagePuller.DeepCopy()
 	}
 
-	return "", errors.Errorf("registry service IP not found in config")
+	return component
+
 }
 
-// setRegistryServiceIP defines the registry service IP if not defined
-func setRegistryServiceIP(ctx context.Context, client kubernetes.Interface,
-	namespace string, app *orchestv1alpha1.ApplicationSpec) (bool, error) {
+// validateIngress validates the ingress configuration of the OrchestCluster
+func validateIngress(ingress *orchestv1alpha1.IngressSpec) error {
 
-	var changed = false
+	switch ingress.Provider {
+	case "", orchestv1alpha1.NginxIngressProvider, orchestv1alpha1.TraefikIngressProvider:
+	case orchestv1alpha1.GatewayIngressProvider:
+		if ingress.Gateway == nil || len(ingress.Gateway.ParentRefs) == 0 {
+			return errors.New("the parentRefs of the gateway have to be specified")
+		}
+		// The Gateway API has no standard filter authenticating the requests
+		if filter := ingress.Gateway.AuthFilter; filter == nil || filter.Kind == "" || filter.Name == "" {
+			return errors.New("the kind and name of the auth filter of the gateway have to be specified")
+		}
+	default:
+		return errors.Errorf("unrecognized ingress provider %s", ingress.Provider)
+	}
 
-	if app.Config.Helm == nil {
-		changed = true
-		app.Config.Helm = &orchestv1alpha1.ApplicationConfigHelm{}
+	if ingress.InstallController && ingress.Provider != "" &&
+		ingress.Provider != orchestv1alpha1.NginxIngressProvider {
+		return errors.Errorf("installing the controller is not supported by the %s provider", ingress.Provider)
 	}
 
-	if app.Config.Helm.Parameters == nil {
-		app.Config.Helm.Parameters = []orchestv1alpha1.HelmParameter{}
+	return nil
+}
+
+// getApplications returns the applications of the OrchestCluster, including ingress-nginx if
+// its installation is requested by the ingress and it is not listed already
+func getApplications(orchest *orchestv1alpha1.OrchestCluster) []orchestv1alpha1.ApplicationSpec {
+
+	applications := orchest.Spec.Applications
+
+	ingress := orchest.Spec.Orchest.Ingress
+	if ingress == nil || !ingress.InstallController {
+		return applications
 	}
 
-	// We first check if there is an IP assigned by the controller for the service in Config
-	// then we check the actual IP of the service if exists, and if they are different the actual
-	// IP takes precedence and the configured IP will be changed to the actual one, (this is to
-	// fix the issue of the instances updated to v2022.07.6 because, controller in that version
-	// assigned the service IP, regardless of the IP of the present service.) If there was
-	// no service, we just find a free IP and set it in the configs
-
-	// We check the current controller assigned service IP first
-	serviceIpIndex := -1
-	for index, param := range app.Config.Helm.Parameters {
-		if param.Name == registryServiceIP {
-			serviceIpIndex = index
-			break
+	for _, app := range applications {
+		if app.Name == addons.IngressNginx {
+			return applications
 		}
 	}
 
-	//Now we get the ClusterIP of the service if present
-	var currentIP string
-	registryService, err := client.CoreV1().Services(namespace).Get(ctx, addons.DockerRegistry, metav1.GetOptions{})
-	if err != nil {
-		if !kerrors.IsNotFound(err) {
-			return changed, err
-		}
-	} else {
-		currentIP = registryService.Spec.ClusterIP
-	}
-
-	// If the service is present
-	if currentIP != "" {
-		if serviceIpIndex >= 0 {
-			changed = app.Config.Helm.Parameters[serviceIpIndex].Value != currentIP || changed
-			app.Config.Helm.Parameters[serviceIpIndex].Value = currentIP
-			return changed, nil
-		} else {
-			changed = true
-			app.Config.Helm.Parameters = append(app.Config.Helm.Parameters,
-				orchestv1alpha1.HelmParameter{
-					Name:  registryServiceIP,
-					Value: currentIP,
-				})
-			return changed, nil
-		}
+	// ingress-nginx is installed first, as the ingresses of Orchest depend on it
+	return append([]orchestv1alpha1.ApplicationSpec{{Name: addons.IngressNginx}}, applications...)
+}
+
+// validateImagePuller validates the image puller configuration of the node-agent
+func validateImagePuller(imagePuller *orchestv1alpha1.ImagePullerSpec) error {
+
+	if imagePuller.Interval != nil && *imagePuller.Interval <= 0 {
+		return errors.Errorf("interval must be positive, got %d", *imagePuller.Interval)
 	}
 
-	if serviceIpIndex >= 0 {
-		// If we are here, it means the the ip is already configured
-		return changed, nil
+	if imagePuller.Retries != nil && *imagePuller.Retries <= 0 {
+		return errors.Errorf("retries must be positive, got %d", *imagePuller.Retries)
 	}
 
-	// the service ip is not defined, let's find a free IP
-	serviceList, err := client.CoreV1().Services("").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return changed, err
+	switch imagePuller.Policy {
+	case "", corev1.PullIfNotPresent, corev1.PullAlways:
+	default:
+		return errors.Errorf("unsupported policy %s", imagePuller.Policy)
 	}
 
-	// Sort the ip addresses
-	sort.Slice(serviceList.Items, func(i, j int) bool {
+	switch imagePuller.LogLevel {
+	case "", "DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL":
+	default:
+		return errors.Errorf("unsupported log level %s", imagePuller.LogLevel)
+	}
 
-		ip1 := net.ParseIP(serviceList.Items[i].Spec.ClusterIP).To4()
-		if ip1 == nil {
-			return false
+	for _, image := range imagePuller.Images {
+		if strings.TrimSpace(image) == "" {
+			return errors.New("image names must not be empty")
 		}
+	}
 
-		ip2 := net.ParseIP(serviceList.Items[j].Spec.ClusterIP).To4()
-		if ip2 == nil {
-			return false
-		}
+	return nil
+}
 
-		return bytes.Compare(ip1, ip2) < 0
+// getUserDirEnvVars returns the env vars of the component naming the user directory volume of
+// the cluster, it takes precedence over the value of previous versions of the controller
+// stored in the OrchestCluster.
+func getUserDirEnvVars(name string, orchest *orchestv1alpha1.OrchestCluster) []corev1.EnvVar {
+	if name != controller.OrchestWebserver {
+		return nil
+	}
 
-	})
+	return []corev1.EnvVar{
+		{Name: "USERDIR_PVC", Value: controller.GetResourceName(orchest.Name, controller.UserDirName)},
+	}
+}
 
-	// Find the first free IP
-	var serviceIP string
-	for i := 0; i < len(serviceList.Items)-1; i++ {
+// getOrchestHosts returns the OrchestHost followed by its aliases, it is empty if Orchest is
+// not exposed at a host
+func getOrchestHosts(orchest *orchestv1alpha1.OrchestCluster) []string {
+	host := orchest.Spec.Orchest.OrchestHost
+	if host == nil || *host == "" {
+		return nil
+	}
 
-		if serviceList.Items[i].Spec.ClusterIP == "" {
-			continue
+	hosts := []string{*host}
+	for _, alias := range orchest.Spec.Orchest.OrchestHostAliases {
+		if !utils.Contains(hosts, alias) {
+			hosts = append(hosts, alias)
 		}
+	}
 
-		nextIP := nextIP(net.ParseIP(serviceList.Items[i].Spec.ClusterIP).To4(), 1)
-		if nextIP == nil {
-			continue
-		}
+	return hosts
+}
 
-		// If next ip is not free
-		if nextIP.Equal(net.ParseIP(serviceList.Items[i+1].Spec.ClusterIP)) {
-			continue
-		}
+// validateOrchestHostAliases validates the aliases of the OrchestHost, which are DNS names
+func validateOrchestHostAliases(orchest *orchestv1alpha1.OrchestCluster) error {
+	aliases := orchest.Spec.Orchest.OrchestHostAliases
+	if len(aliases) == 0 {
+		return nil
+	}
 
-		serviceIP = nextIP.To4().String()
-		break
+	if host := orchest.Spec.Orchest.OrchestHost; host == nil || *host == "" {
+		return errors.New("orchestHost has to be specified with its aliases")
 	}
 
-	if serviceIP == "" {
-		lastIP := net.ParseIP(serviceList.Items[len(serviceList.Items)-1].
-			Spec.ClusterIP)
-		if lastIP != nil {
-			return changed, errors.Errorf("no free IP found in the cluster %s", lastIP.String())
+	for _, alias := range aliases {
+		if errs := validation.IsDNS1123Subdomain(alias); len(errs) > 0 {
+			return errors.Errorf("invalid host alias %s: %s", alias, strings.Join(errs, ", "))
 		}
-		lastIP = lastIP.To4()
+	}
+
+	return nil
+}
 
-		serviceIP = nextIP(lastIP, 1).To4().String()
+// getBasePathEnvVars returns the env vars of the components which are aware of the base path,
+// either because they serve requests routed with their full path or because they create the
+// ingresses of the sessions under it.
+func getBasePathEnvVars(name string, orchest *orchestv1alpha1.OrchestCluster) []corev1.EnvVar {
+	basePath := getBasePath(orchest)
+	if basePath == "" {
+		return nil
 	}
 
-	changed = true
-	app.Config.Helm.Parameters = append(app.Config.Helm.Parameters,
-		orchestv1alpha1.HelmParameter{
-			Name:  registryServiceIP,
-			Value: serviceIP,
-		})
+	switch name {
+	case controller.OrchestWebserver, controller.AuthServer, controller.OrchestApi,
+		controller.CeleryWorker:
+	default:
+		return nil
+	}
 
-	return changed, nil
+	return []corev1.EnvVar{
+		{Name: "ORCHEST_BASE_PATH", Value: basePath},
+	}
 }
 
-// nextIP returns the next ip address
-func nextIP(ip net.IP, inc uint) net.IP {
-	v := uint(ip[0])<<24 + uint(ip[1])<<16 + uint(ip[2])<<8 + uint(ip[3])
-	v += inc
-	v3 := byte(v & 0xFF)
-	v2 := byte((v >> 8) & 0xFF)
-	v1 := byte((v >> 16) & 0xFF)
-	v0 := byte((v >> 24) & 0xFF)
-	return net.IPv4(v0, v1, v2, v3)
+// getBasePath returns the base path of the OrchestCluster without the trailing slash, it is
+// empty if Orchest is served at the root
+func getBasePath(orchest *orchestv1alpha1.OrchestCluster) string {
+	return strings.TrimRight(orchest.Spec.Orchest.BasePath, "/")
 }
 
-// detectContainerRuntime detects the container runtime of the cluster and the socket path
-// returns error if the container runtime is not supported or the cluster is not homogeneous
-// If the socket path is not set or not detected, it will use the default socket path based on
-// the container runtime
-func detectContainerRuntime(ctx context.Context,
-	client kubernetes.Interface, orchest *orchestv1alpha1.OrchestCluster) (string, string, error) {
+// validateBasePath validates the base path of the OrchestCluster, which is used as is in the
+// paths of the ingresses and the configuration of the built-in proxy
+func validateBasePath(basePath string) error {
+	if basePath == "" || basePath == "/" {
+		return nil
+	}
 
-	// Get the node list
-	nodeList, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return "", "", errors.Wrapf(err, "failed to get node list")
+	if !basePathRegex.MatchString(basePath) {
+		return errors.Errorf("invalid base path %s, it has to be an absolute path of "+
+			"alphanumerics, '-', '_', '.' and '/'", basePath)
+	}
+
+	return nil
+}
+
+// getProxyEnvVars returns the proxy env vars of the components, in both the upper and lower
+// case forms as clients differ in which one they read. The in-cluster services are always
+// excluded from the proxy.
+func getProxyEnvVars(orchest *orchestv1alpha1.OrchestCluster) []corev1.EnvVar {
+	httpProxy := orchest.Spec.Orchest.HTTPProxy
+	httpsProxy := orchest.Spec.Orchest.HTTPSProxy
+	if httpProxy == "" && httpsProxy == "" {
+		return nil
+	}
+
+	noProxy := []string{
+		"localhost",
+		"127.0.0.1",
+		".svc",
+		".cluster.local",
+	}
+
+	for _, service := range []string{
+		controller.OrchestApi,
+		controller.OrchestWebserver,
+		controller.AuthServer,
+		controller.OrchestDatabase,
+		controller.Rabbitmq,
+		addons.DockerRegistry,
+	} {
+		noProxy = append(noProxy, controller.GetResourceName(orchest.Name, service))
+	}
+
+	// The pods reach the API server by the IP of the kubernetes service
+	if apiServerHost := os.Getenv("KUBERNETES_SERVICE_HOST"); apiServerHost != "" {
+		noProxy = append(noProxy, apiServerHost)
 	}
 
-	var runtime = ""
-	for _, node := range nodeList.Items {
-		// Get the node container runtime
-		runtimeVersion := node.Status.NodeInfo.ContainerRuntimeVersion
-		if runtimeVersion == "" {
-			return "", "", errors.Errorf("failed to get container runtime version")
+	for _, app := range orchest.Spec.Applications {
+		if app.Name == addons.DockerRegistry {
+			serviceIPs, _ := getRegistryServiceIPs(&app.Config)
+			noProxy = append(noProxy, serviceIPs...)
 		}
+	}
 
-		// Get the container runtime name
-		runtimeName := strings.Split(runtimeVersion, ":")[0]
-		if runtimeName != "docker" && runtimeName != "containerd" {
-			return "", "", errors.Errorf("unsupported container runtime %s", runtimeName)
+	for _, host := range strings.Split(orchest.Spec.Orchest.NoProxy, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			noProxy = append(noProxy, host)
 		}
+	}
 
-		if runtime == "" {
-			runtime = runtimeName
+	envVars := make([]corev1.EnvVar, 0, 6)
+	for _, envVar := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: httpProxy},
+		{Name: "HTTPS_PROXY", Value: httpsProxy},
+		{Name: "NO_PROXY", Value: strings.Join(noProxy, ",")},
+	} {
+		if envVar.Value == "" {
 			continue
 		}
+		envVars = append(envVars, envVar,
+			corev1.EnvVar{Name: strings.ToLower(envVar.Name), Value: envVar.Value})
+	}
+
+	return envVars
+}
 
-		if runtime != runtimeName {
-			return "", "", errors.Errorf("cluster is not homogeneous")
+// getRegistryServiceIPs retrives the defined registry service IPs from config, one per IP
+// family of the service, the first one is the primary IP.
+func getRegistryServiceIPs(config *orchestv1alpha1.ApplicationConfig) ([]string, error) {
+	if config.Helm == nil {
+		return nil, errors.Errorf("registry service IP not found in config")
+	}
+
+	var serviceIP string
+	for _, param := range config.Helm.Parameters {
+		switch param.Name {
+		case registryServiceIPs:
+			ips := strings.Split(strings.Trim(param.Value, "{}"), ",")
+			if len(ips) != 0 && ips[0] != "" {
+				return ips, nil
+			}
+		case registryServiceIP:
+			serviceIP = param.Value
 		}
 	}
 
-	// Get the container runtime socket path
-	runtimeSocket, ok := orchest.Annotations[controller.ContainerRuntimeSocketPathAnnotationKey]
-	if !ok {
-		// The socket path is not provided in the annotation, so we need to detect it
-		if runtime == "containerd" {
-			// Get the first node
-			node := nodeList.Items[0]
-			runtimeSocket, ok = node.Annotations[controller.KubeAdmCRISocketAnnotationKey]
-			if !ok {
-				// kubeadm CRi socket is not provided in the annotation, so we use the default socket path
-				runtimeSocket = "/var/run/containerd/containerd.sock"
+	if serviceIP != "" {
+		return []string{serviceIP}, nil
+	}
 
-			}
-		} else {
-			// The socket path is not provided in the annotation of OrchestCluster, so we use the default socket path
-			runtimeSocket = "/var/run/docker.sock"
+	return nil, errors.Errorf("registry service IP not found in config")
+}
+
+// setRegistryServiceIP defines the registry service IPs if not defined.
+//
+// The registry certificates are generated before the registry is deployed and carry the IPs
+// of the registry service, since the nodes pull images by IP. To know the IPs upfront, the
+// controller creates the service and lets the API server allocate an IP of every IP family
+// of the cluster, the service is then adopted by the helm release of the registry.
+func setRegistryServiceIP(ctx context.Context, client kubernetes.Interface,
+	orchest *orchestv1alpha1.OrchestCluster, app *orchestv1alpha1.ApplicationSpec) (bool, error) {
+
+	var changed = false
+
+	if app.Config.Helm == nil {
+		changed = true
+		app.Config.Helm = &orchestv1alpha1.ApplicationConfigHelm{}
+	}
+
+	if app.Config.Helm.Parameters == nil {
+		app.Config.Helm.Parameters = []orchestv1alpha1.HelmParameter{}
+	}
+
+	// The IPs of an existing service take precedence over the configured ones, (this is to
+	// fix the issue of the instances updated to v2022.07.6 because, controller in that version
+	// assigned the service IP, regardless of the IP of the present service.)
+	registryService, err := client.CoreV1().Services(orchest.Namespace).Get(ctx, getRegistryName(orchest), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		configuredIPs, _ := getRegistryServiceIPs(&app.Config)
+		registryService, err = createRegistryService(ctx, client, orchest, configuredIPs)
+	}
+	if err != nil {
+		return changed, err
+	}
+
+	serviceIPs := registryService.Spec.ClusterIPs
+	if len(serviceIPs) == 0 && registryService.Spec.ClusterIP != "" {
+		serviceIPs = []string{registryService.Spec.ClusterIP}
+	}
+
+	if len(serviceIPs) == 0 || serviceIPs[0] == corev1.ClusterIPNone {
+		return changed, errors.Errorf("service %s has no cluster IP", registryService.Name)
+	}
+
+	changed = setHelmParameter(app.Config.Helm, registryServiceIP, serviceIPs[0]) || changed
+	changed = setHelmParameter(app.Config.Helm, registryServiceIPs,
+		fmt.Sprintf("{%s}", strings.Join(serviceIPs, ","))) || changed
+
+	if registryService.Spec.IPFamilyPolicy != nil {
+		changed = setHelmParameter(app.Config.Helm, registryIPFamilyPolicy,
+			string(*registryService.Spec.IPFamilyPolicy)) || changed
+	}
+
+	return changed, nil
+}
+
+// createRegistryService creates the registry service with the given IPs, or with IPs allocated
+// by the API server if the IPs are not given or not available.
+func createRegistryService(ctx context.Context, client kubernetes.Interface,
+	orchest *orchestv1alpha1.OrchestCluster, serviceIPs []string) (*corev1.Service, error) {
+
+	namespace := orchest.Namespace
+	name := getRegistryName(orchest)
+	releaseName := getRegistryReleaseName(orchest)
+	ipFamilyPolicy := corev1.IPFamilyPolicyPreferDualStack
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":                          addons.DockerRegistry,
+				"release":                      releaseName,
+				"app.kubernetes.io/managed-by": "Helm",
+			},
+			// Allows the helm release of the registry to adopt the service
+			Annotations: map[string]string{
+				"meta.helm.sh/release-name":      releaseName,
+				"meta.helm.sh/release-namespace": namespace,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:           corev1.ServiceTypeClusterIP,
+			ClusterIPs:     serviceIPs,
+			IPFamilyPolicy: &ipFamilyPolicy,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "https-443",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       443,
+					TargetPort: intstr.FromInt(5000),
+				},
+			},
+			Selector: map[string]string{
+				"app":     addons.DockerRegistry,
+				"release": releaseName,
+			},
+		},
+	}
+
+	created, err := client.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+	if kerrors.IsInvalid(err) && len(serviceIPs) != 0 {
+		// The IPs are already allocated or out of the service CIDR of the cluster
+		klog.Warningf("Registry service IPs %v are not available, allocating new IPs, error: %v", serviceIPs, err)
+		service.Spec.ClusterIPs = nil
+		created, err = client.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s service", name)
+	}
+
+	return created, nil
+}
+
+// setHelmParameter sets the value of a helm parameter, returns true if it is changed
+func setHelmParameter(config *orchestv1alpha1.ApplicationConfigHelm, name, value string) bool {
+	for i := range config.Parameters {
+		if config.Parameters[i].Name == name {
+			changed := config.Parameters[i].Value != value
+			config.Parameters[i].Value = value
+			return changed
 		}
 	}
 
-	return runtime, runtimeSocket, nil
+	config.Parameters = append(config.Parameters, orchestv1alpha1.HelmParameter{
+		Name:  name,
+		Value: value,
+	})
+	return true
 }
 
 // borrowed from https://github.com/distribution/distribution
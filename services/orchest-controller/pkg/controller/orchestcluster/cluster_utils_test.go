@@ -3,6 +3,10 @@ package orchestcluster
 import (
 	"testing"
 
+	"github.com/orchest/orchest/services/orchest-controller/pkg/addons"
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -92,3 +96,189 @@ func TestParseImageName(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProxyEnvVars(t *testing.T) {
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+
+	inClusterNoProxy := "localhost,127.0.0.1,.svc,.cluster.local,cluster-1-orchest-api," +
+		"cluster-1-orchest-webserver,cluster-1-auth-server,cluster-1-orchest-database," +
+		"cluster-1-rabbitmq-server,cluster-1-docker-registry,10.96.0.1"
+
+	tests := []struct {
+		name    string
+		spec    orchestv1alpha1.OrchestSpec
+		envVars map[string]string
+	}{
+		{
+			name:    "no proxy",
+			spec:    orchestv1alpha1.OrchestSpec{NoProxy: "example.com"},
+			envVars: map[string]string{},
+		},
+		{
+			name: "https proxy",
+			spec: orchestv1alpha1.OrchestSpec{
+				HTTPSProxy: "http://proxy:3128",
+				NoProxy:    "example.com, .internal",
+			},
+			envVars: map[string]string{
+				"HTTPS_PROXY": "http://proxy:3128",
+				"https_proxy": "http://proxy:3128",
+				"NO_PROXY":    inClusterNoProxy + ",example.com,.internal",
+				"no_proxy":    inClusterNoProxy + ",example.com,.internal",
+			},
+		},
+		{
+			name: "http and https proxy",
+			spec: orchestv1alpha1.OrchestSpec{
+				HTTPProxy:  "http://proxy:3128",
+				HTTPSProxy: "http://proxy:3129",
+			},
+			envVars: map[string]string{
+				"HTTP_PROXY":  "http://proxy:3128",
+				"http_proxy":  "http://proxy:3128",
+				"HTTPS_PROXY": "http://proxy:3129",
+				"https_proxy": "http://proxy:3129",
+				"NO_PROXY":    inClusterNoProxy,
+				"no_proxy":    inClusterNoProxy,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orchest := &orchestv1alpha1.OrchestCluster{}
+			orchest.Name = "cluster-1"
+			orchest.Spec.Orchest = test.spec
+			assert.Equal(t, test.envVars, utils.GetMapFromEnvVar(getProxyEnvVars(orchest)))
+		})
+	}
+}
+
+func TestValidateBasePath(t *testing.T) {
+
+	testCases := []struct {
+		basePath      string
+		expectedError bool
+	}{
+		{basePath: ""},
+		{basePath: "/"},
+		{basePath: "/orchest"},
+		{basePath: "/tools/orchest/"},
+		{basePath: "orchest", expectedError: true},
+		{basePath: "/orchest//", expectedError: true},
+		{basePath: "/orchest/{uuid}", expectedError: true},
+		{basePath: "/orchest;return", expectedError: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.basePath, func(t *testing.T) {
+			err := validateBasePath(test.basePath)
+			assert.Equal(t, test.expectedError, err != nil)
+		})
+	}
+}
+
+func TestGetBasePathEnvVars(t *testing.T) {
+
+	orchest := &orchestv1alpha1.OrchestCluster{}
+	orchest.Spec.Orchest.BasePath = "/orchest/"
+
+	assert.Equal(t, map[string]string{"ORCHEST_BASE_PATH": "/orchest"},
+		utils.GetMapFromEnvVar(getBasePathEnvVars(controller.AuthServer, orchest)))
+	assert.Equal(t, map[string]string{"ORCHEST_BASE_PATH": "/orchest"},
+		utils.GetMapFromEnvVar(getBasePathEnvVars(controller.CeleryWorker, orchest)))
+	assert.Empty(t, getBasePathEnvVars(controller.NodeAgent, orchest))
+
+	orchest.Spec.Orchest.BasePath = "/"
+	assert.Empty(t, getBasePathEnvVars(controller.OrchestWebserver, orchest))
+}
+
+func TestValidateOrchestHostAliases(t *testing.T) {
+
+	host := "orchest.example.com"
+
+	testCases := []struct {
+		name          string
+		orchestHost   *string
+		aliases       []string
+		expectedError bool
+	}{
+		{
+			name:        "no aliases",
+			orchestHost: nil,
+		},
+		{
+			name:        "aliases",
+			orchestHost: &host,
+			aliases:     []string{"orchest.example.org", "orchest.internal"},
+		},
+		{
+			name:          "aliases without host",
+			orchestHost:   nil,
+			aliases:       []string{"orchest.example.org"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid alias",
+			orchestHost:   &host,
+			aliases:       []string{"https://orchest.example.org"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			orchest := &orchestv1alpha1.OrchestCluster{}
+			orchest.Spec.Orchest.OrchestHost = test.orchestHost
+			orchest.Spec.Orchest.OrchestHostAliases = test.aliases
+
+			err := validateOrchestHostAliases(orchest)
+			assert.Equal(t, test.expectedError, err != nil)
+		})
+	}
+}
+
+func TestGetApplications(t *testing.T) {
+
+	orchest := &orchestv1alpha1.OrchestCluster{}
+	orchest.Spec.Applications = []orchestv1alpha1.ApplicationSpec{{Name: addons.ArgoWorkflow}}
+
+	// ingress-nginx is opt-in
+	assert.Equal(t, orchest.Spec.Applications, getApplications(orchest))
+
+	orchest.Spec.Orchest.Ingress = &orchestv1alpha1.IngressSpec{InstallController: true}
+	assert.Equal(t, []orchestv1alpha1.ApplicationSpec{
+		{Name: addons.IngressNginx},
+		{Name: addons.ArgoWorkflow},
+	}, getApplications(orchest))
+
+	// It is not added twice if listed in the applications
+	orchest.Spec.Applications = append(orchest.Spec.Applications, orchestv1alpha1.ApplicationSpec{
+		Name: addons.IngressNginx,
+	})
+	assert.Equal(t, orchest.Spec.Applications, getApplications(orchest))
+
+	assert.NoError(t, validateIngress(orchest.Spec.Orchest.Ingress))
+	orchest.Spec.Orchest.Ingress.Provider = orchestv1alpha1.TraefikIngressProvider
+	assert.Error(t, validateIngress(orchest.Spec.Orchest.Ingress))
+}
+
+func TestValidateIngressGateway(t *testing.T) {
+
+	ingress := &orchestv1alpha1.IngressSpec{
+		Provider: orchestv1alpha1.GatewayIngressProvider,
+		Gateway: &orchestv1alpha1.GatewaySpec{
+			ParentRefs: []orchestv1alpha1.GatewayReference{{Name: "gateway"}},
+		},
+	}
+
+	// The requests are not authenticated without the auth filter
+	assert.Error(t, validateIngress(ingress))
+
+	ingress.Gateway.AuthFilter = &orchestv1alpha1.ExtensionReference{Kind: "SecurityPolicy"}
+	assert.Error(t, validateIngress(ingress))
+
+	ingress.Gateway.AuthFilter.Name = "orchest-auth"
+	assert.NoError(t, validateIngress(ingress))
+}
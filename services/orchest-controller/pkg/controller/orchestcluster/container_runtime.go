@@ -0,0 +1,143 @@
+package orchestcluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// detectContainerRuntimes returns the supported container runtimes of the cluster, the runtime
+// of the most nodes first, and labels the nodes with their container runtime so the node-agent
+// of each runtime is scheduled on them. If the socket path is set in the annotation of the
+// OrchestCluster, it is used as the socket path of the first runtime.
+func (occ *OrchestClusterController) detectContainerRuntimes(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) ([]controller.ContainerRuntime, error) {
+
+	nodes, err := occ.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get node list")
+	}
+
+	// Nodes which did not report their container runtime yet are labeled once they do
+	for _, node := range nodes {
+		err = labelNodeContainerRuntime(ctx, occ.Client(), node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	runtimes := controller.DetectContainerRuntimes(nodes)
+	if len(runtimes) == 0 {
+		return nil, errors.Errorf("no node with a supported container runtime found")
+	}
+
+	if runtimeSocket, ok := orchest.Annotations[controller.ContainerRuntimeSocketPathAnnotationKey]; ok {
+		runtimes[0].SocketPath = runtimeSocket
+	}
+
+	return runtimes, nil
+}
+
+// labelNodeContainerRuntime labels the node with its container runtime, if supported
+func labelNodeContainerRuntime(ctx context.Context, client kubernetes.Interface, node *corev1.Node) error {
+
+	if controller.IsNodeContainerRuntimeLabeled(node) {
+		return nil
+	}
+
+	runtime := controller.GetNodeContainerRuntime(node)
+	patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, controller.ContainerRuntimeLabelKey, runtime))
+	_, err := client.CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to label node %s with its container runtime", node.Name)
+	}
+
+	return nil
+}
+
+// addNode syncs the OrchestClusters if the node is not labeled with its container runtime yet,
+// which labels it so the node-agent is scheduled on it right away.
+func (occ *OrchestClusterController) addNode(obj interface{}) {
+	node := obj.(*corev1.Node)
+	if controller.IsNodeContainerRuntimeLabeled(node) {
+		return
+	}
+
+	klog.V(4).Infof("Node %s is not labeled with its container runtime", node.Name)
+	occ.enqueueOrchestClusters()
+}
+
+func (occ *OrchestClusterController) updateNode(old, cur interface{}) {
+	occ.addNode(cur)
+}
+
+// deleteNode syncs the OrchestClusters to update the container runtimes in their status
+func (occ *OrchestClusterController) deleteNode(obj interface{}) {
+	occ.enqueueOrchestClusters()
+}
+
+func (occ *OrchestClusterController) enqueueOrchestClusters() {
+	orchests, err := occ.oClusterLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't list OrchestClusters: %v", err))
+		return
+	}
+
+	for _, orchest := range orchests {
+		occ.Enqueue(orchest)
+	}
+}
+
+// updateContainerRuntimeStatus reports the container runtimes in the status of the
+// OrchestCluster, and warns if the nodes run different container runtimes.
+func (occ *OrchestClusterController) updateContainerRuntimeStatus(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, runtimes []controller.ContainerRuntime) error {
+
+	runtimeStatuses := make([]orchestv1alpha1.ContainerRuntimeStatus, 0, len(runtimes))
+	for _, runtime := range runtimes {
+		runtimeStatuses = append(runtimeStatuses, orchestv1alpha1.ContainerRuntimeStatus{
+			Name:       runtime.Name,
+			SocketPath: runtime.SocketPath,
+			Nodes:      len(runtime.Nodes),
+		})
+	}
+
+	if orchest.Status == nil || reflect.DeepEqual(orchest.Status.ContainerRuntimes, runtimeStatuses) {
+		return nil
+	}
+
+	if len(runtimes) > 1 {
+		klog.Warningf("The nodes of OrchestCluster %s run different container runtimes %v, "+
+			"sessions and pipeline runs use %s, which runs on %d nodes",
+			orchest.Name, runtimeStatuses, runtimes[0].Name, len(runtimes[0].Nodes))
+	}
+
+	orchest, err := occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).Get(ctx, orchest.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get OrchestCluster")
+	}
+
+	if orchest.Status == nil {
+		return nil
+	}
+
+	orchest.Status.ContainerRuntimes = runtimeStatuses
+
+	_, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).UpdateStatus(ctx, orchest, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the container runtimes of OrchestCluster %s", orchest.Name)
+	}
+
+	return nil
+}
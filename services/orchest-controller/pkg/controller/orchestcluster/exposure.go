@@ -0,0 +1,246 @@
+package orchestcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// isProxyEnabled returns true if Orchest is exposed by the built-in proxy
+func isProxyEnabled(orchest *orchestv1alpha1.OrchestCluster) bool {
+	return getExposureMode(orchest) != orchestv1alpha1.IngressExposureMode
+}
+
+func getExposureMode(orchest *orchestv1alpha1.OrchestCluster) orchestv1alpha1.ExposureMode {
+	if exposure := orchest.Spec.Orchest.Exposure; exposure != nil && exposure.Mode != "" {
+		return exposure.Mode
+	}
+	return orchestv1alpha1.IngressExposureMode
+}
+
+// validateExposure validates the exposure configuration of the OrchestCluster, TLS is only
+// terminated by the ingresses
+func validateExposure(exposure *orchestv1alpha1.ExposureSpec, tls *orchestv1alpha1.TLSSpec) error {
+
+	switch exposure.Mode {
+	case "", orchestv1alpha1.IngressExposureMode:
+		return nil
+	case orchestv1alpha1.NodePortExposureMode, orchestv1alpha1.LoadBalancerExposureMode,
+		orchestv1alpha1.ProxyExposureMode:
+	default:
+		return errors.Errorf("unrecognized exposure mode %s", exposure.Mode)
+	}
+
+	if exposure.NodePort != 0 && exposure.Mode != orchestv1alpha1.NodePortExposureMode {
+		return errors.Errorf("the node port is not supported in the %s exposure mode", exposure.Mode)
+	}
+
+	if exposure.NodePort < 0 || exposure.NodePort > 65535 {
+		return errors.Errorf("invalid node port %d", exposure.NodePort)
+	}
+
+	if tls != nil {
+		return errors.Errorf("tls is not supported in the %s exposure mode", exposure.Mode)
+	}
+
+	return nil
+}
+
+// ensureURL updates the URLs of the OrchestCluster in the status, they are derived from the
+// OrchestHost and its aliases or the address the webserver is exposed at in the exposure mode
+func (occ *OrchestClusterController) ensureURL(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	host, port, err := occ.getExposedAddress(ctx, orchest)
+	if err != nil {
+		return err
+	}
+
+	urls := getOrchestURLs(orchest, host, port)
+	if reflect.DeepEqual(orchest.Status.URLs, urls) {
+		return nil
+	}
+
+	return occ.updateURLStatus(ctx, orchest, urls)
+}
+
+// getExposedAddress returns the host and, if not the default one, the port the webserver is
+// exposed at. The OrchestHost takes precedence over the addresses of the nodes and the load
+// balancers, the host is empty if none is known yet.
+func (occ *OrchestClusterController) getExposedAddress(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (string, int32, error) {
+
+	var host string
+	if orchest.Spec.Orchest.OrchestHost != nil {
+		host = *orchest.Spec.Orchest.OrchestHost
+	}
+
+	mode := getExposureMode(orchest)
+
+	if mode == orchestv1alpha1.IngressExposureMode {
+		if host != "" {
+			return host, 0, nil
+		}
+
+		name := controller.GetResourceName(orchest.Name, controller.OrchestWebserver)
+		ing, err := occ.Client().NetworkingV1().Ingresses(orchest.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			// The webserver is exposed by an HTTPRoute
+			return "", 0, nil
+		} else if err != nil {
+			return "", 0, errors.Wrapf(err, "failed to get Ingress %s", name)
+		}
+
+		return getLoadBalancerAddress(ing.Status.LoadBalancer), 0, nil
+	}
+
+	name := controller.GetResourceName(orchest.Name, controller.OrchestProxy)
+	svc, err := occ.Client().CoreV1().Services(orchest.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "failed to get Service %s", name)
+	}
+
+	switch mode {
+	case orchestv1alpha1.NodePortExposureMode:
+		if host == "" {
+			host, err = occ.getNodeAddress()
+			if err != nil {
+				return "", 0, err
+			}
+		}
+		if host == "" || len(svc.Spec.Ports) == 0 {
+			return "", 0, nil
+		}
+		return host, svc.Spec.Ports[0].NodePort, nil
+	case orchestv1alpha1.LoadBalancerExposureMode:
+		if host != "" {
+			return host, 0, nil
+		}
+		return getLoadBalancerAddress(svc.Status.LoadBalancer), 0, nil
+	default:
+		return fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace), 0, nil
+	}
+}
+
+// getNodeAddress returns the external IP of a node, or the internal one if no node has an
+// external IP. The nodes are looked at by name, so the same address is returned every time.
+func (occ *OrchestClusterController) getNodeAddress() (string, error) {
+
+	nodes, err := occ.nodeLister.List(labels.Everything())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list nodes")
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	var internalIP string
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			switch address.Type {
+			case corev1.NodeExternalIP:
+				return address.Address, nil
+			case corev1.NodeInternalIP:
+				if internalIP == "" {
+					internalIP = address.Address
+				}
+			}
+		}
+	}
+
+	return internalIP, nil
+}
+
+func (occ *OrchestClusterController) updateURLStatus(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, urls []string) error {
+
+	orchest, err := occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).Get(ctx, orchest.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get OrchestCluster")
+	}
+
+	if orchest.Status == nil || reflect.DeepEqual(orchest.Status.URLs, urls) {
+		return nil
+	}
+
+	orchest.Status.URLs = urls
+	orchest.Status.URL = ""
+	if len(urls) > 0 {
+		orchest.Status.URL = urls[0]
+	}
+
+	_, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).UpdateStatus(ctx, orchest, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the URL of OrchestCluster %s", orchest.Name)
+	}
+
+	return nil
+}
+
+// getOrchestURLs returns the URLs of the webserver at the host and port, and at the aliases
+// of the host if it is the OrchestHost
+func getOrchestURLs(orchest *orchestv1alpha1.OrchestCluster, host string, port int32) []string {
+	if host == "" {
+		return nil
+	}
+
+	hosts := []string{host}
+	if orchestHost := orchest.Spec.Orchest.OrchestHost; orchestHost != nil && *orchestHost == host {
+		hosts = getOrchestHosts(orchest)
+	}
+
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, getOrchestURL(orchest, host, port))
+	}
+
+	return urls
+}
+
+// getOrchestURL returns the URL of the webserver at the host, the port and the base path, it
+// is https only if the ingresses terminate TLS
+func getOrchestURL(orchest *orchestv1alpha1.OrchestCluster, host string, port int32) string {
+
+	scheme := "http"
+	if orchest.Spec.Orchest.TLS != nil && getExposureMode(orchest) == orchestv1alpha1.IngressExposureMode {
+		scheme = "https"
+	}
+
+	address := utils.GetHostFromIP(host)
+	if port != 0 {
+		address = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+
+	url := fmt.Sprintf("%s://%s", scheme, address)
+	if basePath := getBasePath(orchest); basePath != "" {
+		url += basePath + "/"
+	}
+
+	return url
+}
+
+// getLoadBalancerAddress returns the IP or the hostname of the first load balancer ingress
+func getLoadBalancerAddress(status corev1.LoadBalancerStatus) string {
+	for _, ingress := range status.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+		if ingress.Hostname != "" {
+			return ingress.Hostname
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package orchestcluster
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExposure(t *testing.T) {
+
+	testCases := []struct {
+		name          string
+		exposure      *orchestv1alpha1.ExposureSpec
+		tls           *orchestv1alpha1.TLSSpec
+		expectedError bool
+	}{
+		{
+			name:     "ingress with tls",
+			exposure: &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.IngressExposureMode},
+			tls:      &orchestv1alpha1.TLSSpec{SelfSigned: true},
+		},
+		{
+			name:     "node port",
+			exposure: &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.NodePortExposureMode, NodePort: 30080},
+		},
+		{
+			name:     "proxy",
+			exposure: &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.ProxyExposureMode},
+		},
+		{
+			name:          "unrecognized mode",
+			exposure:      &orchestv1alpha1.ExposureSpec{Mode: "hostPort"},
+			expectedError: true,
+		},
+		{
+			name:          "node port of load balancer",
+			exposure:      &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.LoadBalancerExposureMode, NodePort: 30080},
+			expectedError: true,
+		},
+		{
+			name:          "load balancer with tls",
+			exposure:      &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.LoadBalancerExposureMode},
+			tls:           &orchestv1alpha1.TLSSpec{SelfSigned: true},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateExposure(test.exposure, test.tls)
+			assert.Equal(t, test.expectedError, err != nil)
+		})
+	}
+}
+
+func TestGetOrchestURLs(t *testing.T) {
+
+	orchestHost := "orchest.example.com"
+
+	testCases := []struct {
+		name         string
+		exposure     *orchestv1alpha1.ExposureSpec
+		tls          *orchestv1alpha1.TLSSpec
+		basePath     string
+		aliases      []string
+		host         string
+		port         int32
+		expectedURLs []string
+	}{
+		{
+			name:         "ingress",
+			host:         orchestHost,
+			expectedURLs: []string{"http://orchest.example.com"},
+		},
+		{
+			name:         "ingress with tls",
+			tls:          &orchestv1alpha1.TLSSpec{SecretName: "orchest-tls"},
+			host:         orchestHost,
+			expectedURLs: []string{"https://orchest.example.com"},
+		},
+		{
+			name:         "aliases",
+			aliases:      []string{"orchest.example.org", "orchest.example.com"},
+			host:         orchestHost,
+			expectedURLs: []string{"http://orchest.example.com", "http://orchest.example.org"},
+		},
+		{
+			name:         "base path",
+			tls:          &orchestv1alpha1.TLSSpec{SecretName: "orchest-tls"},
+			basePath:     "/orchest",
+			host:         orchestHost,
+			expectedURLs: []string{"https://orchest.example.com/orchest/"},
+		},
+		{
+			name:         "node port",
+			exposure:     &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.NodePortExposureMode},
+			aliases:      []string{"orchest.example.org"},
+			host:         "10.0.0.1",
+			port:         30080,
+			expectedURLs: []string{"http://10.0.0.1:30080"},
+		},
+		{
+			name:         "ipv6 load balancer",
+			exposure:     &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.LoadBalancerExposureMode},
+			host:         "2001:db8::1",
+			expectedURLs: []string{"http://[2001:db8::1]"},
+		},
+		{
+			name:         "load balancer not provisioned",
+			exposure:     &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.LoadBalancerExposureMode},
+			expectedURLs: nil,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			orchest := &orchestv1alpha1.OrchestCluster{}
+			orchest.Spec.Orchest.OrchestHost = &orchestHost
+			orchest.Spec.Orchest.OrchestHostAliases = test.aliases
+			orchest.Spec.Orchest.Exposure = test.exposure
+			orchest.Spec.Orchest.TLS = test.tls
+			orchest.Spec.Orchest.BasePath = test.basePath
+
+			assert.Equal(t, test.expectedURLs, getOrchestURLs(orchest, test.host, test.port))
+		})
+	}
+}
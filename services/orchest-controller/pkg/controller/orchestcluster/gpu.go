@@ -0,0 +1,153 @@
+package orchestcluster
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+var (
+	gpuEnabledEnvVar = "ORCHEST_GPU_ENABLED_INSTANCE"
+
+	// The components which offer GPU support if the cluster has GPU nodes
+	gpuComponents = []string{
+		controller.OrchestApi,
+		controller.CeleryWorker,
+		controller.OrchestWebserver,
+	}
+)
+
+// getGPUResourceName returns the extended resource advertised by the GPU nodes
+func getGPUResourceName(orchest *orchestv1alpha1.OrchestCluster) corev1.ResourceName {
+	if gpu := orchest.Spec.Orchest.GPU; gpu != nil && gpu.ResourceName != "" {
+		return corev1.ResourceName(gpu.ResourceName)
+	}
+	return corev1.ResourceName(controller.DefaultGPUResource)
+}
+
+// detectGPUs returns the GPU nodes, which either advertise allocatable GPUs or are labeled as
+// GPU nodes sorted by name, or nil if there are none.
+func detectGPUs(nodes []*corev1.Node, resourceName corev1.ResourceName) *orchestv1alpha1.GPUStatus {
+
+	var gpuNodes []orchestv1alpha1.GPUNodeStatus
+	for _, node := range nodes {
+		var gpus int64
+		if quantity, ok := node.Status.Allocatable[resourceName]; ok {
+			gpus = quantity.Value()
+		}
+
+		if gpus > 0 || node.Labels[controller.GPULabelKey] == "true" {
+			gpuNodes = append(gpuNodes, orchestv1alpha1.GPUNodeStatus{
+				Name: node.Name,
+				GPUs: gpus,
+			})
+		}
+	}
+
+	if len(gpuNodes) == 0 {
+		return nil
+	}
+
+	// The nodes of the lister are not ordered, which would otherwise change the status
+	sort.Slice(gpuNodes, func(i, j int) bool {
+		return gpuNodes[i].Name < gpuNodes[j].Name
+	})
+
+	return &orchestv1alpha1.GPUStatus{
+		ResourceName: string(resourceName),
+		Nodes:        gpuNodes,
+	}
+}
+
+// getGPUEnvVars returns the env vars of the component telling whether GPUs are available
+func getGPUEnvVars(name string, gpu *orchestv1alpha1.GPUStatus) []corev1.EnvVar {
+	if !utils.Contains(gpuComponents, name) {
+		return nil
+	}
+
+	// The components parse the flag as a python boolean
+	enabled := "False"
+	if gpu != nil && len(gpu.Nodes) > 0 {
+		enabled = "True"
+	}
+
+	return []corev1.EnvVar{
+		{Name: gpuEnabledEnvVar, Value: enabled},
+	}
+}
+
+// ensureGPUs detects the GPU nodes of the cluster, reports them in the status of the
+// OrchestCluster and updates the components of a running cluster if GPUs became available
+// or unavailable.
+func (occ *OrchestClusterController) ensureGPUs(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	if orchest.Status == nil {
+		return nil
+	}
+
+	nodes, err := occ.nodeLister.List(labels.Everything())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get node list")
+	}
+
+	gpu := detectGPUs(nodes, getGPUResourceName(orchest))
+	if !reflect.DeepEqual(orchest.Status.GPU, gpu) {
+		klog.Infof("GPU nodes of OrchestCluster %s changed to %v", orchest.Name, gpu)
+
+		orchest, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).Get(ctx, orchest.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to get OrchestCluster")
+		}
+
+		if orchest.Status == nil {
+			return nil
+		}
+
+		orchest.Status.GPU = gpu
+		orchest, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).UpdateStatus(ctx, orchest, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update the GPU nodes of OrchestCluster %s", orchest.Name)
+		}
+	}
+
+	// Components are recreated with the current env vars while the cluster is not running
+	if orchest.Status.Phase != orchestv1alpha1.Running {
+		return nil
+	}
+
+	components, err := GetOrchestComponents(ctx, orchest, occ.oComponentLister)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range gpuComponents {
+		component, ok := components[name]
+		if !ok {
+			continue
+		}
+
+		envVars := getGPUEnvVars(name, gpu)
+		if utils.GetKeyFromEnvVar(component.Spec.Template.Env, gpuEnabledEnvVar) == envVars[0].Value {
+			continue
+		}
+
+		component = component.DeepCopy()
+		component.Spec.Template.Env = utils.MergeEnvVars(component.Spec.Template.Env, envVars)
+		_, err = occ.oClient.OrchestV1alpha1().OrchestComponents(orchest.Namespace).Update(ctx, component, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update %s", component.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package orchestcluster
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDetectGPUs(t *testing.T) {
+
+	getNode := func(name string, labels map[string]string, allocatable corev1.ResourceList) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: labels,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: allocatable,
+			},
+		}
+	}
+
+	cpuNode := getNode("cpu", nil, corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("4"),
+	})
+	nvidiaNode := getNode("nvidia", nil, corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("4"),
+		"nvidia.com/gpu":   resource.MustParse("2"),
+	})
+	drainedNode := getNode("drained", nil, corev1.ResourceList{
+		"nvidia.com/gpu": resource.MustParse("0"),
+	})
+	amdNode := getNode("amd", nil, corev1.ResourceList{
+		"amd.com/gpu": resource.MustParse("1"),
+	})
+	labeledNode := getNode("labeled", map[string]string{controller.GPULabelKey: "true"}, nil)
+
+	testCases := []struct {
+		name         string
+		nodes        []*corev1.Node
+		resourceName corev1.ResourceName
+		expected     *orchestv1alpha1.GPUStatus
+	}{
+		{
+			name:         "no gpu nodes",
+			nodes:        []*corev1.Node{cpuNode, drainedNode, amdNode},
+			resourceName: "nvidia.com/gpu",
+			expected:     nil,
+		},
+		{
+			name:         "allocatable and labeled gpu nodes",
+			nodes:        []*corev1.Node{cpuNode, nvidiaNode, labeledNode},
+			resourceName: "nvidia.com/gpu",
+			expected: &orchestv1alpha1.GPUStatus{
+				ResourceName: "nvidia.com/gpu",
+				Nodes: []orchestv1alpha1.GPUNodeStatus{
+					{Name: "labeled", GPUs: 0},
+					{Name: "nvidia", GPUs: 2},
+				},
+			},
+		},
+		{
+			name:         "overridden resource name",
+			nodes:        []*corev1.Node{nvidiaNode, amdNode},
+			resourceName: "amd.com/gpu",
+			expected: &orchestv1alpha1.GPUStatus{
+				ResourceName: "amd.com/gpu",
+				Nodes: []orchestv1alpha1.GPUNodeStatus{
+					{Name: "amd", GPUs: 1},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, detectGPUs(test.nodes, test.resourceName))
+		})
+	}
+}
+
+func TestGetGPUEnvVars(t *testing.T) {
+
+	gpu := &orchestv1alpha1.GPUStatus{
+		Nodes: []orchestv1alpha1.GPUNodeStatus{{Name: "nvidia", GPUs: 1}},
+	}
+
+	assert.Equal(t, []corev1.EnvVar{{Name: gpuEnabledEnvVar, Value: "True"}},
+		getGPUEnvVars(controller.OrchestApi, gpu))
+	assert.Equal(t, []corev1.EnvVar{{Name: gpuEnabledEnvVar, Value: "False"}},
+		getGPUEnvVars(controller.OrchestWebserver, nil))
+	assert.Nil(t, getGPUEnvVars(controller.AuthServer, gpu))
+}
@@ -0,0 +1,350 @@
+package orchestcluster
+
+import (
+	"context"
+	"time"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/certs"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// The name of the Secret of the ingress certificate if managed by the controller, and of
+	// the cert-manager Certificate issuing it
+	ingressTLSSecret = "ingress-tls"
+
+	// The self-signed ingress certificate is renewed if it expires within this period
+	ingressCertRenewBefore = 30 * 24 * time.Hour
+
+	// The interval the ingress certificates are checked at
+	ingressCertCheckPeriod = time.Hour
+
+	certManagerGroup   = "cert-manager.io"
+	certificateGVK     = schema.GroupVersionKind{Group: certManagerGroup, Version: "v1", Kind: "Certificate"}
+	issuerKind         = "Issuer"
+	clusterIssuerKind  = "ClusterIssuer"
+	selfSignedLifetime = uint(365)
+)
+
+// checkIngressCertificates renews the self-signed ingress certificates of all OrchestClusters
+// if needed, and reports the expiry of the ingress certificates.
+func (occ *OrchestClusterController) checkIngressCertificates() {
+
+	ctx, cancel := context.WithTimeout(context.Background(), ingressCertCheckPeriod)
+	defer cancel()
+
+	orchests, err := occ.oClusterLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list OrchestClusters, error: %v", err)
+		return
+	}
+
+	for _, orchest := range orchests {
+		if orchest.Spec.Orchest.TLS == nil || orchest.Status == nil ||
+			orchest.Status.Phase != orchestv1alpha1.Running {
+			continue
+		}
+
+		err = occ.ensureIngressCertificate(ctx, orchest.Status.ObservedHash, orchest)
+		if err != nil {
+			klog.Errorf("failed to check the ingress certificate of OrchestCluster %s, error: %v",
+				orchest.Name, err)
+		}
+	}
+}
+
+// ensureIngressCertificate makes sure the certificate of the Orchest ingresses is issued, i.e.
+// the self-signed certificate is generated, or the cert-manager Certificate is created, and
+// reports the expiry of the certificate in the status of the OrchestCluster.
+func (occ *OrchestClusterController) ensureIngressCertificate(ctx context.Context,
+	hash string, orchest *orchestv1alpha1.OrchestCluster) error {
+
+	tls := orchest.Spec.Orchest.TLS
+
+	if tls == nil || tls.IssuerRef == nil {
+		err := occ.deleteIngressCertificate(ctx, orchest)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tls == nil {
+		return nil
+	}
+
+	var renewedAt *metav1.Time
+	if tls.IssuerRef != nil {
+		err := occ.upsertIngressCertificate(ctx, getIngressCertificate(hash, orchest))
+		if err != nil {
+			return err
+		}
+	} else if tls.SelfSigned {
+		renew, err := occ.isSelfSignedCertificateRenewalNeeded(ctx, orchest)
+		if err != nil {
+			return err
+		}
+
+		if renew {
+			klog.Infof("Generating the self-signed ingress certificate of OrchestCluster %s", orchest.Name)
+
+			generatedCerts, err := certs.GenerateCerts(&certs.Configuration{
+				Lifetime: selfSignedLifetime,
+				DNSNames: getOrchestHosts(orchest),
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to generate the self-signed ingress certificate")
+			}
+
+			owner := *metav1.NewControllerRef(orchest, OrchestClusterKind)
+			err = utils.OutputCerts(ctx, orchest.Namespace, getIngressTLSSecretName(orchest), owner,
+				occ.Client(), generatedCerts, true)
+			if err != nil {
+				return err
+			}
+
+			now := metav1.Now()
+			renewedAt = &now
+		}
+	}
+
+	secret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx,
+		getIngressTLSSecretName(orchest), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		// cert-manager has not issued the certificate yet
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get the ingress certificate of OrchestCluster %s", orchest.Name)
+	}
+
+	expiry, err := certs.GetCertificateExpiry(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		// The ingress controller reports invalid certificates, so the expiry is not reported
+		klog.Warningf("failed to parse the certificate of %s, error: %v", secret.Name, err)
+		return nil
+	}
+
+	return occ.updateIngressTLSStatus(ctx, orchest, expiry, renewedAt)
+}
+
+// isSelfSignedCertificateRenewalNeeded returns true if the self-signed certificate does not
+// exist yet, expires soon or is not issued for the OrchestHost and its aliases
+func (occ *OrchestClusterController) isSelfSignedCertificateRenewalNeeded(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (bool, error) {
+
+	secretName := getIngressTLSSecretName(orchest)
+	secret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to get secret %s", secretName)
+	}
+
+	expiry, err := certs.GetCertificateExpiry(secret.Data[utils.CACertificateKey], secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		klog.Warningf("failed to parse the certificates of %s, renewing, error: %v", secretName, err)
+		return true, nil
+	}
+
+	dnsNames, err := certs.GetCertificateDNSNames(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return true, nil
+	}
+
+	if time.Until(expiry) < ingressCertRenewBefore {
+		return true, nil
+	}
+
+	for _, host := range getOrchestHosts(orchest) {
+		if !utils.Contains(dnsNames, host) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// upsertIngressCertificate creates or updates the cert-manager Certificate, it is not upserted
+// with controller.UpsertObject since custom resources don't support strategic merge patches
+func (occ *OrchestClusterController) upsertIngressCertificate(ctx context.Context,
+	certificate *unstructured.Unstructured) error {
+
+	err := occ.gClient.Create(ctx, certificate)
+	if err == nil {
+		return nil
+	} else if !kerrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create Certificate %s", certificate.GetName())
+	}
+
+	oldCertificate := &unstructured.Unstructured{}
+	oldCertificate.SetGroupVersionKind(certificateGVK)
+	err = occ.gClient.Get(ctx, client.ObjectKeyFromObject(certificate), oldCertificate)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get Certificate %s", certificate.GetName())
+	}
+
+	oldCertificate.SetLabels(certificate.GetLabels())
+	oldCertificate.Object["spec"] = certificate.Object["spec"]
+	err = occ.gClient.Update(ctx, oldCertificate)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Certificate %s", certificate.GetName())
+	}
+
+	return nil
+}
+
+// deleteIngressCertificate deletes the cert-manager Certificate of the OrchestCluster, if any
+func (occ *OrchestClusterController) deleteIngressCertificate(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(controller.GetResourceName(orchest.Name, ingressTLSSecret))
+	certificate.SetNamespace(orchest.Namespace)
+
+	err := occ.gClient.Delete(ctx, certificate)
+	// cert-manager may not be installed
+	if err != nil && !kerrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return errors.Wrapf(err, "failed to delete Certificate %s", certificate.GetName())
+	}
+
+	return nil
+}
+
+func (occ *OrchestClusterController) updateIngressTLSStatus(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, expiry time.Time, renewedAt *metav1.Time) error {
+
+	orchest, err := occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).Get(ctx, orchest.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get OrchestCluster")
+	}
+
+	if orchest.Status == nil {
+		return nil
+	}
+
+	tlsStatus := orchest.Status.TLS.DeepCopy()
+	if tlsStatus == nil {
+		tlsStatus = &orchestv1alpha1.TLSStatus{}
+	}
+
+	expiryTime := metav1.NewTime(expiry)
+	if tlsStatus.CertificateExpiry != nil && tlsStatus.CertificateExpiry.Equal(&expiryTime) &&
+		renewedAt == nil {
+		// The status is not changed
+		return nil
+	}
+
+	tlsStatus.CertificateExpiry = &expiryTime
+	if renewedAt != nil {
+		tlsStatus.LastCertificateRenewal = renewedAt
+	}
+
+	orchest.Status.TLS = tlsStatus
+
+	_, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).UpdateStatus(ctx, orchest, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the TLS status of OrchestCluster %s", orchest.Name)
+	}
+
+	return nil
+}
+
+// getIngressCertificate returns the cert-manager Certificate of the OrchestHost and its aliases
+func getIngressCertificate(hash string, orchest *orchestv1alpha1.OrchestCluster) *unstructured.Unstructured {
+
+	issuerRef := orchest.Spec.Orchest.TLS.IssuerRef
+	kind := issuerRef.Kind
+	if kind == "" {
+		kind = issuerKind
+	}
+
+	metadata := controller.GetMetadata(ingressTLSSecret, hash, orchest, OrchestClusterKind)
+
+	hosts := getOrchestHosts(orchest)
+	dnsNames := make([]interface{}, 0, len(hosts))
+	for _, host := range hosts {
+		dnsNames = append(dnsNames, host)
+	}
+
+	certificate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"secretName": getIngressTLSSecretName(orchest),
+				"dnsNames":   dnsNames,
+				"issuerRef": map[string]interface{}{
+					"name":  issuerRef.Name,
+					"kind":  kind,
+					"group": certManagerGroup,
+				},
+			},
+		},
+	}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(controller.GetResourceName(orchest.Name, ingressTLSSecret))
+	certificate.SetNamespace(orchest.Namespace)
+	certificate.SetLabels(metadata.Labels)
+	certificate.SetOwnerReferences(metadata.OwnerReferences)
+
+	return certificate
+}
+
+// getIngressTLS returns the TLS of the ingresses of the components, with the name of the
+// Secret of the certificate resolved
+func getIngressTLS(orchest *orchestv1alpha1.OrchestCluster) *orchestv1alpha1.TLSSpec {
+	if orchest.Spec.Orchest.TLS == nil {
+		return nil
+	}
+
+	tls := orchest.Spec.Orchest.TLS.DeepCopy()
+	tls.SecretName = getIngressTLSSecretName(orchest)
+	return tls
+}
+
+// getIngressTLSSecretName returns the name of the Secret of the ingress certificate
+func getIngressTLSSecretName(orchest *orchestv1alpha1.OrchestCluster) string {
+	if tls := orchest.Spec.Orchest.TLS; tls != nil && tls.SecretName != "" {
+		return tls.SecretName
+	}
+	return controller.GetResourceName(orchest.Name, ingressTLSSecret)
+}
+
+// validateTLS validates the TLS configuration of the Orchest ingresses
+func validateTLS(tls *orchestv1alpha1.TLSSpec, orchestHost *string) error {
+
+	if tls.IssuerRef != nil && tls.SelfSigned {
+		return errors.New("only one of issuerRef and selfSigned can be specified")
+	}
+
+	if tls.IssuerRef == nil && !tls.SelfSigned && tls.SecretName == "" {
+		return errors.New("one of secretName, issuerRef and selfSigned has to be specified")
+	}
+
+	if (tls.IssuerRef != nil || tls.SelfSigned) && (orchestHost == nil || *orchestHost == "") {
+		return errors.New("orchestHost has to be specified to issue a certificate")
+	}
+
+	if tls.IssuerRef != nil {
+		if tls.IssuerRef.Name == "" {
+			return errors.New("the name of the issuer is not specified")
+		}
+		switch tls.IssuerRef.Kind {
+		case "", issuerKind, clusterIssuerKind:
+		default:
+			return errors.Errorf("unrecognized issuer kind %s", tls.IssuerRef.Kind)
+		}
+	}
+
+	return nil
+}
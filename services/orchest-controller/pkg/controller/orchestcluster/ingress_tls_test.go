@@ -0,0 +1,107 @@
+package orchestcluster
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateTLS(t *testing.T) {
+
+	host := "orchest.example.com"
+
+	testCases := []struct {
+		name          string
+		tls           *orchestv1alpha1.TLSSpec
+		orchestHost   *string
+		expectedError bool
+	}{
+		{
+			name:        "existing secret",
+			tls:         &orchestv1alpha1.TLSSpec{SecretName: "orchest-tls"},
+			orchestHost: nil,
+		},
+		{
+			name:        "issuer",
+			tls:         &orchestv1alpha1.TLSSpec{IssuerRef: &orchestv1alpha1.IssuerReference{Name: "letsencrypt", Kind: "ClusterIssuer"}},
+			orchestHost: &host,
+		},
+		{
+			name:        "self-signed",
+			tls:         &orchestv1alpha1.TLSSpec{SelfSigned: true},
+			orchestHost: &host,
+		},
+		{
+			name:          "no mode",
+			tls:           &orchestv1alpha1.TLSSpec{},
+			orchestHost:   &host,
+			expectedError: true,
+		},
+		{
+			name: "issuer and self-signed",
+			tls: &orchestv1alpha1.TLSSpec{
+				IssuerRef:  &orchestv1alpha1.IssuerReference{Name: "letsencrypt"},
+				SelfSigned: true,
+			},
+			orchestHost:   &host,
+			expectedError: true,
+		},
+		{
+			name:          "self-signed without host",
+			tls:           &orchestv1alpha1.TLSSpec{SelfSigned: true},
+			orchestHost:   nil,
+			expectedError: true,
+		},
+		{
+			name:          "unrecognized issuer kind",
+			tls:           &orchestv1alpha1.TLSSpec{IssuerRef: &orchestv1alpha1.IssuerReference{Name: "letsencrypt", Kind: "Vault"}},
+			orchestHost:   &host,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateTLS(test.tls, test.orchestHost)
+			assert.Equal(t, test.expectedError, err != nil)
+		})
+	}
+}
+
+func TestGetIngressCertificate(t *testing.T) {
+
+	host := "orchest.example.com"
+	orchest := &orchestv1alpha1.OrchestCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster-1",
+			Namespace: "orchest",
+		},
+	}
+	orchest.Spec.Orchest.OrchestHost = &host
+	orchest.Spec.Orchest.OrchestHostAliases = []string{"orchest.example.org"}
+	orchest.Spec.Orchest.TLS = &orchestv1alpha1.TLSSpec{
+		IssuerRef: &orchestv1alpha1.IssuerReference{Name: "letsencrypt"},
+	}
+
+	certificate := getIngressCertificate("1", orchest)
+
+	assert.Equal(t, "cluster-1-ingress-tls", certificate.GetName())
+	assert.Equal(t, "Certificate", certificate.GetKind())
+	assert.Equal(t, map[string]interface{}{
+		"secretName": "cluster-1-ingress-tls",
+		"dnsNames":   []interface{}{host, "orchest.example.org"},
+		"issuerRef": map[string]interface{}{
+			"name":  "letsencrypt",
+			"kind":  "Issuer",
+			"group": "cert-manager.io",
+		},
+	}, certificate.Object["spec"])
+
+	// The secret of the components is the one of the certificate
+	assert.Equal(t, "cluster-1-ingress-tls", getIngressTLS(orchest).SecretName)
+
+	orchest.Spec.Orchest.TLS = &orchestv1alpha1.TLSSpec{SecretName: "orchest-tls"}
+	assert.Equal(t, "orchest-tls", getIngressTLS(orchest).SecretName)
+}
@@ -0,0 +1,371 @@
+package orchestcluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/orchest/orchest/services/orchest-controller/pkg/addons"
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/helm"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// Set on the OrchestCluster once the objects created by previous versions of the controller
+	// are migrated to the names prefixed with the name of the cluster.
+	ResourceNamesMigratedAnnotationKey = "controller.orchest.io/resource-names-migrated"
+
+	// The reclaim policy of a volume before it is retained to be moved to the migrated claim.
+	reclaimPolicyAnnotationKey = "controller.orchest.io/reclaim-policy"
+
+	// Helm adopts the objects labeled as managed by helm, and annotated with the release.
+	helmManagedByLabel             = "app.kubernetes.io/managed-by"
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// migrateLegacyObjects migrates the objects created by previous versions of the controller, which
+// were not prefixed with the name of the OrchestCluster. The legacy components are stopped, the
+// volumes are moved to the new claims, and the remaining legacy objects are removed. The new
+// objects are created once the cluster starts again. Returns true once the migration is done.
+func (occ *OrchestClusterController) migrateLegacyObjects(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (bool, error) {
+
+	if _, ok := orchest.GetAnnotations()[ResourceNamesMigratedAnnotationKey]; ok {
+		return true, nil
+	}
+
+	done, err := occ.stopLegacyComponents(ctx, orchest)
+	if err != nil || !done {
+		return false, err
+	}
+
+	// The registry of the cluster was deployed by a previous version of the controller if the
+	// legacy certificate of the registry belongs to the cluster
+	legacyRegistry, err := occ.isControlledSecret(ctx, orchest, registryTLSSecret)
+	if err != nil {
+		return false, err
+	}
+
+	legacyClaims := []string{controller.UserDirName, controller.BuilderDirName}
+	if legacyRegistry {
+		legacyClaims = append(legacyClaims, addons.DockerRegistry)
+	}
+
+	for _, name := range legacyClaims {
+		err = occ.retainLegacyClaim(ctx, orchest, name)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if legacyRegistry {
+		releaseName := addons.GetReleaseName(orchest.Namespace, addons.DockerRegistry)
+		if _, err := helm.GetReleaseConfig(ctx, releaseName, orchest.Namespace); err == nil {
+			klog.Infof("Removing the legacy registry release %s of OrchestCluster %s", releaseName, orchest.Name)
+			err = helm.RemoveRelease(ctx, releaseName, orchest.Namespace)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	done = true
+	for _, name := range legacyClaims {
+		deleted, err := occ.deleteLegacyObject(ctx, orchest, name, &corev1.PersistentVolumeClaim{})
+		if err != nil {
+			return false, err
+		}
+		done = done && deleted
+	}
+
+	// The volumes are released once the legacy claims are gone
+	for _, name := range []string{controller.UserDirName, controller.BuilderDirName, addons.DockerRegistry} {
+		bound, err := occ.bindMigratedClaim(ctx, orchest, getMigratedClaimName(orchest, name))
+		if err != nil {
+			return false, err
+		}
+		done = done && bound
+	}
+
+	if !done {
+		occ.EnqueueAfter(orchest)
+		return false, nil
+	}
+
+	err = occ.deleteLegacyRegistryObjects(ctx, orchest, legacyRegistry)
+	if err != nil {
+		return false, err
+	}
+
+	return controller.AnnotateObject(ctx, occ.gClient, orchest, ResourceNamesMigratedAnnotationKey, "true")
+}
+
+// stopLegacyComponents stops the cluster if it runs components of a previous version of the
+// controller, the cluster is left in Stopped phase so it is started again with the new components.
+func (occ *OrchestClusterController) stopLegacyComponents(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (bool, error) {
+
+	components, err := GetOrchestComponents(ctx, orchest, occ.oComponentLister)
+	if err != nil {
+		return false, err
+	}
+
+	legacy := false
+	for _, component := range components {
+		legacy = legacy || component.Spec.ReconcilerName == ""
+	}
+
+	if !legacy {
+		return true, nil
+	}
+
+	klog.Infof("Stopping the legacy components of OrchestCluster %s", orchest.Name)
+
+	stopped, err := occ.stopOrchest(ctx, orchest)
+	if err != nil || !stopped {
+		return false, err
+	}
+
+	return true, occ.updatePhase(ctx, orchest.Namespace, orchest.Name, orchestv1alpha1.Stopped, "")
+}
+
+// retainLegacyClaim retains the volume of a legacy claim, and creates the migrated claim which
+// binds the volume once the legacy claim is removed.
+func (occ *OrchestClusterController) retainLegacyClaim(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, name string) error {
+
+	legacyPvc, err := occ.Client().CoreV1().PersistentVolumeClaims(orchest.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get pvc %s", name)
+	}
+
+	// The claims of the registry are owned by helm
+	if name != addons.DockerRegistry && !metav1.IsControlledBy(legacyPvc, orchest) {
+		return nil
+	}
+
+	if legacyPvc.Spec.VolumeName != "" {
+		pv, err := occ.Client().CoreV1().PersistentVolumes().Get(ctx, legacyPvc.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the volume of pvc %s", name)
+		}
+
+		if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+			pv = pv.DeepCopy()
+			if pv.Annotations == nil {
+				pv.Annotations = map[string]string{}
+			}
+			pv.Annotations[reclaimPolicyAnnotationKey] = string(pv.Spec.PersistentVolumeReclaimPolicy)
+			pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+			_, err = occ.Client().CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{})
+			if err != nil {
+				return errors.Wrapf(err, "failed to retain the volume of pvc %s", name)
+			}
+		}
+	}
+
+	newPvc := getMigratedClaim(orchest, legacyPvc)
+	_, err = occ.Client().CoreV1().PersistentVolumeClaims(orchest.Namespace).Create(ctx, newPvc, metav1.CreateOptions{})
+	if err != nil && !kerrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create pvc %s", newPvc.Name)
+	}
+
+	return nil
+}
+
+// bindMigratedClaim releases the volume of a migrated claim from the legacy claim, and restores
+// the reclaim policy of the volume once it is bound to the migrated claim.
+func (occ *OrchestClusterController) bindMigratedClaim(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, name string) (bool, error) {
+
+	pvc, err := occ.Client().CoreV1().PersistentVolumeClaims(orchest.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to get pvc %s", name)
+	}
+
+	if pvc.Spec.VolumeName == "" {
+		return true, nil
+	}
+
+	pv, err := occ.Client().CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get the volume of pvc %s", name)
+	}
+
+	pv = pv.DeepCopy()
+	if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.UID != pvc.UID {
+		// The volume is still reserved for the legacy claim
+		if pv.Status.Phase != corev1.VolumeReleased {
+			return false, nil
+		}
+		pv.Spec.ClaimRef = nil
+		_, err = occ.Client().CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to release the volume of pvc %s", name)
+		}
+		return false, nil
+	}
+
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, nil
+	}
+
+	policy, ok := pv.Annotations[reclaimPolicyAnnotationKey]
+	if !ok {
+		return true, nil
+	}
+
+	pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimPolicy(policy)
+	delete(pv.Annotations, reclaimPolicyAnnotationKey)
+	_, err = occ.Client().CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to restore the volume of pvc %s", name)
+	}
+
+	return true, nil
+}
+
+// deleteLegacyRegistryObjects deletes the legacy objects of the cluster which are not owned by
+// the components.
+func (occ *OrchestClusterController) deleteLegacyRegistryObjects(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, legacyRegistry bool) error {
+
+	for _, name := range []string{controller.OrchestApi, controller.CeleryWorker} {
+		for _, object := range controller.GetRbacManifest(metav1.ObjectMeta{Name: name}, nil) {
+			_, err := occ.deleteLegacyObject(ctx, orchest, name, object)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := occ.deleteLegacyObject(ctx, orchest, registryGC, &batchv1.CronJob{})
+	if err != nil || !legacyRegistry {
+		return err
+	}
+
+	serviceAccount, err := occ.Client().CoreV1().ServiceAccounts(orchest.Namespace).Get(ctx, "default", metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the default service account")
+	}
+
+	pullSecrets := make([]corev1.LocalObjectReference, 0, len(serviceAccount.ImagePullSecrets))
+	for _, secret := range serviceAccount.ImagePullSecrets {
+		if secret.Name != registryCredentialsSecret {
+			pullSecrets = append(pullSecrets, secret)
+		}
+	}
+
+	if len(pullSecrets) != len(serviceAccount.ImagePullSecrets) {
+		serviceAccount = serviceAccount.DeepCopy()
+		serviceAccount.ImagePullSecrets = pullSecrets
+		_, err = occ.Client().CoreV1().ServiceAccounts(orchest.Namespace).Update(ctx, serviceAccount, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to update the default service account")
+		}
+	}
+
+	for _, name := range []string{registryCredentialsSecret, registryTLSSecret} {
+		_, err = occ.deleteLegacyObject(ctx, orchest, name, &corev1.Secret{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isControlledSecret returns true if the secret exists and is controlled by the OrchestCluster
+func (occ *OrchestClusterController) isControlledSecret(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, name string) (bool, error) {
+
+	secret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to get secret %s", name)
+	}
+
+	return metav1.IsControlledBy(secret, orchest), nil
+}
+
+// deleteLegacyObject deletes the object if it is controlled by the OrchestCluster or by helm,
+// returns true once the object is gone.
+func (occ *OrchestClusterController) deleteLegacyObject(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, name string, object client.Object) (bool, error) {
+
+	err := occ.gClient.Get(ctx, client.ObjectKey{Namespace: orchest.Namespace, Name: name}, object)
+	if kerrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to get legacy object %s", name)
+	}
+
+	// The legacy claim of the registry is created by helm
+	if !metav1.IsControlledBy(object, orchest) && object.GetLabels()["heritage"] != "Helm" {
+		return true, nil
+	}
+
+	if object.GetDeletionTimestamp().IsZero() {
+		klog.Infof("Removing the legacy object %s of OrchestCluster %s", name, orchest.Name)
+		err = occ.gClient.Delete(ctx, object)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return false, errors.Wrapf(err, "failed to delete legacy object %s", name)
+		}
+	}
+
+	return false, nil
+}
+
+// getMigratedClaimName returns the name of the claim a legacy claim is migrated to
+func getMigratedClaimName(orchest *orchestv1alpha1.OrchestCluster, legacyName string) string {
+	if legacyName == addons.DockerRegistry {
+		return getRegistryName(orchest)
+	}
+	return controller.GetResourceName(orchest.Name, legacyName)
+}
+
+// getMigratedClaim returns the claim a legacy claim is migrated to, it claims the volume of the
+// legacy claim. The claim of the registry is labeled to be adopted by the new registry release.
+func getMigratedClaim(orchest *orchestv1alpha1.OrchestCluster,
+	legacyPvc *corev1.PersistentVolumeClaim) *corev1.PersistentVolumeClaim {
+
+	var metadata metav1.ObjectMeta
+	if legacyPvc.Name == addons.DockerRegistry {
+		releaseName := getRegistryReleaseName(orchest)
+		metadata = metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app":              getRegistryName(orchest),
+				"release":          releaseName,
+				"heritage":         "Helm",
+				helmManagedByLabel: "Helm",
+			},
+			Annotations: map[string]string{
+				helmReleaseNameAnnotation:      releaseName,
+				helmReleaseNamespaceAnnotation: orchest.Namespace,
+			},
+		}
+	} else {
+		metadata = controller.GetMetadata(legacyPvc.Name, fmt.Sprint(orchest.Generation),
+			orchest, OrchestClusterKind)
+	}
+	metadata.Name = getMigratedClaimName(orchest, legacyPvc.Name)
+	metadata.Namespace = orchest.Namespace
+
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metadata,
+		Spec:       *legacyPvc.Spec.DeepCopy(),
+	}
+}
@@ -0,0 +1,72 @@
+package orchestcluster
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetMigratedClaim(t *testing.T) {
+
+	orchest := &orchestv1alpha1.OrchestCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster-1",
+			Namespace: "orchest",
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		legacyName        string
+		expectedName      string
+		expectedOwned     bool
+		expectedAnnotated bool
+	}{
+		{
+			name:          "userdir",
+			legacyName:    controller.UserDirName,
+			expectedName:  "cluster-1-userdir-pvc",
+			expectedOwned: true,
+		},
+		{
+			name:          "builder cache",
+			legacyName:    controller.BuilderDirName,
+			expectedName:  "cluster-1-image-builder-cache-pvc",
+			expectedOwned: true,
+		},
+		{
+			name:              "registry",
+			legacyName:        "docker-registry",
+			expectedName:      "cluster-1-docker-registry",
+			expectedAnnotated: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			legacyPvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      test.legacyName,
+					Namespace: orchest.Namespace,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					VolumeName: "pv-1",
+				},
+			}
+
+			pvc := getMigratedClaim(orchest, legacyPvc)
+
+			assert.Equal(t, test.expectedName, pvc.Name)
+			assert.Equal(t, "pv-1", pvc.Spec.VolumeName)
+			assert.Equal(t, test.expectedOwned, metav1.IsControlledBy(pvc, orchest))
+			if test.expectedAnnotated {
+				assert.Equal(t, "Helm", pvc.Labels[helmManagedByLabel])
+				assert.Equal(t, "orchest-cluster-1-docker-registry", pvc.Annotations[helmReleaseNameAnnotation])
+			}
+		})
+	}
+}
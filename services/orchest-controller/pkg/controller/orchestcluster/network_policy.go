@@ -0,0 +1,322 @@
+package orchestcluster
+
+import (
+	"context"
+	"net"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	netsv1 "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var (
+	// The name of the NetworkPolicy restricting the egress of the user pods
+	userEgressPolicy = "user-egress"
+
+	// The user pods, i.e. sessions, kernels, services and pipeline steps, are labeled with the
+	// project they belong to
+	userPodLabelKey = "project_uuid"
+
+	// The label of the ingress-nginx controller pods
+	ingressNginxPodLabels = map[string]string{
+		"app.kubernetes.io/name":      "ingress-nginx",
+		"app.kubernetes.io/component": "controller",
+	}
+
+	// The label of the Traefik pods
+	traefikPodLabels = map[string]string{
+		"app.kubernetes.io/name": "traefik",
+	}
+
+	// The Orchest services using the database and the broker
+	databaseClients = []string{controller.OrchestApi, controller.CeleryWorker,
+		controller.OrchestWebserver, controller.AuthServer}
+	brokerClients = []string{controller.OrchestApi, controller.CeleryWorker}
+
+	// All Orchest services of a cluster
+	orchestServices = []string{controller.OrchestDatabase, controller.Rabbitmq, controller.OrchestApi,
+		controller.CeleryWorker, controller.AuthServer, controller.OrchestWebserver, controller.NodeAgent,
+		controller.OrchestProxy}
+)
+
+// ensureNetworkPolicies creates the NetworkPolicies enabled in the OrchestCluster, and deletes
+// the disabled ones.
+func (occ *OrchestClusterController) ensureNetworkPolicies(ctx context.Context,
+	hash string, orchest *orchestv1alpha1.OrchestCluster) error {
+
+	spec := orchest.Spec.Orchest.NetworkPolicy
+	if spec == nil {
+		spec = &orchestv1alpha1.NetworkPolicySpec{}
+	}
+
+	var apiServer *netsv1.NetworkPolicyEgressRule
+	if spec.UserEgress {
+		var err error
+		apiServer, err = occ.getAPIServerEgressRule(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	enabled, disabled := getNetworkPolicies(hash, orchest, apiServer)
+
+	for _, policy := range enabled {
+		err := controller.UpsertObject(ctx, occ.gClient, policy)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range disabled {
+		policy := &netsv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: orchest.Namespace,
+			},
+		}
+		err := occ.gClient.Delete(ctx, policy)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete NetworkPolicy %s", name)
+		}
+	}
+
+	return nil
+}
+
+// getAPIServerEgressRule returns the egress rule allowing the Kubernetes API, the rule has to
+// select the endpoints of the API server, since the policies apply after the service is resolved
+func (occ *OrchestClusterController) getAPIServerEgressRule(ctx context.Context) (
+	*netsv1.NetworkPolicyEgressRule, error) {
+
+	endpoints, err := occ.Client().CoreV1().Endpoints(metav1.NamespaceDefault).Get(ctx, "kubernetes", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the endpoints of the Kubernetes API")
+	}
+
+	rule := &netsv1.NetworkPolicyEgressRule{}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			rule.To = append(rule.To, netsv1.NetworkPolicyPeer{
+				IPBlock: &netsv1.IPBlock{CIDR: getHostCIDR(address.IP)},
+			})
+		}
+		for _, port := range subset.Ports {
+			rule.Ports = append(rule.Ports, getPolicyPort(port.Protocol, port.Port))
+		}
+	}
+
+	return rule, nil
+}
+
+// getNetworkPolicies returns the NetworkPolicies enabled in the OrchestCluster and the names of
+// the disabled ones. apiServer is the egress rule of the Kubernetes API used if the egress of the
+// user pods is restricted.
+func getNetworkPolicies(hash string, orchest *orchestv1alpha1.OrchestCluster,
+	apiServer *netsv1.NetworkPolicyEgressRule) ([]*netsv1.NetworkPolicy, []string) {
+
+	spec := orchest.Spec.Orchest.NetworkPolicy
+	if spec == nil {
+		spec = &orchestv1alpha1.NetworkPolicySpec{}
+	}
+
+	enabled := make([]*netsv1.NetworkPolicy, 0, 6)
+	disabled := make([]string, 0, 6)
+	add := func(on bool, policy *netsv1.NetworkPolicy) {
+		if on {
+			enabled = append(enabled, policy)
+		} else {
+			disabled = append(disabled, policy.Name)
+		}
+	}
+
+	// The pods owned by the cluster itself, e.g. the registry garbage collection, are Orchest pods too
+	orchestPods := getOwnersPeer(append(getResourceNames(orchest, orchestServices), orchest.Name)...)
+
+	ingressController := getIngressControllerPeer(orchest)
+	if spec.IngressControllerNamespace != "" {
+		ingressController = netsv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					corev1.LabelMetadataName: spec.IngressControllerNamespace,
+				},
+			},
+		}
+	}
+
+	userPods := netsv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      userPodLabelKey,
+					Operator: metav1.LabelSelectorOpExists,
+				},
+			},
+		},
+	}
+
+	add(spec.Databases, getIngressPolicy(hash, orchest, controller.OrchestDatabase, 5432,
+		getServicesPeer(orchest, databaseClients...)))
+
+	add(spec.Databases, getIngressPolicy(hash, orchest, controller.Rabbitmq, 5672,
+		getServicesPeer(orchest, brokerClients...)))
+
+	add(spec.Ingress, getIngressPolicy(hash, orchest, controller.OrchestWebserver, 80,
+		ingressController, orchestPods))
+
+	add(spec.Ingress, getIngressPolicy(hash, orchest, controller.AuthServer, 80,
+		ingressController, orchestPods))
+
+	add(spec.Ingress, getIngressPolicy(hash, orchest, controller.OrchestApi, 80,
+		ingressController, orchestPods, userPods))
+
+	// The user pods may reach the DNS, the other user pods, orchest-api, the Kubernetes API,
+	// e.g. to report the results of the pipeline steps to argo, and the allowed CIDRs
+	egress := []netsv1.NetworkPolicyEgressRule{
+		{
+			Ports: []netsv1.NetworkPolicyPort{
+				getPolicyPort(corev1.ProtocolUDP, 53),
+				getPolicyPort(corev1.ProtocolTCP, 53),
+			},
+		},
+		{
+			To: []netsv1.NetworkPolicyPeer{userPods},
+		},
+		{
+			To:    []netsv1.NetworkPolicyPeer{getServicesPeer(orchest, controller.OrchestApi)},
+			Ports: []netsv1.NetworkPolicyPort{getPolicyPort(corev1.ProtocolTCP, 80)},
+		},
+	}
+	if apiServer != nil && len(apiServer.To) > 0 {
+		egress = append(egress, *apiServer)
+	}
+	if len(spec.AllowedEgressCIDRs) > 0 {
+		allowed := netsv1.NetworkPolicyEgressRule{}
+		for _, cidr := range spec.AllowedEgressCIDRs {
+			allowed.To = append(allowed.To, netsv1.NetworkPolicyPeer{
+				IPBlock: &netsv1.IPBlock{CIDR: cidr},
+			})
+		}
+		egress = append(egress, allowed)
+	}
+
+	egressMetadata := controller.GetMetadata(userEgressPolicy, hash, orchest, OrchestClusterKind)
+	egressMetadata.Name = controller.GetResourceName(orchest.Name, userEgressPolicy)
+	add(spec.UserEgress, &netsv1.NetworkPolicy{
+		ObjectMeta: egressMetadata,
+		Spec: netsv1.NetworkPolicySpec{
+			PodSelector: *userPods.PodSelector,
+			PolicyTypes: []netsv1.PolicyType{netsv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	})
+
+	return enabled, disabled
+}
+
+// getIngressControllerPeer returns the peer selecting the ingress controller pods of the ingress
+// provider. The pods of the Gateway implementations are not known, so all pods are selected
+// unless the namespace of the ingress controller is specified.
+func getIngressControllerPeer(orchest *orchestv1alpha1.OrchestCluster) netsv1.NetworkPolicyPeer {
+
+	var provider orchestv1alpha1.IngressProvider
+	if ingress := orchest.Spec.Orchest.Ingress; ingress != nil {
+		provider = ingress.Provider
+	}
+
+	switch provider {
+	case orchestv1alpha1.GatewayIngressProvider:
+		return netsv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{},
+		}
+	case orchestv1alpha1.TraefikIngressProvider:
+		return netsv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{},
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: traefikPodLabels,
+			},
+		}
+	default:
+		return netsv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{},
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: ingressNginxPodLabels,
+			},
+		}
+	}
+}
+
+// getIngressPolicy returns the NetworkPolicy which allows only the peers to reach the port of
+// the pods of an Orchest service
+func getIngressPolicy(hash string, orchest *orchestv1alpha1.OrchestCluster,
+	service string, port int32, peers ...netsv1.NetworkPolicyPeer) *netsv1.NetworkPolicy {
+
+	metadata := controller.GetMetadata(service, hash, orchest, OrchestClusterKind)
+	metadata.Name = controller.GetResourceName(orchest.Name, service)
+
+	return &netsv1.NetworkPolicy{
+		ObjectMeta: metadata,
+		Spec: netsv1.NetworkPolicySpec{
+			PodSelector: *getServicesPeer(orchest, service).PodSelector,
+			PolicyTypes: []netsv1.PolicyType{netsv1.PolicyTypeIngress},
+			Ingress: []netsv1.NetworkPolicyIngressRule{
+				{
+					From:  peers,
+					Ports: []netsv1.NetworkPolicyPort{getPolicyPort(corev1.ProtocolTCP, port)},
+				},
+			},
+		},
+	}
+}
+
+// getServicesPeer returns the peer selecting the pods of the Orchest services of the cluster,
+// the pods of a component are owned by the component, which is named after the cluster
+func getServicesPeer(orchest *orchestv1alpha1.OrchestCluster, services ...string) netsv1.NetworkPolicyPeer {
+	return getOwnersPeer(getResourceNames(orchest, services)...)
+}
+
+// getOwnersPeer returns the peer selecting the Orchest pods of the owners
+func getOwnersPeer(owners ...string) netsv1.NetworkPolicyPeer {
+	return netsv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				controller.ControllerPartOfLabel: "orchest",
+			},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      controller.OwnerLabelKey,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   owners,
+				},
+			},
+		},
+	}
+}
+
+func getResourceNames(orchest *orchestv1alpha1.OrchestCluster, names []string) []string {
+	resourceNames := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		resourceNames = append(resourceNames, controller.GetResourceName(orchest.Name, name))
+	}
+	return resourceNames
+}
+
+func getPolicyPort(protocol corev1.Protocol, port int32) netsv1.NetworkPolicyPort {
+	policyPort := intstr.FromInt(int(port))
+	return netsv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &policyPort,
+	}
+}
+
+// getHostCIDR returns the CIDR of a single IP address
+func getHostCIDR(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return ip + "/128"
+	}
+	return ip + "/32"
+}
@@ -0,0 +1,88 @@
+package orchestcluster
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	netsv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetNetworkPolicies(t *testing.T) {
+
+	allPolicies := []string{
+		"cluster-1-orchest-database",
+		"cluster-1-rabbitmq-server",
+		"cluster-1-orchest-webserver",
+		"cluster-1-auth-server",
+		"cluster-1-orchest-api",
+		"cluster-1-user-egress",
+	}
+
+	apiServer := &netsv1.NetworkPolicyEgressRule{
+		To: []netsv1.NetworkPolicyPeer{
+			{IPBlock: &netsv1.IPBlock{CIDR: "10.0.0.1/32"}},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		spec             *orchestv1alpha1.NetworkPolicySpec
+		expectedEnabled  []string
+		expectedDisabled []string
+		expectedEgress   int
+	}{
+		{
+			name:             "not specified",
+			spec:             nil,
+			expectedEnabled:  []string{},
+			expectedDisabled: allPolicies,
+		},
+		{
+			name: "databases",
+			spec: &orchestv1alpha1.NetworkPolicySpec{
+				Databases: true,
+			},
+			expectedEnabled:  allPolicies[:2],
+			expectedDisabled: allPolicies[2:],
+		},
+		{
+			name: "ingress and user egress",
+			spec: &orchestv1alpha1.NetworkPolicySpec{
+				Ingress:            true,
+				UserEgress:         true,
+				AllowedEgressCIDRs: []string{"0.0.0.0/0"},
+			},
+			expectedEnabled:  allPolicies[2:],
+			expectedDisabled: allPolicies[:2],
+			// DNS, user pods, orchest-api, the Kubernetes API and the allowed CIDRs
+			expectedEgress: 5,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			orchest := &orchestv1alpha1.OrchestCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster-1",
+					Namespace: "orchest",
+				},
+			}
+			orchest.Spec.Orchest.NetworkPolicy = test.spec
+
+			enabled, disabled := getNetworkPolicies("1", orchest, apiServer)
+
+			enabledNames := make([]string, 0, len(enabled))
+			for _, policy := range enabled {
+				enabledNames = append(enabledNames, policy.Name)
+				if len(policy.Spec.Egress) > 0 {
+					assert.Equal(t, test.expectedEgress, len(policy.Spec.Egress))
+				}
+			}
+
+			assert.Equal(t, test.expectedEnabled, enabledNames)
+			assert.Equal(t, test.expectedDisabled, disabled)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package orchestcluster
+
+import (
+	"context"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// The labels of the namespace read by the Pod Security Admission
+	podSecurityEnforceLabelKey = "pod-security.kubernetes.io/enforce"
+	podSecurityAuditLabelKey   = "pod-security.kubernetes.io/audit"
+	podSecurityWarnLabelKey    = "pod-security.kubernetes.io/warn"
+
+	podSecurityPrivileged = "privileged"
+	podSecurityBaseline   = "baseline"
+	podSecurityRestricted = "restricted"
+
+	// node-agent mounts the container runtime socket of the nodes, and the image builds and the
+	// sessions of the users run privileged or mount the socket too, so privileged is the
+	// strictest level which can be enforced. The Orchest services satisfy the restricted level
+	// apart from node-agent, which the audit log and the warnings call out.
+	defaultPodSecurityLevels = map[string]string{
+		podSecurityEnforceLabelKey: podSecurityPrivileged,
+		podSecurityAuditLabelKey:   podSecurityRestricted,
+		podSecurityWarnLabelKey:    podSecurityBaseline,
+	}
+)
+
+// ensurePodSecurityLabels labels the namespace of the OrchestCluster with the Pod Security
+// Admission levels of the OrchestCluster. The levels which are not specified are defaulted,
+// unless the administrator of the cluster already labeled the namespace with them.
+func (occ *OrchestClusterController) ensurePodSecurityLabels(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	namespace, err := occ.Client().CoreV1().Namespaces().Get(ctx, orchest.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get namespace %s", orchest.Namespace)
+	}
+
+	labels := getPodSecurityLabels(orchest.Spec.Orchest.PodSecurity, namespace.Labels)
+
+	changed := false
+	for key, value := range labels {
+		if namespace.Labels[key] != value {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	namespace.Labels = utils.CloneAndAddLabel(namespace.Labels, labels)
+	_, err = occ.Client().CoreV1().Namespaces().Update(ctx, namespace, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to label namespace %s", orchest.Namespace)
+	}
+
+	return nil
+}
+
+// getPodSecurityLabels returns the Pod Security Admission labels of the specified levels, and
+// of the default levels which are not already labeled on the namespace.
+func getPodSecurityLabels(podSecurity *orchestv1alpha1.PodSecuritySpec,
+	namespaceLabels map[string]string) map[string]string {
+
+	labels := map[string]string{}
+	if podSecurity != nil {
+		if podSecurity.Enforce != "" {
+			labels[podSecurityEnforceLabelKey] = podSecurity.Enforce
+		}
+		if podSecurity.Audit != "" {
+			labels[podSecurityAuditLabelKey] = podSecurity.Audit
+		}
+		if podSecurity.Warn != "" {
+			labels[podSecurityWarnLabelKey] = podSecurity.Warn
+		}
+	}
+
+	for key, level := range defaultPodSecurityLevels {
+		if _, ok := labels[key]; ok {
+			continue
+		}
+		if _, ok := namespaceLabels[key]; !ok {
+			labels[key] = level
+		}
+	}
+
+	return labels
+}
+
+// validatePodSecurity validates the Pod Security Admission levels of the OrchestCluster
+func validatePodSecurity(podSecurity *orchestv1alpha1.PodSecuritySpec) error {
+
+	for _, level := range []string{podSecurity.Enforce, podSecurity.Audit, podSecurity.Warn} {
+		switch level {
+		case "", podSecurityPrivileged, podSecurityBaseline, podSecurityRestricted:
+		default:
+			return errors.Errorf("unrecognized pod security level %s", level)
+		}
+	}
+
+	// node-agent would be rejected, as it runs in the namespace of the OrchestCluster
+	if podSecurity.Enforce != "" && podSecurity.Enforce != podSecurityPrivileged {
+		return errors.Errorf("pod security level %s can not be enforced, node-agent requires %s",
+			podSecurity.Enforce, podSecurityPrivileged)
+	}
+
+	return nil
+}
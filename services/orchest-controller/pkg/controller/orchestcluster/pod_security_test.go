@@ -0,0 +1,98 @@
+package orchestcluster
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPodSecurityLabels(t *testing.T) {
+
+	testCases := []struct {
+		name            string
+		podSecurity     *orchestv1alpha1.PodSecuritySpec
+		namespaceLabels map[string]string
+		expected        map[string]string
+	}{
+		{
+			name: "default levels",
+			expected: map[string]string{
+				podSecurityEnforceLabelKey: podSecurityPrivileged,
+				podSecurityAuditLabelKey:   podSecurityRestricted,
+				podSecurityWarnLabelKey:    podSecurityBaseline,
+			},
+		},
+		{
+			name:        "specified levels",
+			podSecurity: &orchestv1alpha1.PodSecuritySpec{Audit: podSecurityBaseline},
+			expected: map[string]string{
+				podSecurityEnforceLabelKey: podSecurityPrivileged,
+				podSecurityAuditLabelKey:   podSecurityBaseline,
+				podSecurityWarnLabelKey:    podSecurityBaseline,
+			},
+		},
+		{
+			name:        "levels labeled by the administrator",
+			podSecurity: &orchestv1alpha1.PodSecuritySpec{Warn: podSecurityRestricted},
+			namespaceLabels: map[string]string{
+				podSecurityAuditLabelKey: podSecurityPrivileged,
+				podSecurityWarnLabelKey:  podSecurityBaseline,
+			},
+			expected: map[string]string{
+				podSecurityEnforceLabelKey: podSecurityPrivileged,
+				podSecurityWarnLabelKey:    podSecurityRestricted,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, getPodSecurityLabels(test.podSecurity, test.namespaceLabels))
+		})
+	}
+}
+
+func TestValidatePodSecurity(t *testing.T) {
+
+	testCases := []struct {
+		name          string
+		podSecurity   *orchestv1alpha1.PodSecuritySpec
+		expectedError bool
+	}{
+		{
+			name:        "no levels",
+			podSecurity: &orchestv1alpha1.PodSecuritySpec{},
+		},
+		{
+			name: "privileged enforced",
+			podSecurity: &orchestv1alpha1.PodSecuritySpec{
+				Enforce: podSecurityPrivileged,
+				Audit:   podSecurityRestricted,
+				Warn:    podSecurityRestricted,
+			},
+		},
+		{
+			name:          "baseline enforced",
+			podSecurity:   &orchestv1alpha1.PodSecuritySpec{Enforce: podSecurityBaseline},
+			expectedError: true,
+		},
+		{
+			name:          "restricted enforced",
+			podSecurity:   &orchestv1alpha1.PodSecuritySpec{Enforce: podSecurityRestricted},
+			expectedError: true,
+		},
+		{
+			name:          "unrecognized level",
+			podSecurity:   &orchestv1alpha1.PodSecuritySpec{Warn: "strict"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			err := validatePodSecurity(test.podSecurity)
+			assert.Equal(t, test.expectedError, err != nil)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package orchestcluster
+
+import (
+	"context"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// The permissions of orchest-api and celery-worker in the namespace of the OrchestCluster.
+	// Both run the same application, which launches sessions, runs pipelines on argo and
+	// builds images, and creates the Roles of the session pods, which may only grant
+	// permissions held by the application itself. The application restarts the OrchestCluster
+	// and has the controller update it, which checks that it may update the OrchestCluster.
+	orchestApiPolicyRules = []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods/log"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods/exec"},
+			Verbs:     []string{"get", "create"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"services", "serviceaccounts"},
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+		},
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"deployments", "deployments/status"},
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+		},
+		{
+			APIGroups: []string{"networking.k8s.io"},
+			Resources: []string{"ingresses"},
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings"},
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+		},
+		{
+			APIGroups: []string{"argoproj.io"},
+			Resources: []string{"workflows"},
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+		},
+		{
+			APIGroups: []string{orchestv1alpha1.SchemeGroupVersion.Group},
+			Resources: []string{"orchestclusters"},
+			Verbs:     []string{"get", "list", "watch", "update", "patch"},
+		},
+	}
+)
+
+// deleteLegacyClusterRbac deletes the ClusterRoleBinding of the service account of a component
+// created by previous versions of the controller, and the ClusterRole once no other cluster
+// binds it. They were named after the component and shared by all OrchestClusters.
+func (occ *OrchestClusterController) deleteLegacyClusterRbac(ctx context.Context,
+	name string, orchest *orchestv1alpha1.OrchestCluster) error {
+
+	rbacClient := occ.Client().RbacV1()
+
+	binding, err := rbacClient.ClusterRoleBindings().Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get ClusterRoleBinding %s", name)
+	}
+
+	if err == nil {
+		if binding.Labels[controller.ControllerPartOfLabel] != "orchest" {
+			return nil
+		}
+
+		subjects := make([]rbacv1.Subject, 0, len(binding.Subjects))
+		for _, subject := range binding.Subjects {
+			if subject.Kind != "ServiceAccount" || subject.Namespace != orchest.Namespace {
+				subjects = append(subjects, subject)
+			}
+		}
+
+		if len(subjects) == len(binding.Subjects) {
+			// The binding is not of this cluster
+			return nil
+		}
+
+		klog.Infof("Removing the cluster wide permissions of %s of OrchestCluster %s", name, orchest.Name)
+
+		if len(subjects) > 0 {
+			binding = binding.DeepCopy()
+			binding.Subjects = subjects
+			_, err = rbacClient.ClusterRoleBindings().Update(ctx, binding, metav1.UpdateOptions{})
+			if err != nil {
+				return errors.Wrapf(err, "failed to update ClusterRoleBinding %s", name)
+			}
+			return nil
+		}
+
+		err = rbacClient.ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete ClusterRoleBinding %s", name)
+		}
+	}
+
+	role, err := rbacClient.ClusterRoles().Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get ClusterRole %s", name)
+	}
+
+	if role.Labels[controller.ControllerPartOfLabel] != "orchest" {
+		return nil
+	}
+
+	err = rbacClient.ClusterRoles().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete ClusterRole %s", name)
+	}
+
+	return nil
+}
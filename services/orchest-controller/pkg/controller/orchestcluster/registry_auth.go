@@ -0,0 +1,225 @@
+package orchestcluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/orchest/orchest/services/orchest-controller/pkg/addons"
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// The secret holding the credentials of the in-cluster registry, it is also used as
+	// the imagePullSecret of the pods pulling from the registry
+	registryCredentialsSecret = "docker-registry-credentials"
+
+	registryUsername = "orchest"
+
+	// The keys of the registry credentials secret
+	registryUsernameKey = "username"
+	registryPasswordKey = "password"
+	registryHtpasswdKey = "htpasswd"
+
+	// Registry helm parameters
+	registryHtpasswd = "secrets.htpasswd"
+
+	// If present on the OrchestCluster, the registry credentials are rotated
+	RotateRegistryCredentialsAnnotationKey = "orchest.io/rotate-registry-credentials"
+)
+
+// withRegistryCredentials returns a copy of the registry application, configured with the
+// htpasswd of the registry credentials. The credentials are generated if they don't exist yet
+// or if their rotation is requested.
+func (occ *OrchestClusterController) withRegistryCredentials(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster,
+	app *orchestv1alpha1.ApplicationSpec) (*orchestv1alpha1.ApplicationSpec, error) {
+
+	serviceIPs, err := getRegistryServiceIPs(&app.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	htpasswd, err := occ.ensureRegistryCredentials(ctx, orchest, serviceIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = occ.ensureImagePullSecret(ctx, orchest)
+	if err != nil {
+		return nil, err
+	}
+
+	app = app.DeepCopy()
+	if app.Config.Helm == nil {
+		app.Config.Helm = &orchestv1alpha1.ApplicationConfigHelm{}
+	}
+	app.Config.Helm.Parameters = append(app.Config.Helm.Parameters, orchestv1alpha1.HelmParameter{
+		Name:  registryHtpasswd,
+		Value: htpasswd,
+	})
+
+	return app, nil
+}
+
+// ensureRegistryCredentials makes sure the registry credentials secret exists and returns
+// the htpasswd of the credentials. The credentials are kept if only the registry hosts change,
+// e.g. the service got an IP of a new IP family.
+func (occ *OrchestClusterController) ensureRegistryCredentials(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, serviceIPs []string) (string, error) {
+
+	_, rotate := orchest.GetAnnotations()[RotateRegistryCredentialsAnnotationKey]
+
+	secretName := controller.GetResourceName(orchest.Name, registryCredentialsSecret)
+	oldSecret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return "", err
+	}
+	exists := err == nil
+
+	var newSecret *corev1.Secret
+	if exists && !rotate {
+		newSecret, err = getRegistryCredentialsSecret(orchest, serviceIPs,
+			string(oldSecret.Data[registryPasswordKey]), string(oldSecret.Data[registryHtpasswdKey]))
+		if err != nil {
+			return "", err
+		}
+
+		if reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+			return string(oldSecret.Data[registryHtpasswdKey]), nil
+		}
+	} else {
+		klog.Infof("Generating the registry credentials of OrchestCluster %s", orchest.Name)
+
+		newSecret, err = getRegistryCredentialsSecret(orchest, serviceIPs, "", "")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !exists {
+		_, err = occ.Client().CoreV1().Secrets(orchest.Namespace).Create(ctx, newSecret, metav1.CreateOptions{})
+	} else {
+		oldSecret = oldSecret.DeepCopy()
+		oldSecret.Data = newSecret.Data
+		_, err = occ.Client().CoreV1().Secrets(orchest.Namespace).Update(ctx, oldSecret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write the registry credentials of %s", orchest.Name)
+	}
+
+	if rotate {
+		_, err = controller.RemoveAnnotation(ctx, occ.gClient, orchest, RotateRegistryCredentialsAnnotationKey)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return string(newSecret.Data[registryHtpasswdKey]), nil
+}
+
+// ensureImagePullSecret adds the registry credentials to the imagePullSecrets of the default
+// service account, so session and job pods can pull from the registry
+func (occ *OrchestClusterController) ensureImagePullSecret(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	serviceAccount, err := occ.Client().CoreV1().ServiceAccounts(orchest.Namespace).Get(ctx, "default", metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the default service account")
+	}
+
+	secretName := controller.GetResourceName(orchest.Name, registryCredentialsSecret)
+	for _, secret := range serviceAccount.ImagePullSecrets {
+		if secret.Name == secretName {
+			return nil
+		}
+	}
+
+	serviceAccount = serviceAccount.DeepCopy()
+	serviceAccount.ImagePullSecrets = append(serviceAccount.ImagePullSecrets,
+		corev1.LocalObjectReference{Name: secretName})
+
+	_, err = occ.Client().CoreV1().ServiceAccounts(orchest.Namespace).Update(ctx, serviceAccount, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to add the registry imagePullSecret to the default service account")
+	}
+
+	return nil
+}
+
+// getRegistryCredentialsSecret returns the registry credentials secret, the secret holds the
+// htpasswd of the registry and a docker config to authenticate against the registry with each
+// of its hosts. New credentials are generated if password is empty.
+func getRegistryCredentialsSecret(orchest *orchestv1alpha1.OrchestCluster, serviceIPs []string,
+	password, htpasswd string) (*corev1.Secret, error) {
+
+	if password == "" || htpasswd == "" {
+		passwordBytes := make([]byte, 32)
+		if _, err := rand.Read(passwordBytes); err != nil {
+			return nil, errors.Wrap(err, "failed to generate the registry password")
+		}
+		password = hex.EncodeToString(passwordBytes)
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to hash the registry password")
+		}
+		htpasswd = fmt.Sprintf("%s:%s", registryUsername, hash)
+	}
+
+	registryName := getRegistryName(orchest)
+	hosts := []string{
+		registryName,
+		fmt.Sprintf("%s.%s.svc.cluster.local", registryName, orchest.Namespace),
+	}
+	for _, serviceIP := range serviceIPs {
+		hosts = append(hosts, utils.GetHostFromIP(serviceIP))
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(registryUsername + ":" + password))
+	auths := map[string]interface{}{}
+	for _, host := range hosts {
+		auths[host] = map[string]string{
+			"username": registryUsername,
+			"password": password,
+			"auth":     auth,
+		}
+	}
+
+	dockerConfig, err := json.Marshal(map[string]interface{}{"auths": auths})
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      controller.GetResourceName(orchest.Name, registryCredentialsSecret),
+			Namespace: orchest.Namespace,
+			Labels: map[string]string{
+				"app": addons.DockerRegistry,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(orchest, OrchestClusterKind),
+			},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			registryUsernameKey:        []byte(registryUsername),
+			registryPasswordKey:        []byte(password),
+			registryHtpasswdKey:        []byte(htpasswd),
+			corev1.DockerConfigJsonKey: dockerConfig,
+		},
+	}, nil
+}
@@ -0,0 +1,244 @@
+package orchestcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/orchest/orchest/services/orchest-controller/pkg/addons"
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/certs"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+var (
+	registryTLSSecret = "registry-tls-secret"
+
+	// The registry certificates are renewed if they expire within this period
+	registryCertRenewBefore = 30 * 24 * time.Hour
+
+	// The interval the registry certificates are checked at
+	registryCertCheckPeriod = time.Hour
+
+	// The pod template annotation which restarts the registry and node-agent after renewal
+	registryCertsRenewedAnnotationKey = "orchest.io/registry-certs-renewed-at"
+)
+
+// checkRegistryCertificates renews the registry certificates of all OrchestClusters if needed
+func (occ *OrchestClusterController) checkRegistryCertificates() {
+
+	ctx, cancel := context.WithTimeout(context.Background(), registryCertCheckPeriod)
+	defer cancel()
+
+	orchests, err := occ.oClusterLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list OrchestClusters, error: %v", err)
+		return
+	}
+
+	for _, orchest := range orchests {
+		err = occ.ensureRegistryCertificates(ctx, orchest)
+		if err != nil {
+			klog.Errorf("failed to check the registry certificates of OrchestCluster %s, error: %v",
+				orchest.Name, err)
+		}
+	}
+}
+
+// ensureRegistryCertificates renews the registry certificates if they expire soon, and
+// reports their expiry in the status of the OrchestCluster.
+func (occ *OrchestClusterController) ensureRegistryCertificates(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	var registry *orchestv1alpha1.ApplicationSpec
+	for i := range orchest.Spec.Applications {
+		if orchest.Spec.Applications[i].Name == addons.DockerRegistry {
+			registry = &orchest.Spec.Applications[i]
+		}
+	}
+
+	// The certificates are created by the registry pre-install hook, so they are only
+	// renewed once the cluster is running. External registries manage their own certificates.
+	if registry == nil || orchest.Spec.Orchest.ImageRegistry != nil ||
+		orchest.Status == nil || orchest.Status.Phase != orchestv1alpha1.Running {
+		return nil
+	}
+
+	expiry, err := occ.getRegistryCertificateExpiry(ctx, orchest)
+	if kerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	serviceIPs, err := getRegistryServiceIPs(&registry.Config)
+	if err != nil {
+		return err
+	}
+
+	coversIPs, err := occ.registryCertificateCoversIPs(ctx, orchest, serviceIPs)
+	if err != nil {
+		return err
+	}
+
+	var renewedAt *metav1.Time
+	if time.Until(expiry) < registryCertRenewBefore || !coversIPs {
+		klog.Infof("Registry certificates of OrchestCluster %s expire at %s or don't cover the service IPs %v, renewing",
+			orchest.Name, expiry, serviceIPs)
+
+		err = registryCertgen(ctx, occ.Client(), serviceIPs, orchest, true)
+		if err != nil {
+			return err
+		}
+
+		expiry, err = occ.getRegistryCertificateExpiry(ctx, orchest)
+		if err != nil {
+			return err
+		}
+
+		now := metav1.Now()
+		renewedAt = &now
+
+		err = occ.restartRegistryConsumers(ctx, orchest, now.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+	}
+
+	return occ.updateRegistryStatus(ctx, orchest, expiry, renewedAt)
+}
+
+// restartRegistryConsumers restarts the registry to serve the new certificates, and the
+// node-agents to copy the new CA certificate to the nodes in its postStart hook.
+func (occ *OrchestClusterController) restartRegistryConsumers(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, renewedAt string) error {
+
+	namespace := orchest.Namespace
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		registryCertsRenewedAnnotationKey, renewedAt))
+
+	registryName := getRegistryName(orchest)
+	_, err := occ.Client().AppsV1().Deployments(namespace).Patch(ctx, registryName,
+		types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to restart %s", registryName)
+	}
+
+	// There is a node-agent for each container runtime of the cluster
+	selector := labels.SelectorFromSet(map[string]string{
+		controller.ComponentLabelKey: controller.NodeAgent,
+		controller.OwnerLabelKey:     controller.GetResourceName(orchest.Name, controller.NodeAgent),
+	})
+	daemonSets, err := occ.Client().AppsV1().DaemonSets(namespace).List(ctx,
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list %s", controller.NodeAgent)
+	}
+
+	for _, daemonSet := range daemonSets.Items {
+		_, err = occ.Client().AppsV1().DaemonSets(namespace).Patch(ctx, daemonSet.Name,
+			types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to restart %s", daemonSet.Name)
+		}
+	}
+
+	return nil
+}
+
+func (occ *OrchestClusterController) updateRegistryStatus(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, expiry time.Time, renewedAt *metav1.Time) error {
+
+	orchest, err := occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).Get(ctx, orchest.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get OrchestCluster")
+	}
+
+	if orchest.Status == nil {
+		return nil
+	}
+
+	registryStatus := orchest.Status.Registry.DeepCopy()
+	if registryStatus == nil {
+		registryStatus = &orchestv1alpha1.RegistryStatus{}
+	}
+
+	expiryTime := metav1.NewTime(expiry)
+	if registryStatus.CertificateExpiry != nil && registryStatus.CertificateExpiry.Equal(&expiryTime) &&
+		renewedAt == nil {
+		// The status is not changed
+		return nil
+	}
+
+	registryStatus.CertificateExpiry = &expiryTime
+	if renewedAt != nil {
+		registryStatus.LastCertificateRenewal = renewedAt
+	}
+
+	orchest.Status.Registry = registryStatus
+
+	_, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).UpdateStatus(ctx, orchest, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the registry status of OrchestCluster %s", orchest.Name)
+	}
+
+	return nil
+}
+
+// getRegistryCertificateExpiry returns the earliest expiry of the CA and the registry certificate
+func (occ *OrchestClusterController) getRegistryCertificateExpiry(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (time.Time, error) {
+
+	secretName := controller.GetResourceName(orchest.Name, registryTLSSecret)
+	secret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	expiry, err := certs.GetCertificateExpiry(secret.Data[utils.CACertificateKey], secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to parse the certificates of %s", secretName)
+	}
+
+	return expiry, nil
+}
+
+// registryCertificateCoversIPs returns true if the registry certificate carries all the given IPs
+func (occ *OrchestClusterController) registryCertificateCoversIPs(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster, serviceIPs []string) (bool, error) {
+
+	secretName := controller.GetResourceName(orchest.Name, registryTLSSecret)
+	secret, err := occ.Client().CoreV1().Secrets(orchest.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	certIPs, err := certs.GetCertificateIPs(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse the certificate of %s", secretName)
+	}
+
+	for _, serviceIP := range serviceIPs {
+		covered := false
+		for _, certIP := range certIPs {
+			if certIP.Equal(net.ParseIP(serviceIP)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
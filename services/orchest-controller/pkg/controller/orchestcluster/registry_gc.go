@@ -0,0 +1,316 @@
+package orchestcluster
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/orchest/orchest/services/orchest-controller/pkg/addons"
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+var (
+	registryGC = "docker-registry-gc"
+
+	registryGCDefaultSchedule = "0 3 * * *"
+
+	// The interval the results of the garbage collection jobs are checked at
+	registryGCCheckPeriod = 10 * time.Minute
+
+	// Has to match the image of the docker-registry chart, see
+	// deploy/thirdparty/docker-registry/orchest-values.yaml
+	registryImage = "registry:2.7.1"
+
+	registryDataDir = "/var/lib/registry"
+
+	// The garbage collection script, orchest-api puts the registry in maintenance, so no images
+	// are pushed during the garbage collection, deletes the manifests of the images no longer
+	// referenced and returns the repositories which have no images left. The reclaimed space
+	// is reported through the termination message of the container.
+	registryGCScript = `set -e
+api=http://$ORCHEST_API_HOST/api/ctl/registry-garbage-collection
+response=$(wget -q -O - --post-data '' "$api")
+trap 'wget -q -O /dev/null --post-data "" "$api/complete"' EXIT
+
+before=$(du -sk ` + registryDataDir + ` | cut -f1)
+registry garbage-collect --delete-untagged /etc/docker/registry/config.yml
+for repo in $(echo "$response" | sed -e 's/.*\[\(.*\)\].*/\1/' | tr -d '" ' | tr ',' ' '); do
+  rm -rf "` + registryDataDir + `/docker/registry/v2/repositories/$repo"
+done
+after=$(du -sk ` + registryDataDir + ` | cut -f1)
+
+echo "{\"reclaimedBytes\": $(( (before - after) * 1024 ))}" > /dev/termination-log
+`
+)
+
+// registryGCResult is the termination message of the garbage collection container
+type registryGCResult struct {
+	ReclaimedBytes int64 `json:"reclaimedBytes"`
+}
+
+// isRegistryGCEnabled returns true if the controller runs the garbage collection of the
+// in-cluster docker-registry
+func isRegistryGCEnabled(orchest *orchestv1alpha1.OrchestCluster) bool {
+	if orchest.Spec.Orchest.ImageRegistry != nil {
+		return false
+	}
+
+	gc := orchest.Spec.Orchest.RegistryGarbageCollection
+	return gc == nil || !gc.Suspend
+}
+
+// ensureRegistryGarbageCollection creates or updates the CronJob running the registry
+// garbage collection, the CronJob is suspended while the cluster is paused.
+func (occ *OrchestClusterController) ensureRegistryGarbageCollection(ctx context.Context,
+	hash string, orchest *orchestv1alpha1.OrchestCluster) error {
+
+	if orchest.Spec.Orchest.ImageRegistry != nil {
+		name := controller.GetResourceName(orchest.Name, registryGC)
+		err := occ.Client().BatchV1().CronJobs(orchest.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %s cronjob", name)
+		}
+		return nil
+	}
+
+	cronJob := getRegistryGCCronJob(hash, orchest)
+	return controller.UpsertObject(ctx, occ.gClient, cronJob)
+}
+
+func getRegistryGCCronJob(hash string, orchest *orchestv1alpha1.OrchestCluster) *batchv1.CronJob {
+
+	metadata := controller.GetMetadata(registryGC, hash, orchest, OrchestClusterKind)
+	metadata.Name = controller.GetResourceName(orchest.Name, registryGC)
+	matchLabels := controller.GetResourceMatchLables(registryGC, orchest)
+	registryName := getRegistryName(orchest)
+
+	schedule := registryGCDefaultSchedule
+	if gc := orchest.Spec.Orchest.RegistryGarbageCollection; gc != nil && gc.Schedule != "" {
+		schedule = gc.Schedule
+	}
+
+	suspend := !isRegistryGCEnabled(orchest) ||
+		(orchest.Spec.Orchest.Pause != nil && *orchest.Spec.Orchest.Pause)
+
+	var historyLimit int32 = 1
+	var backoffLimit int32 = 2
+	var runAsUser int64 = 1000
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: matchLabels,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			// The registry volume is ReadWriteOnce, so the job runs next to the registry
+			Affinity: &corev1.Affinity{
+				PodAffinity: &corev1.PodAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+						{
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"app":     addons.DockerRegistry,
+									"release": getRegistryReleaseName(orchest),
+								},
+							},
+							TopologyKey: corev1.LabelHostname,
+						},
+					},
+				},
+			},
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsUser: &runAsUser,
+				FSGroup:   &runAsUser,
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    registryGC,
+					Image:   registryImage,
+					Command: []string{"/bin/sh", "-c", registryGCScript},
+					Env: []corev1.EnvVar{
+						{
+							Name:  "REGISTRY_STORAGE_FILESYSTEM_ROOTDIRECTORY",
+							Value: registryDataDir,
+						},
+						{
+							Name:  "ORCHEST_API_HOST",
+							Value: controller.GetResourceName(orchest.Name, controller.OrchestApi),
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "data",
+							MountPath: registryDataDir,
+						},
+						{
+							Name:      "config",
+							MountPath: "/etc/docker/registry",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: registryName,
+						},
+					},
+				},
+				{
+					Name: "config",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: registryName + "-config",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metadata,
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   schedule,
+			Suspend:                    &suspend,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: matchLabels,
+				},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template:     template,
+				},
+			},
+		},
+	}
+}
+
+// checkRegistryGarbageCollections reports the result of the last registry garbage
+// collection in the status of all OrchestClusters
+func (occ *OrchestClusterController) checkRegistryGarbageCollections() {
+
+	ctx, cancel := context.WithTimeout(context.Background(), registryGCCheckPeriod)
+	defer cancel()
+
+	orchests, err := occ.oClusterLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list OrchestClusters, error: %v", err)
+		return
+	}
+
+	for _, orchest := range orchests {
+		if orchest.Spec.Orchest.ImageRegistry != nil {
+			continue
+		}
+
+		err = occ.updateRegistryGCStatus(ctx, orchest)
+		if err != nil {
+			klog.Errorf("failed to check the registry garbage collection of OrchestCluster %s, error: %v",
+				orchest.Name, err)
+		}
+	}
+}
+
+func (occ *OrchestClusterController) updateRegistryGCStatus(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	selector := labels.SelectorFromSet(controller.GetResourceMatchLables(registryGC, orchest))
+	pods, err := occ.Client().CoreV1().Pods(orchest.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list %s pods", registryGC)
+	}
+
+	completedAt, result := getLastRegistryGCResult(pods.Items)
+	if result == nil {
+		return nil
+	}
+
+	orchest, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).Get(ctx, orchest.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get OrchestCluster")
+	}
+
+	if orchest.Status == nil {
+		return nil
+	}
+
+	registryStatus := orchest.Status.Registry.DeepCopy()
+	if registryStatus == nil {
+		registryStatus = &orchestv1alpha1.RegistryStatus{}
+	}
+
+	if registryStatus.LastGarbageCollection != nil && !registryStatus.LastGarbageCollection.Before(completedAt) {
+		// The status is not changed
+		return nil
+	}
+
+	registryStatus.LastGarbageCollection = completedAt
+	registryStatus.ReclaimedBytes = result.ReclaimedBytes
+	orchest.Status.Registry = registryStatus
+
+	_, err = occ.oClient.OrchestV1alpha1().OrchestClusters(orchest.Namespace).UpdateStatus(ctx, orchest, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the registry status of OrchestCluster %s", orchest.Name)
+	}
+
+	return nil
+}
+
+// getLastRegistryGCResult returns the completion time and the result of the last successful
+// garbage collection, or nil if there is none.
+func getLastRegistryGCResult(pods []corev1.Pod) (*metav1.Time, *registryGCResult) {
+
+	type completion struct {
+		finishedAt metav1.Time
+		message    string
+	}
+
+	completions := make([]completion, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == registryGC && status.State.Terminated != nil {
+				completions = append(completions, completion{
+					finishedAt: status.State.Terminated.FinishedAt,
+					message:    status.State.Terminated.Message,
+				})
+			}
+		}
+	}
+
+	sort.Slice(completions, func(i, j int) bool {
+		return completions[j].finishedAt.Before(&completions[i].finishedAt)
+	})
+
+	for _, completion := range completions {
+		result := &registryGCResult{}
+		if err := json.Unmarshal([]byte(completion.message), result); err != nil {
+			klog.Warningf("failed to parse the result of the registry garbage collection: %v", err)
+			continue
+		}
+		return &completion.finishedAt, result
+	}
+
+	return nil, nil
+}
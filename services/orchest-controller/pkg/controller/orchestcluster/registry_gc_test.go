@@ -0,0 +1,87 @@
+package orchestcluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getRegistryGCPod(phase corev1.PodPhase, finishedAt time.Time, message string) corev1.Pod {
+	return corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: registryGC,
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							FinishedAt: metav1.NewTime(finishedAt),
+							Message:    message,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetLastRegistryGCResult(t *testing.T) {
+
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		pods           []corev1.Pod
+		completedAt    *time.Time
+		reclaimedBytes int64
+	}{
+		{
+			name:        "no pods",
+			pods:        []corev1.Pod{},
+			completedAt: nil,
+		},
+		{
+			name: "only failed pods",
+			pods: []corev1.Pod{
+				getRegistryGCPod(corev1.PodFailed, now, ""),
+			},
+			completedAt: nil,
+		},
+		{
+			name: "latest successful pod",
+			pods: []corev1.Pod{
+				getRegistryGCPod(corev1.PodSucceeded, now.Add(-time.Hour), `{"reclaimedBytes": 1024}`),
+				getRegistryGCPod(corev1.PodSucceeded, now, `{"reclaimedBytes": 2048}`),
+				getRegistryGCPod(corev1.PodFailed, now.Add(time.Hour), ""),
+			},
+			completedAt:    &now,
+			reclaimedBytes: 2048,
+		},
+		{
+			name: "invalid termination message",
+			pods: []corev1.Pod{
+				getRegistryGCPod(corev1.PodSucceeded, now.Add(-time.Hour), `{"reclaimedBytes": 1024}`),
+				getRegistryGCPod(corev1.PodSucceeded, now, "garbage"),
+			},
+			completedAt:    func() *time.Time { t := now.Add(-time.Hour); return &t }(),
+			reclaimedBytes: 1024,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			completedAt, result := getLastRegistryGCResult(test.pods)
+			if test.completedAt == nil {
+				assert.Nil(t, completedAt)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.True(t, completedAt.Time.Equal(*test.completedAt))
+			assert.Equal(t, test.reclaimedBytes, result.ReclaimedBytes)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package orchestcluster
+
+import (
+	"context"
+	"time"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// The interval the trusted CA ConfigMaps are checked for changes at
+	trustedCACheckPeriod = time.Minute
+)
+
+// getTrustedCAHash returns the hash of the trusted CA certificates of the OrchestCluster, or an
+// empty string if no trusted CA is specified.
+func (occ *OrchestClusterController) getTrustedCAHash(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) (string, error) {
+
+	ca := orchest.Spec.Orchest.TrustedCA
+	if ca == nil {
+		return "", nil
+	}
+
+	configMap, err := occ.Client().CoreV1().ConfigMaps(orchest.Namespace).Get(ctx, ca.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the trusted CA ConfigMap %s", ca.Name)
+	}
+
+	bundle, ok := configMap.Data[ca.Key]
+	if !ok {
+		return "", errors.Errorf("the trusted CA ConfigMap %s has no key %s", ca.Name, ca.Key)
+	}
+
+	return utils.ComputeHash(bundle), nil
+}
+
+// checkTrustedCAs rolls out the components of all OrchestClusters whose trusted CA changed
+func (occ *OrchestClusterController) checkTrustedCAs() {
+
+	ctx, cancel := context.WithTimeout(context.Background(), trustedCACheckPeriod)
+	defer cancel()
+
+	orchests, err := occ.oClusterLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list OrchestClusters, error: %v", err)
+		return
+	}
+
+	for _, orchest := range orchests {
+		err = occ.ensureTrustedCA(ctx, orchest)
+		if err != nil {
+			klog.Errorf("failed to check the trusted CA of OrchestCluster %s, error: %v",
+				orchest.Name, err)
+		}
+	}
+}
+
+// ensureTrustedCA updates the trusted CA hash of the components if the trusted CA certificates
+// changed, which rolls out their pods to merge the new certificates.
+func (occ *OrchestClusterController) ensureTrustedCA(ctx context.Context,
+	orchest *orchestv1alpha1.OrchestCluster) error {
+
+	// Components are recreated with the current hash while the cluster is not running
+	if orchest.Spec.Orchest.TrustedCA == nil || orchest.Status == nil ||
+		orchest.Status.Phase != orchestv1alpha1.Running {
+		return nil
+	}
+
+	hash, err := occ.getTrustedCAHash(ctx, orchest)
+	if err != nil {
+		return err
+	}
+
+	components, err := GetOrchestComponents(ctx, orchest, occ.oComponentLister)
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		if component.Spec.TrustedCA == nil || component.Spec.TrustedCAHash == hash {
+			continue
+		}
+
+		klog.Infof("Trusted CA of OrchestCluster %s changed, rolling out %s", orchest.Name, component.Name)
+
+		component = component.DeepCopy()
+		component.Spec.TrustedCAHash = hash
+		_, err = occ.oClient.OrchestV1alpha1().OrchestComponents(orchest.Namespace).Update(ctx, component, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to roll out %s", component.Name)
+		}
+	}
+
+	return nil
+}
@@ -13,13 +13,11 @@ import (
 
 type AuthServerReconciler struct {
 	*OrchestComponentController
-	ingressClass string
 }
 
 func NewAuthServerReconciler(ctrl *OrchestComponentController) OrchestComponentReconciler {
 	return &AuthServerReconciler{
 		ctrl,
-		"",
 	}
 }
 
@@ -27,7 +25,7 @@ func (reconciler *AuthServerReconciler) Reconcile(ctx context.Context, component
 
 	hash := utils.ComputeHash(component)
 	matchLabels := controller.GetResourceMatchLables(controller.AuthServer, component)
-	metadata := controller.GetMetadata(controller.AuthServer, hash, component, OrchestComponentKind)
+	metadata := getComponentMetadata(controller.AuthServer, hash, component)
 	newDep := getAuthServerDeployment(metadata, matchLabels, component)
 
 	oldDep, err := reconciler.depLister.Deployments(component.Namespace).Get(component.Name)
@@ -57,21 +55,8 @@ func (reconciler *AuthServerReconciler) Reconcile(ctx context.Context, component
 		return err
 	}
 
-	if reconciler.ingressClass == "" {
-		reconciler.ingressClass, err = detectIngressClass(ctx, reconciler.Client())
-		if err != nil {
-			return err
-		}
-	}
-
-	_, err = reconciler.ingLister.Ingresses(component.Namespace).Get(component.Name)
+	_, err = reconciler.ensureIngress(ctx, metadata, "/login", false, false, component)
 	if err != nil {
-		if !kerrors.IsAlreadyExists(err) {
-			ing := getIngressManifest(metadata, "/login", reconciler.ingressClass, false, false, component)
-			_, err = reconciler.Client().NetworkingV1().Ingresses(component.Namespace).Create(ctx, ing, metav1.CreateOptions{})
-			reconciler.EnqueueAfter(component)
-			return err
-		}
 		return err
 	}
 
@@ -90,8 +75,8 @@ func (reconciler *AuthServerReconciler) Uninstall(ctx context.Context, component
 		return false, err
 	}
 
-	err = reconciler.Client().NetworkingV1().Ingresses(component.Namespace).Delete(ctx, component.Name, metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
+	err = reconciler.deleteIngress(ctx, component)
+	if err != nil {
 		return false, err
 	}
 
@@ -134,6 +119,11 @@ func getAuthServerDeployment(metadata metav1.ObjectMeta,
 		template.Spec.Containers[0].VolumeMounts = volumeMounts
 	}
 
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecretKey(component, &template.ObjectMeta, &template.Spec)
+	injectAuthentication(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metadata,
 		Spec: appsv1.DeploymentSpec{
@@ -0,0 +1,86 @@
+package orchestcomponent
+
+import (
+	"strings"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// The pod template annotation which rolls out the auth-server if the OIDC client changes
+	authenticationHashAnnotationKey = "orchest.io/authentication-hash"
+
+	defaultOIDCScopes        = []string{"profile", "email", "groups"}
+	defaultOIDCUsernameClaim = "preferred_username"
+	defaultOIDCGroupsClaim   = "groups"
+)
+
+// injectAuthentication configures the auth-server to sign in the users with the OIDC provider
+// of the component, the client credentials are read from the OIDC client Secret.
+func injectAuthentication(component *orchestv1alpha1.OrchestComponent, objectMeta *metav1.ObjectMeta,
+	podSpec *corev1.PodSpec) {
+
+	authentication := component.Spec.Authentication
+	if authentication == nil || authentication.OIDC == nil || len(podSpec.Containers) == 0 {
+		return
+	}
+
+	oidc := authentication.OIDC
+
+	objectMeta.Annotations = utils.CloneAndAddLabel(objectMeta.Annotations, map[string]string{
+		authenticationHashAnnotationKey: component.Spec.AuthenticationHash,
+	})
+
+	scopes := oidc.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultOIDCScopes
+	}
+
+	usernameClaim := oidc.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = defaultOIDCUsernameClaim
+	}
+
+	groupsClaim := oidc.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = defaultOIDCGroupsClaim
+	}
+
+	envVars := []corev1.EnvVar{
+		{Name: "ORCHEST_OIDC_ISSUER_URL", Value: oidc.IssuerURL},
+		{
+			Name: "ORCHEST_OIDC_CLIENT_ID",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: oidc.ClientSecret},
+					Key:                  controller.OIDCClientIDKey,
+				},
+			},
+		},
+		{
+			Name: "ORCHEST_OIDC_CLIENT_SECRET",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: oidc.ClientSecret},
+					Key:                  controller.OIDCClientSecretKey,
+				},
+			},
+		},
+		{Name: "ORCHEST_OIDC_SCOPES", Value: strings.Join(scopes, " ")},
+		{Name: "ORCHEST_OIDC_USERNAME_CLAIM", Value: usernameClaim},
+		{Name: "ORCHEST_OIDC_GROUPS_CLAIM", Value: groupsClaim},
+		{Name: "ORCHEST_OIDC_ALLOWED_GROUPS", Value: strings.Join(oidc.AllowedGroups, ",")},
+		{Name: "ORCHEST_OIDC_ADMIN_GROUP", Value: oidc.AdminGroup},
+	}
+
+	// The env vars are appended as MergeEnvVars drops the secret references
+	container := &podSpec.Containers[0]
+	for _, envVar := range envVars {
+		utils.RemoveEnvVariable(&container.Env, envVar.Name)
+		container.Env = append(container.Env, envVar)
+	}
+}
@@ -0,0 +1,72 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectAuthentication(t *testing.T) {
+
+	getPodSpec := func() corev1.PodSpec {
+		return corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "auth-server",
+					Env: []corev1.EnvVar{
+						{Name: "ORCHEST_OIDC_SCOPES", Value: "openid"},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("no oidc", func(t *testing.T) {
+		component := &orchestv1alpha1.OrchestComponent{}
+		objectMeta := metav1.ObjectMeta{}
+		podSpec := getPodSpec()
+
+		injectAuthentication(component, &objectMeta, &podSpec)
+		assert.Equal(t, getPodSpec(), podSpec)
+		assert.Empty(t, objectMeta.Annotations)
+	})
+
+	t.Run("oidc", func(t *testing.T) {
+		component := &orchestv1alpha1.OrchestComponent{}
+		component.Spec.Authentication = &orchestv1alpha1.AuthenticationSpec{
+			OIDC: &orchestv1alpha1.OIDCSpec{
+				IssuerURL:     "https://dex.example.com/dex",
+				ClientSecret:  "orchest-oidc",
+				AllowedGroups: []string{"data-science", "engineering"},
+				AdminGroup:    "admins",
+			},
+		}
+		component.Spec.AuthenticationHash = "abc"
+		objectMeta := metav1.ObjectMeta{}
+		podSpec := getPodSpec()
+
+		injectAuthentication(component, &objectMeta, &podSpec)
+
+		assert.Equal(t, "abc", objectMeta.Annotations[authenticationHashAnnotationKey])
+
+		env := podSpec.Containers[0].Env
+		assert.Equal(t, "https://dex.example.com/dex", utils.GetKeyFromEnvVar(env, "ORCHEST_OIDC_ISSUER_URL"))
+		assert.Equal(t, "profile email groups", utils.GetKeyFromEnvVar(env, "ORCHEST_OIDC_SCOPES"))
+		assert.Equal(t, "preferred_username", utils.GetKeyFromEnvVar(env, "ORCHEST_OIDC_USERNAME_CLAIM"))
+		assert.Equal(t, "data-science,engineering", utils.GetKeyFromEnvVar(env, "ORCHEST_OIDC_ALLOWED_GROUPS"))
+		assert.Equal(t, "admins", utils.GetKeyFromEnvVar(env, "ORCHEST_OIDC_ADMIN_GROUP"))
+
+		var secretRefs int
+		for _, envVar := range env {
+			if envVar.ValueFrom != nil && envVar.ValueFrom.SecretKeyRef.Name == "orchest-oidc" {
+				secretRefs++
+			}
+		}
+		assert.Equal(t, 2, secretRefs)
+		assert.Len(t, env, 8)
+	})
+}
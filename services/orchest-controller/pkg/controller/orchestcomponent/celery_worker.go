@@ -25,7 +25,7 @@ func (reconciler *CeleryWorkerReconciler) Reconcile(ctx context.Context, compone
 
 	hash := utils.ComputeHash(component)
 	matchLabels := controller.GetResourceMatchLables(controller.CeleryWorker, component)
-	metadata := controller.GetMetadata(controller.CeleryWorker, hash, component, OrchestComponentKind)
+	metadata := getComponentMetadata(controller.CeleryWorker, hash, component)
 	newDep := getCeleryWorkerDeployment(metadata, matchLabels, component)
 
 	oldDep, err := reconciler.depLister.Deployments(component.Namespace).Get(component.Name)
@@ -76,59 +76,52 @@ func getCeleryWorkerDeployment(metadata metav1.ObjectMeta,
 
 	image := component.Spec.Template.Image
 
+	volumes := []corev1.Volume{
+		{
+			Name: controller.UserDirName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: getResourceName(component, controller.UserDirName),
+					ReadOnly:  false,
+				},
+			},
+		},
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      controller.UserDirName,
+			MountPath: controller.UserdirMountPath,
+		},
+	}
+
+	registryVolumes, registryVolumeMounts := getRegistryVolumes(component,
+		"/usr/lib/ssl/certs/additional-ca-cert-bundle.crt", "additional-ca-cert-bundle.crt")
+	volumes = append(volumes, registryVolumes...)
+	volumeMounts = append(volumeMounts, registryVolumeMounts...)
+
 	template := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels: matchLabels,
 		},
 		Spec: corev1.PodSpec{
-			ServiceAccountName: controller.CeleryWorker,
-			Volumes: []corev1.Volume{
-				{
-					Name: controller.UserDirName,
-					VolumeSource: corev1.VolumeSource{
-						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-							ClaimName: controller.UserDirName,
-							ReadOnly:  false,
-						},
-					},
-				},
-				{
-					Name: "tls-secret",
-					VolumeSource: corev1.VolumeSource{
-						Secret: &corev1.SecretVolumeSource{
-							SecretName: "registry-tls-secret",
-							Items: []corev1.KeyToPath{
-								{
-									Key:  "ca.crt",
-									Path: "additional-ca-cert-bundle.crt",
-								},
-							},
-						},
-					},
-				},
-			},
+			ServiceAccountName: getResourceName(component, controller.CeleryWorker),
+			Volumes:            volumes,
 			Containers: []corev1.Container{
 				{
 					Name:            controller.CeleryWorker,
 					Image:           image,
-					Env:             component.Spec.Template.Env,
+					Env:             utils.MergeEnvVars(component.Spec.Template.Env, getRegistryEnvVars(component)),
 					ImagePullPolicy: corev1.PullIfNotPresent,
-					VolumeMounts: []corev1.VolumeMount{
-						{
-							Name:      controller.UserDirName,
-							MountPath: controller.UserdirMountPath,
-						},
-						{
-							Name:      "tls-secret",
-							MountPath: "/usr/lib/ssl/certs/additional-ca-cert-bundle.crt",
-							SubPath:   "additional-ca-cert-bundle.crt",
-							ReadOnly:  true,
-						},
-					},
+					VolumeMounts:    volumeMounts,
 				},
 			},
 		},
 	}
+
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metadata,
 		Spec: appsv1.DeploymentSpec{
@@ -16,6 +16,7 @@ import (
 	netsv1 "k8s.io/api/networking/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
@@ -58,6 +59,8 @@ type OrchestComponentController struct {
 
 	ingLister netslister.IngressLister
 
+	nodeLister corelister.NodeLister
+
 	oComponentLister orchestlisters.OrchestComponentLister
 
 	reconcilers map[string]OrchestComponentReconciler
@@ -73,6 +76,7 @@ func NewOrchestComponentController(kClient kubernetes.Interface,
 	depInformer appsinformers.DeploymentInformer,
 	dsInformer appsinformers.DaemonSetInformer,
 	ingInformer netsinformers.IngressInformer,
+	nodeInformer coreinformers.NodeInformer,
 
 ) *OrchestComponentController {
 
@@ -141,6 +145,14 @@ func NewOrchestComponentController(kClient kubernetes.Interface,
 	informerSyncedList = append(informerSyncedList, ingInformer.Informer().HasSynced)
 	occ.ingLister = ingInformer.Lister()
 
+	// Node event handlers, the node-agents are reconciled if the container runtimes change
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: occ.updateNode,
+		DeleteFunc: occ.deleteNode,
+	})
+	informerSyncedList = append(informerSyncedList, nodeInformer.Informer().HasSynced)
+	occ.nodeLister = nodeInformer.Lister()
+
 	ctrl.InformerSyncedList = informerSyncedList
 	ctrl.SyncHandler = occ.syncOrchestComponent
 	ctrl.ControleeGetter = occ.getOrchestComponent
@@ -155,6 +167,7 @@ func NewOrchestComponentController(kClient kubernetes.Interface,
 	occ.reconcilers[controller.AuthServer] = NewAuthServerReconciler(&occ)
 	occ.reconcilers[controller.OrchestWebserver] = NewOrchestWebServerReconciler(&occ)
 	occ.reconcilers[controller.NodeAgent] = NewNodeAgentReconciler(&occ)
+	occ.reconcilers[controller.OrchestProxy] = NewOrchestProxyReconciler(&occ)
 
 	return &occ
 }
@@ -204,6 +217,40 @@ func (occ *OrchestComponentController) deleteOrchestComponent(obj interface{}) {
 	occ.Enqueue(oc)
 }
 
+// updateNode reconciles the node-agents if the container runtime label of the node changed, a
+// node-agent is deployed for a container runtime once the first node is labeled with it.
+func (occ *OrchestComponentController) updateNode(old, cur interface{}) {
+	oldNode := old.(*corev1.Node)
+	curNode := cur.(*corev1.Node)
+
+	if oldNode.Labels[controller.ContainerRuntimeLabelKey] == curNode.Labels[controller.ContainerRuntimeLabelKey] {
+		return
+	}
+
+	klog.V(4).Infof("Container runtime label of node %s changed", curNode.Name)
+	occ.enqueueNodeAgents()
+}
+
+// deleteNode reconciles the node-agents, the node-agent of a container runtime no node runs
+// anymore is removed
+func (occ *OrchestComponentController) deleteNode(obj interface{}) {
+	occ.enqueueNodeAgents()
+}
+
+func (occ *OrchestComponentController) enqueueNodeAgents() {
+	components, err := occ.oComponentLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't list OrchestComponents: %v", err))
+		return
+	}
+
+	for _, component := range components {
+		if component.Spec.ReconcilerName == controller.NodeAgent {
+			occ.Enqueue(component)
+		}
+	}
+}
+
 func (occ *OrchestComponentController) getOrchestComponent(namespace, name string) (
 	interface{}, error) {
 	return occ.oComponentLister.OrchestComponents(namespace).Get(name)
@@ -260,9 +307,9 @@ func (occ *OrchestComponentController) syncOrchestComponent(ctx context.Context,
 func (occ *OrchestComponentController) manageOrchestComponent(ctx context.Context,
 	component *orchestv1alpha1.OrchestComponent) (err error) {
 
-	reconciler, ok := occ.reconcilers[component.Name]
+	reconciler, ok := occ.reconcilers[getReconcilerName(component)]
 	if !ok {
-		return errors.Errorf("unrecognized component reconciler name : %s", component.Name)
+		return errors.Errorf("unrecognized component reconciler name : %s", getReconcilerName(component))
 	}
 
 	return reconciler.Reconcile(ctx, component)
@@ -272,14 +319,23 @@ func (occ *OrchestComponentController) manageOrchestComponent(ctx context.Contex
 func (occ *OrchestComponentController) uninstallOrchestComponent(ctx context.Context,
 	component *orchestv1alpha1.OrchestComponent) (bool, error) {
 
-	reconciler, ok := occ.reconcilers[component.Name]
+	reconciler, ok := occ.reconcilers[getReconcilerName(component)]
 	if !ok {
-		return false, errors.Errorf("unrecognized component reconciler name : %s", component.Name)
+		return false, errors.Errorf("unrecognized component reconciler name : %s", getReconcilerName(component))
 	}
 
 	return reconciler.Uninstall(ctx, component)
 }
 
+// getReconcilerName returns the name of the reconciler of the component, components created by
+// previous versions of the controller are named after their reconciler
+func getReconcilerName(component *orchestv1alpha1.OrchestComponent) string {
+	if component.Spec.ReconcilerName != "" {
+		return component.Spec.ReconcilerName
+	}
+	return component.Name
+}
+
 func (occ *OrchestComponentController) updatePhase(ctx context.Context,
 	component *orchestv1alpha1.OrchestComponent,
 	phase orchestv1alpha1.OrchestPhase) error {
@@ -2,7 +2,6 @@ package orchestcomponent
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
@@ -11,6 +10,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netsv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -19,6 +19,27 @@ var (
 	DeletePropagationForeground = metav1.DeletionPropagation("Foreground")
 )
 
+// getResourceName returns the name of an object of the OrchestCluster of the component.
+// Components created by previous versions of the controller have no reconciler name and
+// use the names of the objects of their time, which are not prefixed with the cluster name.
+func getResourceName(component *orchestv1alpha1.OrchestComponent, name string) string {
+	owner := metav1.GetControllerOf(component)
+	if component.Spec.ReconcilerName == "" || owner == nil {
+		return name
+	}
+	return controller.GetResourceName(owner.Name, name)
+}
+
+// getComponentMetadata returns the metadata of the objects of the component, which are named
+// after the component and labeled with the name of the component type
+func getComponentMetadata(name, hash string,
+	component *orchestv1alpha1.OrchestComponent) metav1.ObjectMeta {
+
+	metadata := controller.GetMetadata(name, hash, component, OrchestComponentKind)
+	metadata.Name = component.Name
+	return metadata
+}
+
 func getServiceManifest(metadata metav1.ObjectMeta,
 	matchLabels map[string]string, port int,
 	component *orchestv1alpha1.OrchestComponent) *corev1.Service {
@@ -98,7 +119,7 @@ func getDevVolumes(service string, mountApp, mountClient, mountInternalLib bool)
 	return volumes, volumeMounts
 }
 
-func detectIngressClass(ctx context.Context, client kubernetes.Interface) (string, error) {
+func detectIngressClass(ctx context.Context, client kubernetes.Interface, ingressController string) (string, error) {
 
 	// Detect ingress class name
 	ingressClasses, err := client.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
@@ -107,12 +128,12 @@ func detectIngressClass(ctx context.Context, client kubernetes.Interface) (strin
 	}
 
 	for _, ingressClass := range ingressClasses.Items {
-		if ingressClass.Spec.Controller == controller.IngressClassController {
+		if ingressClass.Spec.Controller == ingressController {
 			return ingressClass.Name, nil
 		}
 	}
 
-	return "", errors.New("failed to detect ingress class name")
+	return "", fmt.Errorf("failed to detect ingress class name, no IngressClass with controller %s exists", ingressController)
 
 }
 
@@ -128,22 +149,32 @@ func getIngressManifest(metadata metav1.ObjectMeta, path, ingressClass string,
 		}
 	}
 
-	if enableAuth {
-		authServiceName := fmt.Sprintf("http://auth-server.%s.svc.cluster.local/auth", component.Namespace)
-		ingressMeta.Annotations["nginx.ingress.kubernetes.io/auth-url"] = authServiceName
+	provider := getIngressProvider(component)
 
-	}
+	if provider == orchestv1alpha1.TraefikIngressProvider {
+		// The proxy-body-size annotation is nginx specific, so it is not set by default
+		delete(ingressMeta.Annotations, "nginx.ingress.kubernetes.io/proxy-body-size")
 
-	if enableSignin {
-		ingressMeta.Annotations["nginx.ingress.kubernetes.io/auth-signin"] = "/login"
-	}
+		if enableAuth {
+			// The ForwardAuth Middleware of the component, see getForwardAuthMiddleware
+			ingressMeta.Annotations[traefikMiddlewaresAnnotationKey] = fmt.Sprintf("%s-%s@kubernetescrd",
+				component.Namespace, metadata.Name)
+		}
+
+		if component.Spec.TLS != nil {
+			ingressMeta.Annotations[traefikTLSAnnotationKey] = "true"
+		}
+	} else {
+		if enableAuth {
+			ingressMeta.Annotations["nginx.ingress.kubernetes.io/auth-url"] = getAuthURL(component)
+		}
 
-	rule := netsv1.IngressRule{}
-	if component.Spec.OrchestHost != nil {
-		rule.Host = *component.Spec.OrchestHost
+		if enableSignin {
+			ingressMeta.Annotations["nginx.ingress.kubernetes.io/auth-signin"] = getSigninURL(component)
+		}
 	}
 
-	rule.HTTP = &netsv1.HTTPIngressRuleValue{
+	ruleValue := netsv1.HTTPIngressRuleValue{
 		Paths: []netsv1.HTTPIngressPath{
 			{
 				Path:     path,
@@ -160,19 +191,70 @@ func getIngressManifest(metadata metav1.ObjectMeta, path, ingressClass string,
 		},
 	}
 
+	// A rule per host, or a single rule matching all hosts
+	hosts := getOrchestHosts(component)
+	rules := make([]netsv1.IngressRule, 0, len(hosts))
+	for _, host := range hosts {
+		rules = append(rules, netsv1.IngressRule{
+			Host:             host,
+			IngressRuleValue: netsv1.IngressRuleValue{HTTP: ruleValue.DeepCopy()},
+		})
+	}
+	if len(rules) == 0 {
+		rules = append(rules, netsv1.IngressRule{
+			IngressRuleValue: netsv1.IngressRuleValue{HTTP: &ruleValue},
+		})
+	}
+
 	ingress := &netsv1.Ingress{
 		ObjectMeta: ingressMeta,
 		Spec: netsv1.IngressSpec{
 			IngressClassName: &ingressClass,
-			Rules: []netsv1.IngressRule{
-				rule,
-			},
+			Rules:            rules,
 		},
 	}
 
+	if tls := component.Spec.TLS; tls != nil {
+		// Without hosts the certificate is the default certificate of the ingress rules
+		ingress.Spec.TLS = []netsv1.IngressTLS{
+			{
+				SecretName: tls.SecretName,
+				Hosts:      hosts,
+			},
+		}
+
+		if !tls.DisableRedirect && provider == orchestv1alpha1.NginxIngressProvider {
+			ingressMeta.Annotations["nginx.ingress.kubernetes.io/ssl-redirect"] = "true"
+			ingressMeta.Annotations["nginx.ingress.kubernetes.io/force-ssl-redirect"] = "true"
+		}
+	}
+
+	if component.Spec.Ingress != nil {
+		for key, value := range component.Spec.Ingress.Annotations {
+			ingressMeta.Annotations[key] = value
+		}
+	}
+
 	return ingress
 }
 
+// getOrchestHosts returns the OrchestHost of the component followed by its aliases
+func getOrchestHosts(component *orchestv1alpha1.OrchestComponent) []string {
+	host := component.Spec.OrchestHost
+	if host == nil || *host == "" {
+		return nil
+	}
+
+	hosts := []string{*host}
+	for _, alias := range component.Spec.OrchestHostAliases {
+		if !utils.Contains(hosts, alias) {
+			hosts = append(hosts, alias)
+		}
+	}
+
+	return hosts
+}
+
 func isDeploymentUpdated(newDep *appsv1.Deployment, oldDep *appsv1.Deployment) bool {
 	if oldHash, _ := oldDep.Labels[controller.DeploymentHashLabelKey]; oldHash == utils.ComputeHash(&newDep.Spec) {
 		return true
@@ -180,6 +262,13 @@ func isDeploymentUpdated(newDep *appsv1.Deployment, oldDep *appsv1.Deployment) b
 	return false
 }
 
+func isDaemonSetUpdated(newDs *appsv1.DaemonSet, oldDs *appsv1.DaemonSet) bool {
+	if oldHash, _ := oldDs.Labels[controller.DeploymentHashLabelKey]; oldHash == utils.ComputeHash(&newDs.Spec) {
+		return true
+	}
+	return false
+}
+
 // isServiceReady aims to check if the service is reachable or not
 func isServiceReady(ctx context.Context, client kubernetes.Interface, service *corev1.Service) bool {
 	ep, err := client.CoreV1().Endpoints(service.Namespace).Get(ctx, service.Name, metav1.GetOptions{})
@@ -195,6 +284,18 @@ func isDeploymentReady(dep *appsv1.Deployment) bool {
 	return dep.Spec.Replicas != nil && *dep.Spec.Replicas == dep.Status.ReadyReplicas
 }
 
+// isIngressUpdated returns true if the rules, the TLS and the annotations of the old ingress are
+// the desired ones, the annotations added by others are retained
+func isIngressUpdated(newIng, oldIng *netsv1.Ingress) bool {
+	for key, value := range newIng.Annotations {
+		if oldValue, ok := oldIng.Annotations[key]; !ok || oldValue != value {
+			return false
+		}
+	}
+
+	return equality.Semantic.DeepEqual(newIng.Spec, oldIng.Spec)
+}
+
 // isIngressReady checks fore readiness of the ingress
 func isIngressReady(ing *netsv1.Ingress) bool {
 	return len(ing.Status.LoadBalancer.Ingress) > 0
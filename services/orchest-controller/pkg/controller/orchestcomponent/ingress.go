@@ -0,0 +1,409 @@
+package orchestcomponent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	middlewareGVK = schema.GroupVersionKind{Group: "traefik.io", Version: "v1alpha1", Kind: "Middleware"}
+	httpRouteGVK  = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1beta1", Kind: "HTTPRoute"}
+
+	traefikMiddlewaresAnnotationKey = "traefik.ingress.kubernetes.io/router.middlewares"
+	traefikTLSAnnotationKey         = "traefik.ingress.kubernetes.io/router.tls"
+)
+
+// ensureIngress exposes the service of the component at the path under the base path, with an
+// Ingress or, if the provider is gateway, an HTTPRoute, and returns true once the ingress
+// controller admitted it. Existing ones are updated, e.g. when the hosts change. If enableAuth
+// is true the requests are authenticated by auth-server, and if enableSignin is true
+// unauthenticated requests are redirected to the login page. Nothing is created if the
+// component is exposed by the built-in proxy.
+func (occ *OrchestComponentController) ensureIngress(ctx context.Context, metadata metav1.ObjectMeta,
+	path string, enableAuth, enableSignin bool, component *orchestv1alpha1.OrchestComponent) (bool, error) {
+
+	if getExposureMode(component) != orchestv1alpha1.IngressExposureMode {
+		return true, nil
+	}
+
+	path = getIngressPath(component, path)
+	provider := getIngressProvider(component)
+
+	if enableAuth && provider == orchestv1alpha1.TraefikIngressProvider {
+		err := occ.ensureForwardAuthMiddleware(ctx, metadata, enableSignin, component)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if provider == orchestv1alpha1.GatewayIngressProvider {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(httpRouteGVK)
+		err := occ.gClient.Get(ctx, client.ObjectKey{Namespace: component.Namespace, Name: metadata.Name}, route)
+		if kerrors.IsNotFound(err) {
+			err = occ.createObject(ctx, getHTTPRouteManifest(metadata, path, enableAuth, component))
+			occ.EnqueueAfter(component)
+			return false, err
+		} else if err != nil {
+			return false, errors.Wrapf(err, "failed to get HTTPRoute %s", metadata.Name)
+		}
+
+		newRoute := getHTTPRouteManifest(metadata, path, enableAuth, component)
+		if !isHTTPRouteUpdated(newRoute, route) {
+			route.SetLabels(newRoute.GetLabels())
+			route.SetAnnotations(newRoute.GetAnnotations())
+			route.Object["spec"] = newRoute.Object["spec"]
+			err = occ.gClient.Update(ctx, route)
+			occ.EnqueueAfter(component)
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to update HTTPRoute %s", metadata.Name)
+			}
+			return false, nil
+		}
+
+		// The status of the HTTPRoutes is not watched
+		if !isHTTPRouteReady(route) {
+			occ.EnqueueAfter(component)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	ingressClass, err := getIngressClassName(ctx, occ.Client(), component)
+	if err != nil {
+		return false, err
+	}
+
+	newIng := getIngressManifest(metadata, path, ingressClass, enableAuth, enableSignin, component)
+
+	oldIng, err := occ.ingLister.Ingresses(component.Namespace).Get(metadata.Name)
+	if kerrors.IsNotFound(err) {
+		_, err = occ.Client().NetworkingV1().Ingresses(component.Namespace).Create(ctx, newIng, metav1.CreateOptions{})
+		occ.EnqueueAfter(component)
+		return false, err
+	} else if err != nil {
+		return false, err
+	}
+
+	if !isIngressUpdated(newIng, oldIng) {
+		ing := oldIng.DeepCopy()
+		ing.Labels = newIng.Labels
+		ing.Annotations = utils.CloneAndAddLabel(utils.CloneLabel(ing.Annotations), newIng.Annotations)
+		ing.Spec = newIng.Spec
+		_, err = occ.Client().NetworkingV1().Ingresses(component.Namespace).Update(ctx, ing, metav1.UpdateOptions{})
+		occ.EnqueueAfter(component)
+		return false, err
+	}
+
+	return isIngressReady(oldIng), nil
+}
+
+// deleteIngress deletes the Ingress, HTTPRoute and Middleware of the component, the resources
+// of the providers which are not installed are ignored
+func (occ *OrchestComponentController) deleteIngress(ctx context.Context,
+	component *orchestv1alpha1.OrchestComponent) error {
+
+	err := occ.Client().NetworkingV1().Ingresses(component.Namespace).Delete(ctx, component.Name, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	for _, gvk := range []schema.GroupVersionKind{httpRouteGVK, middlewareGVK} {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(gvk)
+		object.SetName(component.Name)
+		object.SetNamespace(component.Namespace)
+
+		err = occ.gClient.Delete(ctx, object)
+		if err != nil && !kerrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return errors.Wrapf(err, "failed to delete %s %s", gvk.Kind, component.Name)
+		}
+	}
+
+	return nil
+}
+
+// createObject creates the object if it does not exist yet
+// ensureForwardAuthMiddleware creates the Traefik Middleware authenticating the requests of the
+// component, an existing one is updated, e.g. when the base path changes
+func (occ *OrchestComponentController) ensureForwardAuthMiddleware(ctx context.Context,
+	metadata metav1.ObjectMeta, enableSignin bool, component *orchestv1alpha1.OrchestComponent) error {
+
+	newMiddleware := getForwardAuthMiddleware(metadata, enableSignin, component)
+
+	middleware := &unstructured.Unstructured{}
+	middleware.SetGroupVersionKind(middlewareGVK)
+	err := occ.gClient.Get(ctx, client.ObjectKey{Namespace: component.Namespace, Name: metadata.Name}, middleware)
+	if kerrors.IsNotFound(err) {
+		return occ.createObject(ctx, newMiddleware)
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get Middleware %s", metadata.Name)
+	}
+
+	if isMiddlewareUpdated(newMiddleware, middleware) {
+		return nil
+	}
+
+	middleware.SetLabels(newMiddleware.GetLabels())
+	middleware.Object["spec"] = newMiddleware.Object["spec"]
+	err = occ.gClient.Update(ctx, middleware)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Middleware %s", metadata.Name)
+	}
+
+	return nil
+}
+
+func (occ *OrchestComponentController) createObject(ctx context.Context, object client.Object) error {
+	err := occ.gClient.Create(ctx, object)
+	if err != nil && !kerrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create %s", object.GetName())
+	}
+	return nil
+}
+
+// getIngressClassName returns the IngressClass of the component, which is detected from the
+// IngressClasses of the provider if not specified. There is none if the provider is gateway,
+// or if the component is exposed by the built-in proxy.
+func getIngressClassName(ctx context.Context, client kubernetes.Interface,
+	component *orchestv1alpha1.OrchestComponent) (string, error) {
+
+	if ingress := component.Spec.Ingress; ingress != nil && ingress.ClassName != "" {
+		return ingress.ClassName, nil
+	}
+
+	if getExposureMode(component) != orchestv1alpha1.IngressExposureMode {
+		return "", nil
+	}
+
+	switch getIngressProvider(component) {
+	case orchestv1alpha1.GatewayIngressProvider:
+		return "", nil
+	case orchestv1alpha1.TraefikIngressProvider:
+		return detectIngressClass(ctx, client, controller.TraefikIngressClassController)
+	default:
+		return detectIngressClass(ctx, client, controller.IngressClassController)
+	}
+}
+
+func getIngressProvider(component *orchestv1alpha1.OrchestComponent) orchestv1alpha1.IngressProvider {
+	if ingress := component.Spec.Ingress; ingress != nil && ingress.Provider != "" {
+		return ingress.Provider
+	}
+	return orchestv1alpha1.NginxIngressProvider
+}
+
+// getAuthURL returns the URL of the auth endpoint of auth-server, it is the FQDN since the
+// ingress controller runs in a different namespace
+func getAuthURL(component *orchestv1alpha1.OrchestComponent) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local%s/auth",
+		getResourceName(component, controller.AuthServer), component.Namespace, component.Spec.BasePath)
+}
+
+// getSigninURL returns the path of the login page unauthenticated requests are redirected to
+func getSigninURL(component *orchestv1alpha1.OrchestComponent) string {
+	return component.Spec.BasePath + "/login"
+}
+
+// getIngressPath returns the path of the component under the base path, the root is the base
+// path itself so that the prefix matches it with and without the trailing slash
+func getIngressPath(component *orchestv1alpha1.OrchestComponent, path string) string {
+	if path == "/" && component.Spec.BasePath != "" {
+		return component.Spec.BasePath
+	}
+	return component.Spec.BasePath + path
+}
+
+// getForwardAuthMiddleware returns the Traefik Middleware authenticating the requests with
+// auth-server. Unlike nginx, Traefik returns the response of auth-server to the client, so
+// auth-server redirects unauthenticated requests to the login page if enableSignin is true.
+func getForwardAuthMiddleware(metadata metav1.ObjectMeta, enableSignin bool,
+	component *orchestv1alpha1.OrchestComponent) *unstructured.Unstructured {
+
+	address := getAuthURL(component)
+	if enableSignin {
+		address += "?signin=" + getSigninURL(component)
+	}
+
+	middleware := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"forwardAuth": map[string]interface{}{
+					"address": address,
+				},
+			},
+		},
+	}
+	setObjectMeta(middleware, middlewareGVK, metadata)
+
+	return middleware
+}
+
+// getHTTPRouteManifest returns the HTTPRoute of the Gateway API exposing the service of the
+// component at the path
+func getHTTPRouteManifest(metadata metav1.ObjectMeta, path string, enableAuth bool,
+	component *orchestv1alpha1.OrchestComponent) *unstructured.Unstructured {
+
+	gateway := component.Spec.Ingress.Gateway
+
+	parentRefs := make([]interface{}, 0, len(gateway.ParentRefs))
+	for _, parent := range gateway.ParentRefs {
+		parentRef := map[string]interface{}{
+			"name": parent.Name,
+		}
+		if parent.Namespace != "" {
+			parentRef["namespace"] = parent.Namespace
+		}
+		if parent.SectionName != "" {
+			parentRef["sectionName"] = parent.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	rule := map[string]interface{}{
+		"matches": []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  "PathPrefix",
+					"value": path,
+				},
+			},
+		},
+		"backendRefs": []interface{}{
+			map[string]interface{}{
+				"name": metadata.Name,
+				"port": int64(80),
+			},
+		},
+	}
+
+	// The auth filter is required by the validation of the OrchestCluster
+	if authFilter := gateway.AuthFilter; enableAuth && authFilter != nil {
+		rule["filters"] = []interface{}{
+			map[string]interface{}{
+				"type": "ExtensionRef",
+				"extensionRef": map[string]interface{}{
+					"group": authFilter.Group,
+					"kind":  authFilter.Kind,
+					"name":  authFilter.Name,
+				},
+			},
+		}
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": parentRefs,
+		"rules":      []interface{}{rule},
+	}
+	if hosts := getOrchestHosts(component); len(hosts) > 0 {
+		hostnames := make([]interface{}, 0, len(hosts))
+		for _, host := range hosts {
+			hostnames = append(hostnames, host)
+		}
+		spec["hostnames"] = hostnames
+	}
+
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	setObjectMeta(route, httpRouteGVK, metadata)
+	route.SetAnnotations(component.Spec.Ingress.Annotations)
+
+	return route
+}
+
+// isHTTPRouteUpdated returns true if the hostnames and the paths of the old HTTPRoute are the
+// desired ones, the rest of the spec is defaulted by the API server so it is not compared
+func isHTTPRouteUpdated(newRoute, oldRoute *unstructured.Unstructured) bool {
+	newHostnames, _, _ := unstructured.NestedStringSlice(newRoute.Object, "spec", "hostnames")
+	oldHostnames, _, _ := unstructured.NestedStringSlice(oldRoute.Object, "spec", "hostnames")
+	if !reflect.DeepEqual(newHostnames, oldHostnames) {
+		return false
+	}
+
+	return reflect.DeepEqual(getHTTPRoutePaths(newRoute), getHTTPRoutePaths(oldRoute))
+}
+
+// isMiddlewareUpdated returns true if the Middleware forwards the requests to the same address
+func isMiddlewareUpdated(newMiddleware, oldMiddleware *unstructured.Unstructured) bool {
+	newAddress, _, _ := unstructured.NestedString(newMiddleware.Object, "spec", "forwardAuth", "address")
+	oldAddress, _, _ := unstructured.NestedString(oldMiddleware.Object, "spec", "forwardAuth", "address")
+	return newAddress == oldAddress && reflect.DeepEqual(newMiddleware.GetLabels(), oldMiddleware.GetLabels())
+}
+
+// getHTTPRoutePaths returns the path values of the matches of the rules of the HTTPRoute
+func getHTTPRoutePaths(route *unstructured.Unstructured) []string {
+	var paths []string
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, rule := range rules {
+		rule, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		matches, _, _ := unstructured.NestedSlice(rule, "matches")
+		for _, match := range matches {
+			match, ok := match.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if path, ok, _ := unstructured.NestedString(match, "path", "value"); ok {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	return paths
+}
+
+// isHTTPRouteReady returns true if all parent Gateways accepted the HTTPRoute
+func isHTTPRouteReady(route *unstructured.Unstructured) bool {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	if len(parents) == 0 {
+		return false
+	}
+
+	for _, parent := range parents {
+		parentStatus, ok := parent.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		conditions, _, _ := unstructured.NestedSlice(parentStatus, "conditions")
+		accepted := false
+		for _, condition := range conditions {
+			condition, ok := condition.(map[string]interface{})
+			if ok && condition["type"] == "Accepted" && condition["status"] == string(metav1.ConditionTrue) {
+				accepted = true
+			}
+		}
+		if !accepted {
+			return false
+		}
+	}
+
+	return true
+}
+
+func setObjectMeta(object *unstructured.Unstructured, gvk schema.GroupVersionKind,
+	metadata metav1.ObjectMeta) {
+	object.SetGroupVersionKind(gvk)
+	object.SetName(metadata.Name)
+	object.SetNamespace(metadata.Namespace)
+	object.SetLabels(metadata.Labels)
+	object.SetOwnerReferences(metadata.OwnerReferences)
+}
@@ -0,0 +1,189 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getIngressTestComponent(ingress *orchestv1alpha1.IngressSpec) *orchestv1alpha1.OrchestComponent {
+	host := "orchest.example.com"
+	component := &orchestv1alpha1.OrchestComponent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "orchest-webserver",
+			Namespace: "orchest",
+		},
+	}
+	component.Spec.OrchestHost = &host
+	component.Spec.Ingress = ingress
+	return component
+}
+
+func TestGetIngressManifest(t *testing.T) {
+
+	testCases := []struct {
+		name                string
+		ingress             *orchestv1alpha1.IngressSpec
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:    "nginx",
+			ingress: nil,
+			expectedAnnotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-body-size": "0",
+				"nginx.ingress.kubernetes.io/auth-url":        "http://auth-server.orchest.svc.cluster.local/auth",
+				"nginx.ingress.kubernetes.io/auth-signin":     "/login",
+			},
+		},
+		{
+			name: "traefik",
+			ingress: &orchestv1alpha1.IngressSpec{
+				Provider: orchestv1alpha1.TraefikIngressProvider,
+			},
+			expectedAnnotations: map[string]string{
+				traefikMiddlewaresAnnotationKey: "orchest-orchest-webserver@kubernetescrd",
+			},
+		},
+		{
+			name: "extra annotations",
+			ingress: &orchestv1alpha1.IngressSpec{
+				Provider: orchestv1alpha1.TraefikIngressProvider,
+				Annotations: map[string]string{
+					traefikMiddlewaresAnnotationKey: "orchest-custom@kubernetescrd",
+					"example.com/key":               "value",
+				},
+			},
+			expectedAnnotations: map[string]string{
+				traefikMiddlewaresAnnotationKey: "orchest-custom@kubernetescrd",
+				"example.com/key":               "value",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			component := getIngressTestComponent(test.ingress)
+			metadata := metav1.ObjectMeta{Name: component.Name, Namespace: component.Namespace}
+
+			ingress := getIngressManifest(metadata, "/", "class", true, true, component)
+
+			assert.Equal(t, test.expectedAnnotations, ingress.Annotations)
+			assert.Equal(t, "class", *ingress.Spec.IngressClassName)
+			assert.Equal(t, "orchest.example.com", ingress.Spec.Rules[0].Host)
+		})
+	}
+}
+
+func TestGetHTTPRouteManifest(t *testing.T) {
+
+	component := getIngressTestComponent(&orchestv1alpha1.IngressSpec{
+		Provider: orchestv1alpha1.GatewayIngressProvider,
+		Gateway: &orchestv1alpha1.GatewaySpec{
+			ParentRefs: []orchestv1alpha1.GatewayReference{
+				{Name: "gateway", Namespace: "gateways"},
+			},
+			AuthFilter: &orchestv1alpha1.ExtensionReference{
+				Group: "gateway.envoyproxy.io",
+				Kind:  "SecurityPolicy",
+				Name:  "orchest-auth",
+			},
+		},
+	})
+	metadata := metav1.ObjectMeta{Name: component.Name, Namespace: component.Namespace}
+
+	route := getHTTPRouteManifest(metadata, "/", true, component)
+
+	assert.Equal(t, "HTTPRoute", route.GetKind())
+	assert.Equal(t, []interface{}{"orchest.example.com"}, route.Object["spec"].(map[string]interface{})["hostnames"])
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	filters := rules[0].(map[string]interface{})["filters"].([]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"group": "gateway.envoyproxy.io",
+		"kind":  "SecurityPolicy",
+		"name":  "orchest-auth",
+	}, filters[0].(map[string]interface{})["extensionRef"])
+
+	// The requests of the unauthenticated paths are not filtered
+	rules, _, _ = unstructured.NestedSlice(getHTTPRouteManifest(metadata, "/", false, component).Object,
+		"spec", "rules")
+	assert.NotContains(t, rules[0].(map[string]interface{}), "filters")
+
+	middleware := getForwardAuthMiddleware(metadata, true, component)
+	address, _, _ := unstructured.NestedString(middleware.Object, "spec", "forwardAuth", "address")
+	assert.Equal(t, "http://auth-server.orchest.svc.cluster.local/auth?signin=/login", address)
+	assert.True(t, isMiddlewareUpdated(middleware, middleware.DeepCopy()))
+
+	// The Middleware is updated when the base path changes
+	basePathComponent := component.DeepCopy()
+	basePathComponent.Spec.BasePath = "/orchest"
+	assert.False(t, isMiddlewareUpdated(getForwardAuthMiddleware(metadata, true, basePathComponent), middleware))
+
+	assert.False(t, isHTTPRouteReady(route))
+	route.Object["status"] = map[string]interface{}{
+		"parents": []interface{}{
+			map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Accepted", "status": "True"},
+				},
+			},
+		},
+	}
+	assert.True(t, isHTTPRouteReady(route))
+}
+
+func TestGetIngressManifestBasePath(t *testing.T) {
+
+	component := getIngressTestComponent(nil)
+	component.Spec.BasePath = "/orchest"
+	metadata := metav1.ObjectMeta{Name: component.Name, Namespace: component.Namespace}
+
+	assert.Equal(t, "/orchest", getIngressPath(component, "/"))
+	assert.Equal(t, "/orchest/orchest-api", getIngressPath(component, "/orchest-api"))
+
+	ingress := getIngressManifest(metadata, getIngressPath(component, "/login"), "class", true, true, component)
+
+	assert.Equal(t, "/orchest/login", ingress.Spec.Rules[0].HTTP.Paths[0].Path)
+	assert.Equal(t, "http://auth-server.orchest.svc.cluster.local/orchest/auth",
+		ingress.Annotations["nginx.ingress.kubernetes.io/auth-url"])
+	assert.Equal(t, "/orchest/login", ingress.Annotations["nginx.ingress.kubernetes.io/auth-signin"])
+}
+
+func TestIngressHosts(t *testing.T) {
+
+	component := getIngressTestComponent(nil)
+	component.Spec.OrchestHostAliases = []string{"orchest.example.org", "orchest.example.com"}
+	component.Spec.TLS = &orchestv1alpha1.TLSSpec{SecretName: "orchest-tls"}
+	metadata := metav1.ObjectMeta{Name: component.Name, Namespace: component.Namespace}
+
+	ingress := getIngressManifest(metadata, "/", "class", true, true, component)
+
+	assert.Len(t, ingress.Spec.Rules, 2)
+	assert.Equal(t, "orchest.example.org", ingress.Spec.Rules[1].Host)
+	assert.Equal(t, []string{"orchest.example.com", "orchest.example.org"}, ingress.Spec.TLS[0].Hosts)
+
+	// Annotations added by others are retained
+	oldIngress := ingress.DeepCopy()
+	oldIngress.Annotations["example.com/key"] = "value"
+	assert.True(t, isIngressUpdated(ingress, oldIngress))
+
+	// The aliases are removed
+	component.Spec.OrchestHostAliases = nil
+	assert.False(t, isIngressUpdated(getIngressManifest(metadata, "/", "class", true, true, component), oldIngress))
+
+	component.Spec.Ingress = &orchestv1alpha1.IngressSpec{
+		Provider: orchestv1alpha1.GatewayIngressProvider,
+		Gateway: &orchestv1alpha1.GatewaySpec{
+			ParentRefs: []orchestv1alpha1.GatewayReference{{Name: "gateway"}},
+		},
+	}
+	route := getHTTPRouteManifest(metadata, "/", true, component)
+	assert.True(t, isHTTPRouteUpdated(route, route.DeepCopy()))
+
+	host := "orchest.example.net"
+	component.Spec.OrchestHost = &host
+	assert.False(t, isHTTPRouteUpdated(getHTTPRouteManifest(metadata, "/", true, component), route))
+}
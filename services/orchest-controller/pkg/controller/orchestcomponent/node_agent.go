@@ -7,11 +7,28 @@ import (
 	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
 	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
 	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var (
+	// The host directories the container runtimes read the registry certificates from
+	containerRuntimeCertsDirectories = map[string]string{
+		"docker": "/etc/docker/certs.d",
+		"cri-o":  "/etc/containers/certs.d",
+	}
+)
+
+var (
+	defaultImagePullerInterval int32 = 60
+	defaultImagePullerRetries  int32 = 3
+	defaultImagePullerPolicy         = corev1.PullIfNotPresent
+	defaultImagePullerLogLevel       = "INFO"
 )
 
 type NodeAgentReconciler struct {
@@ -26,47 +43,136 @@ func NewNodeAgentReconciler(ctrl *OrchestComponentController) OrchestComponentRe
 
 func (reconciler *NodeAgentReconciler) Reconcile(ctx context.Context, component *orchestv1alpha1.OrchestComponent) error {
 
-	// first retrive the registry IP
-	registryService, err := reconciler.Client().CoreV1().Services(component.Namespace).Get(ctx, addons.DockerRegistry, metav1.GetOptions{})
+	// first retrive the registry IP, if the in-cluster registry is used
+	registryIP := ""
+	if component.Spec.ImageRegistry == nil {
+		registryService, err := reconciler.Client().CoreV1().Services(component.Namespace).Get(ctx, addons.DockerRegistry, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		registryIP = registryService.Spec.ClusterIP
+	}
+
+	nodes, err := reconciler.nodeLister.List(labels.Everything())
 	if err != nil {
-		return err
+		return errors.Wrap(err, "failed to get node list")
 	}
 
-	registryIP := registryService.Spec.ClusterIP
+	// A node-agent is deployed for each container runtime of the cluster, on the nodes
+	// labeled with the runtime.
+	runtimes := controller.DetectContainerRuntimes(nodes)
+	primaryRuntime := utils.GetKeyFromEnvVar(component.Spec.Template.Env, "CONTAINER_RUNTIME")
+	primarySocket := utils.GetKeyFromEnvVar(component.Spec.Template.Env, "CONTAINER_RUNTIME_SOCKET")
 
 	hash := utils.ComputeHash(component)
-	matchLabels := controller.GetResourceMatchLables(controller.NodeAgent, component)
-	metadata := controller.GetMetadata(controller.NodeAgent, hash, component, OrchestComponentKind)
-	newDs, err := getNodeAgentDaemonset(registryIP, metadata, matchLabels, component)
-	if err != nil {
-		return err
-	}
+	daemonSets := make(map[string]bool, len(runtimes))
+	updated := true
+	for _, runtime := range runtimes {
+		if runtime.Name == primaryRuntime && primarySocket != "" {
+			runtime.SocketPath = primarySocket
+		}
 
-	_, err = reconciler.dsLister.DaemonSets(component.Namespace).Get(component.Name)
-	if err != nil {
-		if !kerrors.IsAlreadyExists(err) {
-			_, err = reconciler.Client().AppsV1().DaemonSets(component.Namespace).Create(ctx, newDs, metav1.CreateOptions{})
-			reconciler.EnqueueAfter(component)
+		name := getNodeAgentName(component, runtime.Name)
+		daemonSets[name] = true
+
+		runtimeLabel := map[string]string{
+			controller.ContainerRuntimeLabelKey: runtime.Name,
+		}
+
+		matchLabels := utils.CloneAndAddLabel(
+			controller.GetResourceMatchLables(controller.NodeAgent, component), runtimeLabel)
+		metadata := controller.GetMetadata(controller.NodeAgent, hash, component, OrchestComponentKind)
+		metadata.Name = name
+		metadata.Labels = utils.CloneAndAddLabel(metadata.Labels, runtimeLabel)
+
+		newDs, err := getNodeAgentDaemonset(registryIP, runtime, metadata, matchLabels, component)
+		if err != nil {
 			return err
 		}
+
+		oldDs, err := reconciler.dsLister.DaemonSets(component.Namespace).Get(name)
+		if err != nil {
+			if !kerrors.IsNotFound(err) {
+				return err
+			}
+
+			_, err = reconciler.Client().AppsV1().DaemonSets(component.Namespace).Create(ctx, newDs, metav1.CreateOptions{})
+			if err != nil {
+				return err
+			}
+			updated = false
+			continue
+		}
+
+		if !isDaemonSetUpdated(newDs, oldDs) {
+			_, err := reconciler.Client().AppsV1().DaemonSets(component.Namespace).Update(ctx, newDs, metav1.UpdateOptions{})
+			if err != nil {
+				return err
+			}
+			updated = false
+		}
+	}
+
+	// Remove the node-agents of container runtimes no node runs anymore
+	err = reconciler.deleteNodeAgents(ctx, component, func(name string) bool {
+		return !daemonSets[name]
+	})
+	if err != nil {
 		return err
 	}
 
+	if !updated {
+		reconciler.EnqueueAfter(component)
+		return nil
+	}
+
 	return reconciler.updatePhase(ctx, component, orchestv1alpha1.Running)
 
 }
 
 func (reconciler *NodeAgentReconciler) Uninstall(ctx context.Context, component *orchestv1alpha1.OrchestComponent) (bool, error) {
 
-	err := reconciler.Client().AppsV1().DaemonSets(component.Namespace).Delete(ctx, component.Name, metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
+	err := reconciler.deleteNodeAgents(ctx, component, func(string) bool {
+		return true
+	})
+	if err != nil {
 		return false, err
 	}
 
 	return true, nil
 }
 
-func getNodeAgentDaemonset(registryIP string, metadata metav1.ObjectMeta,
+// deleteNodeAgents deletes the node-agent DaemonSets of the component selected by the filter
+func (reconciler *NodeAgentReconciler) deleteNodeAgents(ctx context.Context,
+	component *orchestv1alpha1.OrchestComponent, filter func(name string) bool) error {
+
+	selector := labels.SelectorFromSet(controller.GetResourceMatchLables(controller.NodeAgent, component))
+	daemonSets, err := reconciler.dsLister.DaemonSets(component.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+
+	for _, daemonSet := range daemonSets {
+		if !metav1.IsControlledBy(daemonSet, component) || !filter(daemonSet.Name) {
+			continue
+		}
+
+		err = reconciler.Client().AppsV1().DaemonSets(component.Namespace).Delete(ctx, daemonSet.Name, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete DaemonSet %s", daemonSet.Name)
+		}
+	}
+
+	return nil
+}
+
+// getNodeAgentName returns the name of the node-agent DaemonSet of the container runtime
+func getNodeAgentName(component *orchestv1alpha1.OrchestComponent, runtime string) string {
+	return component.Name + "-" + runtime
+}
+
+func getNodeAgentDaemonset(registryIP string, runtime controller.ContainerRuntime, metadata metav1.ObjectMeta,
 	matchLabels map[string]string, component *orchestv1alpha1.OrchestComponent) (
 	*appsv1.DaemonSet, error) {
 
@@ -74,10 +180,14 @@ func getNodeAgentDaemonset(registryIP string, metadata metav1.ObjectMeta,
 
 	var one int64 = 1
 
-	socketPath := utils.GetKeyFromEnvVar(component.Spec.Template.Env, "CONTAINER_RUNTIME_SOCKET")
+	socketPath := runtime.SocketPath
 	hostPathSocket := corev1.HostPathType("Socket")
 
-	containerRuntime := utils.GetKeyFromEnvVar(component.Spec.Template.Env, "CONTAINER_RUNTIME")
+	// The runtime of the node-agent may differ from the one of sessions and pipeline runs
+	env := utils.MergeEnvVars(component.Spec.Template.Env, []corev1.EnvVar{
+		{Name: "CONTAINER_RUNTIME", Value: runtime.Name},
+		{Name: "CONTAINER_RUNTIME_SOCKET", Value: socketPath},
+	})
 
 	template := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -85,6 +195,10 @@ func getNodeAgentDaemonset(registryIP string, metadata metav1.ObjectMeta,
 		},
 		Spec: corev1.PodSpec{
 			TerminationGracePeriodSeconds: &one,
+			NodeSelector: utils.CloneAndAddLabel(component.Spec.Template.NodeSelector, map[string]string{
+				controller.ContainerRuntimeLabelKey: runtime.Name,
+			}),
+			Tolerations: component.Spec.Template.Tolerations,
 			Volumes: []corev1.Volume{
 				{
 					Name: "runtimesocket",
@@ -100,19 +214,14 @@ func getNodeAgentDaemonset(registryIP string, metadata metav1.ObjectMeta,
 				{
 					Name:            controller.NodeAgent,
 					Image:           image,
-					Env:             component.Spec.Template.Env,
+					Env:             env,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 
 					Command: []string{
 						"python",
 						"./app/main.py",
 					},
-					Args: []string{
-						"--image-puller-log-level=INFO",
-						"--image-puller-interval=60",
-						"--image-puller-policy=IfNotPresent",
-						"--image-puller-retries=3",
-					},
+					Args: getImagePullerArgs(component.Spec.ImagePuller),
 					VolumeMounts: []corev1.VolumeMount{
 						{
 							Name:      "runtimesocket",
@@ -124,9 +233,19 @@ func getNodeAgentDaemonset(registryIP string, metadata metav1.ObjectMeta,
 		},
 	}
 
-	// If container runtime is docker add required volumes to inject the certificates with lifecycle hooks
-	if containerRuntime == "docker" {
-		certificateDirectory := fmt.Sprintf("/etc/docker/certs.d/%s/", registryIP)
+	// The registry ca.crt and credentials need to be injected into the container
+	registryVolumes, registryVolumeMounts := getRegistryVolumes(component, "/tls-secret/ca.crt", "ca.crt")
+	template.Spec.Volumes = append(template.Spec.Volumes, registryVolumes...)
+	template.Spec.Containers[0].VolumeMounts = append(template.Spec.Containers[0].VolumeMounts, registryVolumeMounts...)
+	template.Spec.Containers[0].Env = utils.MergeEnvVars(template.Spec.Containers[0].Env, getRegistryEnvVars(component))
+
+	hasRegistryCA := component.Spec.ImageRegistry == nil || component.Spec.ImageRegistry.CABundle != nil
+
+	// If the container runtime reads the registry certificates from a host directory add the
+	// required volumes to inject the certificates with lifecycle hooks
+	certsDirectory, ok := containerRuntimeCertsDirectories[runtime.Name]
+	if ok && hasRegistryCA {
+		certificateDirectory := fmt.Sprintf("%s/%s/", certsDirectory, getRegistryHost(registryIP, component))
 
 		template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{
 			PostStart: &corev1.LifecycleHandler{
@@ -150,47 +269,28 @@ func getNodeAgentDaemonset(registryIP string, metadata metav1.ObjectMeta,
 			},
 		}
 
-		// The registry ca.crt also needs to be injected into the container
 		template.Spec.Volumes = append(template.Spec.Volumes,
 			corev1.Volume{
-				Name: "tls-secret",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: "registry-tls-secret",
-						Items: []corev1.KeyToPath{
-							{
-								Key:  "ca.crt",
-								Path: "ca.crt",
-							},
-						},
-					},
-				},
-			},
-			corev1.Volume{
-				Name: "docker-certs",
+				Name: "runtime-certs",
 				VolumeSource: corev1.VolumeSource{
 					HostPath: &corev1.HostPathVolumeSource{
-						Path: "/etc/docker/certs.d",
+						Path: certsDirectory,
 					},
 				},
 			},
 		)
 
-		// And the secret needs to be mounted into the container
 		template.Spec.Containers[0].VolumeMounts = append(template.Spec.Containers[0].VolumeMounts,
 			corev1.VolumeMount{
-				Name:      "tls-secret",
-				MountPath: "/tls-secret/ca.crt",
-				SubPath:   "ca.crt",
-				ReadOnly:  true,
-			},
-			corev1.VolumeMount{
-				Name:      "docker-certs",
-				MountPath: "/etc/docker/certs.d",
+				Name:      "runtime-certs",
+				MountPath: certsDirectory,
 			},
 		)
 	}
 
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+
 	daemonSet := &appsv1.DaemonSet{
 		ObjectMeta: metadata,
 		Spec: appsv1.DaemonSetSpec{
@@ -201,6 +301,50 @@ func getNodeAgentDaemonset(registryIP string, metadata metav1.ObjectMeta,
 		},
 	}
 
+	daemonSet.Labels = utils.CloneAndAddLabel(metadata.Labels, map[string]string{
+		controller.DeploymentHashLabelKey: utils.ComputeHash(&daemonSet.Spec),
+	})
+
 	return daemonSet, nil
 
 }
+
+// getImagePullerArgs returns the arguments of the image puller of the node-agent, the
+// defaults are used for the unspecified options
+func getImagePullerArgs(imagePuller *orchestv1alpha1.ImagePullerSpec) []string {
+
+	interval := defaultImagePullerInterval
+	retries := defaultImagePullerRetries
+	policy := defaultImagePullerPolicy
+	logLevel := defaultImagePullerLogLevel
+	var images []string
+
+	if imagePuller != nil {
+		if imagePuller.Interval != nil {
+			interval = *imagePuller.Interval
+		}
+		if imagePuller.Retries != nil {
+			retries = *imagePuller.Retries
+		}
+		if imagePuller.Policy != "" {
+			policy = imagePuller.Policy
+		}
+		if imagePuller.LogLevel != "" {
+			logLevel = imagePuller.LogLevel
+		}
+		images = imagePuller.Images
+	}
+
+	args := []string{
+		fmt.Sprintf("--image-puller-log-level=%s", logLevel),
+		fmt.Sprintf("--image-puller-interval=%d", interval),
+		fmt.Sprintf("--image-puller-policy=%s", policy),
+		fmt.Sprintf("--image-puller-retries=%d", retries),
+	}
+
+	for _, image := range images {
+		args = append(args, fmt.Sprintf("--image-puller-image=%s", image))
+	}
+
+	return args
+}
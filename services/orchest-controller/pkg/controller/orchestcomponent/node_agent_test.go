@@ -0,0 +1,54 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetImagePullerArgs(t *testing.T) {
+
+	var interval int32 = 10
+
+	testCases := []struct {
+		name        string
+		imagePuller *orchestv1alpha1.ImagePullerSpec
+		expected    []string
+	}{
+		{
+			name:        "defaults",
+			imagePuller: nil,
+			expected: []string{
+				"--image-puller-log-level=INFO",
+				"--image-puller-interval=60",
+				"--image-puller-policy=IfNotPresent",
+				"--image-puller-retries=3",
+			},
+		},
+		{
+			name: "partially specified with extra images",
+			imagePuller: &orchestv1alpha1.ImagePullerSpec{
+				Interval: &interval,
+				Policy:   corev1.PullAlways,
+				LogLevel: "DEBUG",
+				Images:   []string{"python:3.9", "busybox"},
+			},
+			expected: []string{
+				"--image-puller-log-level=DEBUG",
+				"--image-puller-interval=10",
+				"--image-puller-policy=Always",
+				"--image-puller-retries=3",
+				"--image-puller-image=python:3.9",
+				"--image-puller-image=busybox",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, getImagePullerArgs(test.imagePuller))
+		})
+	}
+}
@@ -14,30 +14,32 @@ import (
 
 type OrchestApiReconciler struct {
 	*OrchestComponentController
-	ingressClass string
 }
 
 func NewOrchestApiReconciler(ctrl *OrchestComponentController) OrchestComponentReconciler {
 	return &OrchestApiReconciler{
 		ctrl,
-		"",
 	}
 }
 
 func (reconciler *OrchestApiReconciler) Reconcile(ctx context.Context, component *orchestv1alpha1.OrchestComponent) (err error) {
 
-	if reconciler.ingressClass == "" {
-		reconciler.ingressClass, err = detectIngressClass(ctx, reconciler.Client())
-		if err != nil {
-			return err
-		}
+	ingressClass, err := getIngressClassName(ctx, reconciler.Client(), component)
+	if err != nil {
+		return err
+	}
+
+	// The ingresses of the sessions are created with the IngressClass of the cluster, there
+	// is none if the services are exposed by a Gateway and no class is specified
+	var envVars []corev1.EnvVar
+	if ingressClass != "" {
+		envVars = []corev1.EnvVar{{Name: "INGRESS_CLASS", Value: ingressClass}}
 	}
 
 	hash := utils.ComputeHash(component)
 	matchLabels := controller.GetResourceMatchLables(controller.OrchestApi, component)
-	metadata := controller.GetMetadata(controller.OrchestApi, hash, component, OrchestComponentKind)
-	newDep := getOrchestApiDeployment(metadata, matchLabels, component,
-		[]corev1.EnvVar{{Name: "INGRESS_CLASS", Value: reconciler.ingressClass}})
+	metadata := getComponentMetadata(controller.OrchestApi, hash, component)
+	newDep := getOrchestApiDeployment(metadata, matchLabels, component, envVars)
 
 	oldDep, err := reconciler.depLister.Deployments(component.Namespace).Get(component.Name)
 	if err != nil {
@@ -65,14 +67,9 @@ func (reconciler *OrchestApiReconciler) Reconcile(ctx context.Context, component
 		}
 		return err
 	}
-	_, err = reconciler.ingLister.Ingresses(component.Namespace).Get(component.Name)
+
+	_, err = reconciler.ensureIngress(ctx, metadata, "/orchest-api", true, false, component)
 	if err != nil {
-		if !kerrors.IsAlreadyExists(err) {
-			ing := getIngressManifest(metadata, "/orchest-api", reconciler.ingressClass, true, false, component)
-			_, err = reconciler.Client().NetworkingV1().Ingresses(component.Namespace).Create(ctx, ing, metav1.CreateOptions{})
-			reconciler.EnqueueAfter(component)
-			return err
-		}
 		return err
 	}
 
@@ -93,15 +90,16 @@ func (reconciler *OrchestApiReconciler) Uninstall(ctx context.Context, component
 		return false, err
 	}
 
-	err = reconciler.Client().NetworkingV1().Ingresses(component.Namespace).Delete(ctx, component.Name, metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
+	err = reconciler.deleteIngress(ctx, component)
+	if err != nil {
 		return false, err
 	}
 
 	// Get the cleanup pod
 	// Note: the cleanup logic is also taking care of registering the
 	// fact that Orchest is being stopped, see (register_orchest_stop).
-	pod, err := reconciler.Client().CoreV1().Pods(component.Namespace).Get(ctx, controller.OrchestApiCleanup, metav1.GetOptions{})
+	cleanupName := getResourceName(component, controller.OrchestApiCleanup)
+	pod, err := reconciler.Client().CoreV1().Pods(component.Namespace).Get(ctx, cleanupName, metav1.GetOptions{})
 	if err != nil && !kerrors.IsNotFound(err) {
 		return false, err
 	} else if kerrors.IsNotFound(err) {
@@ -109,6 +107,7 @@ func (reconciler *OrchestApiReconciler) Uninstall(ctx context.Context, component
 		hash := utils.ComputeHash(component)
 		matchLabels := controller.GetResourceMatchLables(controller.OrchestApiCleanup, component)
 		metadata := controller.GetMetadata(controller.OrchestApiCleanup, hash, component, OrchestComponentKind)
+		metadata.Name = cleanupName
 		cleanupPod := getCleanupPod(metadata, matchLabels, component)
 
 		_, err = reconciler.Client().CoreV1().Pods(component.Namespace).Create(ctx, cleanupPod, metav1.CreateOptions{})
@@ -131,32 +130,18 @@ func getOrchestApiDeployment(metadata metav1.ObjectMeta,
 
 	image := component.Spec.Template.Image
 
-	envMap := utils.GetMapFromEnvVar(component.Spec.Template.Env, extraEnvVars)
+	envMap := utils.GetMapFromEnvVar(component.Spec.Template.Env, extraEnvVars, getRegistryEnvVars(component))
 
 	volumes := []corev1.Volume{
 		{
 			Name: controller.UserDirName,
 			VolumeSource: corev1.VolumeSource{
 				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: controller.UserDirName,
+					ClaimName: getResourceName(component, controller.UserDirName),
 					ReadOnly:  false,
 				},
 			},
 		},
-		{
-			Name: "tls-secret",
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName: "registry-tls-secret",
-					Items: []corev1.KeyToPath{
-						{
-							Key:  "ca.crt",
-							Path: "additional-ca-cert-bundle.crt",
-						},
-					},
-				},
-			},
-		},
 	}
 
 	volumeMounts := []corev1.VolumeMount{
@@ -164,14 +149,13 @@ func getOrchestApiDeployment(metadata metav1.ObjectMeta,
 			Name:      controller.UserDirName,
 			MountPath: controller.UserdirMountPath,
 		},
-		{
-			Name:      "tls-secret",
-			MountPath: "/usr/lib/ssl/certs/additional-ca-cert-bundle.crt",
-			SubPath:   "additional-ca-cert-bundle.crt",
-			ReadOnly:  true,
-		},
 	}
 
+	registryVolumes, registryVolumeMounts := getRegistryVolumes(component,
+		"/usr/lib/ssl/certs/additional-ca-cert-bundle.crt", "additional-ca-cert-bundle.crt")
+	volumes = append(volumes, registryVolumes...)
+	volumeMounts = append(volumeMounts, registryVolumeMounts...)
+
 	devMod := isDevelopmentEnabled(envMap)
 	if devMod {
 		devVolumes, devVolumeMounts := getDevVolumes(controller.OrchestApi, true, false, true)
@@ -184,7 +168,7 @@ func getOrchestApiDeployment(metadata metav1.ObjectMeta,
 			Labels: matchLabels,
 		},
 		Spec: corev1.PodSpec{
-			ServiceAccountName: controller.OrchestApi,
+			ServiceAccountName: getResourceName(component, controller.OrchestApi),
 			Volumes:            volumes,
 			Containers: []corev1.Container{
 				{
@@ -216,6 +200,10 @@ func getOrchestApiDeployment(metadata metav1.ObjectMeta,
 		},
 	}
 
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecretKey(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metadata,
 		Spec: appsv1.DeploymentSpec{
@@ -243,7 +231,7 @@ func getCleanupPod(metadata metav1.ObjectMeta,
 		ObjectMeta: metadata,
 		Spec: corev1.PodSpec{
 			RestartPolicy:      corev1.RestartPolicyNever,
-			ServiceAccountName: controller.OrchestApi,
+			ServiceAccountName: getResourceName(component, controller.OrchestApi),
 			Containers: []corev1.Container{
 				{
 					Name: metadata.Name,
@@ -260,5 +248,9 @@ func getCleanupPod(metadata metav1.ObjectMeta,
 		},
 	}
 
+	injectTrustedCA(component, &pod.ObjectMeta, &pod.Spec)
+	injectSecretKey(component, &pod.ObjectMeta, &pod.Spec)
+	injectSecurityContext(component, &pod.Spec)
+
 	return pod
 }
@@ -12,6 +12,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+var (
+	// The emptyDirs of the socket and the temporary files of the database, the root filesystem
+	// of the database is read-only
+	postgresRunDir = "postgres-run"
+	postgresTmpDir = "postgres-tmp"
+)
+
 type OrchestDatabaseReconciler struct {
 	*OrchestComponentController
 }
@@ -26,7 +33,7 @@ func (reconciler *OrchestDatabaseReconciler) Reconcile(ctx context.Context, comp
 
 	hash := utils.ComputeHash(component)
 	matchLabels := controller.GetResourceMatchLables(controller.OrchestDatabase, component)
-	metadata := controller.GetMetadata(controller.OrchestDatabase, hash, component, OrchestComponentKind)
+	metadata := getComponentMetadata(controller.OrchestDatabase, hash, component)
 	newDep := getOrchestDatabaseDeployment(metadata, matchLabels, component)
 
 	oldDep, err := reconciler.depLister.Deployments(component.Namespace).Get(component.Name)
@@ -87,11 +94,23 @@ func getOrchestDatabaseDeployment(metadata metav1.ObjectMeta,
 					Name: controller.UserDirName,
 					VolumeSource: corev1.VolumeSource{
 						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-							ClaimName: controller.UserDirName,
+							ClaimName: getResourceName(component, controller.UserDirName),
 							ReadOnly:  false,
 						},
 					},
 				},
+				{
+					Name: postgresRunDir,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				},
+				{
+					Name: postgresTmpDir,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				},
 			},
 			Containers: []corev1.Container{
 				{
@@ -110,6 +129,14 @@ func getOrchestDatabaseDeployment(metadata metav1.ObjectMeta,
 							MountPath: controller.DBMountPath,
 							SubPath:   controller.DBSubPath,
 						},
+						{
+							Name:      postgresRunDir,
+							MountPath: "/var/run/postgresql",
+						},
+						{
+							Name:      postgresTmpDir,
+							MountPath: "/tmp",
+						},
 					},
 					ReadinessProbe: &corev1.Probe{
 						ProbeHandler: corev1.ProbeHandler{
@@ -132,6 +159,10 @@ func getOrchestDatabaseDeployment(metadata metav1.ObjectMeta,
 		},
 	}
 
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+	injectDataOwner(&template.Spec, template.Spec.Containers[0].VolumeMounts[0])
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metadata,
 		Spec: appsv1.DeploymentSpec{
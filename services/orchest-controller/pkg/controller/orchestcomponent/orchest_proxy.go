@@ -0,0 +1,378 @@
+package orchestcomponent
+
+import (
+	"fmt"
+	"reflect"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var (
+	proxyPort             = 8080
+	proxyConfigVolumeName = "config"
+	// The nginx image renders the templates into /etc/nginx/conf.d on startup
+	proxyConfigMountPath = "/etc/nginx/templates"
+	proxyConfigKey       = "default.conf.template"
+)
+
+// proxyConfigTemplate is the nginx configuration of the proxy, the arguments are the addresses
+// of auth-server, orchest-api and orchest-webserver, the namespace of the Jupyter servers, the
+// port, the base path and the redirect of the base path without the trailing slash.
+// The resolver is filled in by the entrypoint of the nginx image, since the services of the
+// Jupyter servers are only resolved when they are requested.
+const proxyConfigTemplate = `map $http_upgrade $connection_upgrade {
+    default upgrade;
+    ''      close;
+}
+
+server {
+    listen %[5]d;
+    client_max_body_size 0;
+    resolver ${NGINX_LOCAL_RESOLVERS} valid=10s;
+
+    proxy_http_version 1.1;
+    proxy_read_timeout 3600s;
+    proxy_set_header Host $host;
+    proxy_set_header X-Real-IP $remote_addr;
+    proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+    proxy_set_header X-Forwarded-Proto $scheme;
+    proxy_set_header Upgrade $http_upgrade;
+    proxy_set_header Connection $connection_upgrade;
+
+    location = /_auth {
+        internal;
+        proxy_pass http://%[1]s/auth;
+        proxy_pass_request_body off;
+        proxy_set_header Content-Length "";
+        proxy_set_header Host $host;
+        proxy_set_header X-Original-URI $request_uri;
+    }
+
+    location @signin {
+        return 302 %[6]s/login;
+    }
+%[7]s
+    location %[6]s/login {
+        proxy_pass http://%[1]s;
+    }
+
+    location %[6]s/orchest-api {
+        auth_request /_auth;
+        proxy_pass http://%[2]s;
+    }
+
+    location ~ ^%[6]s/(?<jupyter_server>jupyter-server-[^/]+) {
+        auth_request /_auth;
+        error_page 401 = @signin;
+        proxy_pass http://$jupyter_server.%[4]s.svc.cluster.local;
+    }
+
+    location %[6]s/ {
+        auth_request /_auth;
+        error_page 401 = @signin;
+        proxy_pass http://%[3]s;
+    }
+}
+`
+
+type OrchestProxyReconciler struct {
+	*OrchestComponentController
+}
+
+func NewOrchestProxyReconciler(ctrl *OrchestComponentController) OrchestComponentReconciler {
+	return &OrchestProxyReconciler{
+		ctrl,
+	}
+}
+
+func (reconciler *OrchestProxyReconciler) Reconcile(ctx context.Context, component *orchestv1alpha1.OrchestComponent) error {
+
+	hash := utils.ComputeHash(component)
+	matchLabels := controller.GetResourceMatchLables(controller.OrchestProxy, component)
+	metadata := getComponentMetadata(controller.OrchestProxy, hash, component)
+
+	err := reconciler.ensureProxyConfig(ctx, metadata, component)
+	if err != nil {
+		return err
+	}
+
+	newDep := getOrchestProxyDeployment(metadata, matchLabels, component)
+
+	oldDep, err := reconciler.depLister.Deployments(component.Namespace).Get(component.Name)
+	if err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			_, err = reconciler.Client().AppsV1().Deployments(component.Namespace).Create(ctx, newDep, metav1.CreateOptions{})
+			reconciler.EnqueueAfter(component)
+			return err
+		}
+		return err
+	}
+
+	if !isDeploymentUpdated(newDep, oldDep) {
+		_, err := reconciler.Client().AppsV1().Deployments(component.Namespace).Update(ctx, newDep, metav1.UpdateOptions{})
+		reconciler.EnqueueAfter(component)
+		return err
+	}
+
+	newSvc := getOrchestProxyService(metadata, matchLabels, component)
+
+	svc, err := reconciler.svcLister.Services(component.Namespace).Get(component.Name)
+	if kerrors.IsNotFound(err) {
+		_, err = reconciler.Client().CoreV1().Services(component.Namespace).Create(ctx, newSvc, metav1.CreateOptions{})
+		reconciler.EnqueueAfter(component)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if !isProxyServiceUpdated(newSvc, svc) {
+		svc = svc.DeepCopy()
+		svc.Annotations = newSvc.Annotations
+		svc.Spec.Type = newSvc.Spec.Type
+		svc.Spec.Ports = newSvc.Spec.Ports
+		_, err = reconciler.Client().CoreV1().Services(component.Namespace).Update(ctx, svc, metav1.UpdateOptions{})
+		reconciler.EnqueueAfter(component)
+		return err
+	}
+
+	if isServiceReady(ctx, reconciler.Client(), svc) &&
+		isDeploymentReady(oldDep) && isProxyServiceExposed(svc) {
+		return reconciler.updatePhase(ctx, component, orchestv1alpha1.Running)
+	}
+
+	return nil
+}
+
+func (reconciler *OrchestProxyReconciler) Uninstall(ctx context.Context, component *orchestv1alpha1.OrchestComponent) (bool, error) {
+
+	err := reconciler.Client().AppsV1().Deployments(component.Namespace).Delete(ctx, component.Name, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ensureProxyConfig creates or updates the ConfigMap holding the nginx configuration
+func (reconciler *OrchestProxyReconciler) ensureProxyConfig(ctx context.Context,
+	metadata metav1.ObjectMeta, component *orchestv1alpha1.OrchestComponent) error {
+
+	data := map[string]string{
+		proxyConfigKey: getProxyConfig(component),
+	}
+
+	configMap, err := reconciler.Client().CoreV1().ConfigMaps(component.Namespace).Get(ctx, metadata.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metadata,
+			Data:       data,
+		}
+		_, err = reconciler.Client().CoreV1().ConfigMaps(component.Namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the config of %s", metadata.Name)
+		}
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get the config of %s", metadata.Name)
+	}
+
+	if reflect.DeepEqual(configMap.Data, data) {
+		return nil
+	}
+
+	configMap.Data = data
+	_, err = reconciler.Client().CoreV1().ConfigMaps(component.Namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the config of %s", metadata.Name)
+	}
+
+	return nil
+}
+
+// getExposureMode returns the way the component is exposed, defaulting to ingress
+func getExposureMode(component *orchestv1alpha1.OrchestComponent) orchestv1alpha1.ExposureMode {
+	if exposure := component.Spec.Exposure; exposure != nil && exposure.Mode != "" {
+		return exposure.Mode
+	}
+	return orchestv1alpha1.IngressExposureMode
+}
+
+// getProxyConfig returns the nginx configuration routing the requests to the services of the
+// OrchestCluster of the component
+func getProxyConfig(component *orchestv1alpha1.OrchestComponent) string {
+	basePath := component.Spec.BasePath
+
+	var baseRedirect string
+	if basePath != "" {
+		baseRedirect = fmt.Sprintf("\n    location = %[1]s {\n        return 301 %[1]s/;\n    }\n", basePath)
+	}
+
+	return fmt.Sprintf(proxyConfigTemplate,
+		getServiceAddress(component, controller.AuthServer),
+		getServiceAddress(component, controller.OrchestApi),
+		getServiceAddress(component, controller.OrchestWebserver),
+		component.Namespace, proxyPort, basePath, baseRedirect)
+}
+
+// getServiceAddress returns the FQDN of an Orchest service, nginx resolves the upstreams with
+// the resolver of the node instead of the search domains of the pod
+func getServiceAddress(component *orchestv1alpha1.OrchestComponent, name string) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", getResourceName(component, name), component.Namespace)
+}
+
+func getOrchestProxyDeployment(metadata metav1.ObjectMeta,
+	matchLabels map[string]string, component *orchestv1alpha1.OrchestComponent) *appsv1.Deployment {
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: matchLabels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:            controller.OrchestProxy,
+					Image:           component.Spec.Template.Image,
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					Ports: []corev1.ContainerPort{
+						{
+							ContainerPort: int32(proxyPort),
+						},
+					},
+					Env: utils.MergeEnvVars(component.Spec.Template.Env, []corev1.EnvVar{
+						{Name: "NGINX_ENTRYPOINT_LOCAL_RESOLVERS", Value: "1"},
+					}),
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      proxyConfigVolumeName,
+							MountPath: proxyConfigMountPath,
+							ReadOnly:  true,
+						},
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							TCPSocket: &corev1.TCPSocketAction{
+								Port: intstr.FromInt(proxyPort),
+							},
+						},
+						PeriodSeconds: 5,
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: proxyConfigVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: metadata.Name,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Roll the proxy when the configuration changes
+	template.Annotations = map[string]string{
+		controller.DeploymentHashLabelKey: utils.ComputeHash(getProxyConfig(component)),
+	}
+
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metadata,
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: matchLabels,
+			},
+			Template: template,
+		},
+	}
+
+	deployment.Labels = utils.CloneAndAddLabel(metadata.Labels, map[string]string{
+		controller.DeploymentHashLabelKey: utils.ComputeHash(&deployment.Spec),
+	})
+
+	return deployment
+}
+
+// getOrchestProxyService returns the Service exposing the proxy in the exposure mode of the
+// component. Unlike the other services it is owned by the component, so it is deleted with it
+// when the exposure mode changes.
+func getOrchestProxyService(metadata metav1.ObjectMeta, matchLabels map[string]string,
+	component *orchestv1alpha1.OrchestComponent) *corev1.Service {
+
+	port := corev1.ServicePort{
+		Name:       "http",
+		Port:       80,
+		TargetPort: intstr.FromInt(proxyPort),
+		Protocol:   corev1.ProtocolTCP,
+	}
+
+	serviceType := corev1.ServiceTypeClusterIP
+	switch getExposureMode(component) {
+	case orchestv1alpha1.NodePortExposureMode:
+		serviceType = corev1.ServiceTypeNodePort
+		port.NodePort = component.Spec.Exposure.NodePort
+	case orchestv1alpha1.LoadBalancerExposureMode:
+		serviceType = corev1.ServiceTypeLoadBalancer
+	}
+
+	objectMeta := metadata.DeepCopy()
+	objectMeta.Annotations = utils.CloneAndAddLabel(objectMeta.Annotations, component.Spec.Exposure.ServiceAnnotations)
+
+	return &corev1.Service{
+		ObjectMeta: *objectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: matchLabels,
+			Ports:    []corev1.ServicePort{port},
+		},
+	}
+}
+
+// isProxyServiceUpdated returns true if the type, the port and the annotations of the Service
+// are the desired ones, the node port is only compared if it is specified
+func isProxyServiceUpdated(newSvc, oldSvc *corev1.Service) bool {
+	if newSvc.Spec.Type != oldSvc.Spec.Type || len(oldSvc.Spec.Ports) != 1 {
+		return false
+	}
+
+	newPort, oldPort := newSvc.Spec.Ports[0], oldSvc.Spec.Ports[0]
+	if newPort.Port != oldPort.Port || newPort.TargetPort != oldPort.TargetPort ||
+		(newPort.NodePort != 0 && newPort.NodePort != oldPort.NodePort) {
+		return false
+	}
+
+	for key, value := range newSvc.Annotations {
+		if oldSvc.Annotations[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isProxyServiceExposed returns true once the Service is reachable from outside the cluster in
+// its exposure mode, i.e. a node port is allocated or the load balancer is provisioned
+func isProxyServiceExposed(svc *corev1.Service) bool {
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeNodePort:
+		return len(svc.Spec.Ports) > 0 && svc.Spec.Ports[0].NodePort != 0
+	case corev1.ServiceTypeLoadBalancer:
+		return len(svc.Status.LoadBalancer.Ingress) > 0
+	default:
+		return true
+	}
+}
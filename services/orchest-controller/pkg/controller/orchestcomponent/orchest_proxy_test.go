@@ -0,0 +1,83 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetOrchestProxyService(t *testing.T) {
+
+	testCases := []struct {
+		name            string
+		exposure        *orchestv1alpha1.ExposureSpec
+		expectedType    corev1.ServiceType
+		expectedExposed bool
+	}{
+		{
+			name:            "proxy",
+			exposure:        &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.ProxyExposureMode},
+			expectedType:    corev1.ServiceTypeClusterIP,
+			expectedExposed: true,
+		},
+		{
+			name:            "node port",
+			exposure:        &orchestv1alpha1.ExposureSpec{Mode: orchestv1alpha1.NodePortExposureMode, NodePort: 30080},
+			expectedType:    corev1.ServiceTypeNodePort,
+			expectedExposed: true,
+		},
+		{
+			name: "load balancer",
+			exposure: &orchestv1alpha1.ExposureSpec{
+				Mode:               orchestv1alpha1.LoadBalancerExposureMode,
+				ServiceAnnotations: map[string]string{"example.com/lb": "internal"},
+			},
+			expectedType:    corev1.ServiceTypeLoadBalancer,
+			expectedExposed: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			component := &orchestv1alpha1.OrchestComponent{}
+			component.Spec.Exposure = test.exposure
+			metadata := metav1.ObjectMeta{Name: "cluster-1-orchest-proxy", Namespace: "orchest"}
+
+			svc := getOrchestProxyService(metadata, nil, component)
+
+			assert.Equal(t, test.expectedType, svc.Spec.Type)
+			assert.Equal(t, test.exposure.NodePort, svc.Spec.Ports[0].NodePort)
+			assert.Equal(t, test.exposure.ServiceAnnotations, svc.Annotations)
+			assert.Equal(t, test.expectedExposed, isProxyServiceExposed(svc))
+			assert.True(t, isProxyServiceUpdated(svc, svc))
+		})
+	}
+}
+
+func TestGetProxyConfig(t *testing.T) {
+
+	component := &orchestv1alpha1.OrchestComponent{}
+	component.Namespace = "orchest"
+
+	config := getProxyConfig(component)
+
+	assert.Contains(t, config, "listen 8080;")
+	assert.Contains(t, config, "proxy_pass http://auth-server.orchest.svc.cluster.local/auth;")
+	assert.Contains(t, config, "proxy_pass http://orchest-api.orchest.svc.cluster.local;")
+	assert.Contains(t, config, "proxy_pass http://$jupyter_server.orchest.svc.cluster.local;")
+	assert.Contains(t, config, "location / {")
+	assert.NotContains(t, config, "%!")
+
+	component.Spec.BasePath = "/orchest"
+	config = getProxyConfig(component)
+
+	assert.Contains(t, config, "location = /orchest {")
+	assert.Contains(t, config, "location /orchest/login {")
+	assert.Contains(t, config, "return 302 /orchest/login;")
+	assert.Contains(t, config, "location /orchest/ {")
+	assert.Contains(t, config, "location ~ ^/orchest/(?<jupyter_server>jupyter-server-[^/]+) {")
+	assert.NotContains(t, config, "%!")
+}
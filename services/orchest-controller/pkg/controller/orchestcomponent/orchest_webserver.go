@@ -13,13 +13,11 @@ import (
 
 type OrchestWebServerReconciler struct {
 	*OrchestComponentController
-	ingressClass string
 }
 
 func NewOrchestWebServerReconciler(ctrl *OrchestComponentController) OrchestComponentReconciler {
 	return &OrchestWebServerReconciler{
 		ctrl,
-		"",
 	}
 }
 
@@ -27,7 +25,7 @@ func (reconciler *OrchestWebServerReconciler) Reconcile(ctx context.Context, com
 
 	hash := utils.ComputeHash(component)
 	matchLabels := controller.GetResourceMatchLables(controller.OrchestWebserver, component)
-	metadata := controller.GetMetadata(controller.OrchestWebserver, hash, component, OrchestComponentKind)
+	metadata := getComponentMetadata(controller.OrchestWebserver, hash, component)
 	newDep := getOrchestWebserverDeployment(metadata, matchLabels, component)
 
 	oldDep, err := reconciler.depLister.Deployments(component.Namespace).Get(component.Name)
@@ -57,26 +55,13 @@ func (reconciler *OrchestWebServerReconciler) Reconcile(ctx context.Context, com
 		return err
 	}
 
-	if reconciler.ingressClass == "" {
-		reconciler.ingressClass, err = detectIngressClass(ctx, reconciler.Client())
-		if err != nil {
-			return err
-		}
-	}
-
-	oldIng, err := reconciler.ingLister.Ingresses(component.Namespace).Get(component.Name)
+	ingressReady, err := reconciler.ensureIngress(ctx, metadata, "/", true, true, component)
 	if err != nil {
-		if !kerrors.IsAlreadyExists(err) {
-			ing := getIngressManifest(metadata, "/", reconciler.ingressClass, true, true, component)
-			_, err = reconciler.Client().NetworkingV1().Ingresses(component.Namespace).Create(ctx, ing, metav1.CreateOptions{})
-			reconciler.EnqueueAfter(component)
-			return err
-		}
 		return err
 	}
 
 	if isServiceReady(ctx, reconciler.Client(), svc) &&
-		isDeploymentReady(oldDep) && isIngressReady(oldIng) {
+		isDeploymentReady(oldDep) && ingressReady {
 		return reconciler.updatePhase(ctx, component, orchestv1alpha1.Running)
 	}
 
@@ -90,8 +75,8 @@ func (reconciler *OrchestWebServerReconciler) Uninstall(ctx context.Context, com
 		return false, err
 	}
 
-	err = reconciler.Client().NetworkingV1().Ingresses(component.Namespace).Delete(ctx, component.Name, metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
+	err = reconciler.deleteIngress(ctx, component)
+	if err != nil {
 		return false, err
 	}
 	return true, nil
@@ -111,7 +96,7 @@ func getOrchestWebserverDeployment(metadata metav1.ObjectMeta,
 			Name: controller.UserDirName,
 			VolumeSource: corev1.VolumeSource{
 				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: controller.UserDirName,
+					ClaimName: getResourceName(component, controller.UserDirName),
 					ReadOnly:  false,
 				},
 			},
@@ -154,6 +139,10 @@ func getOrchestWebserverDeployment(metadata metav1.ObjectMeta,
 		},
 	}
 
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecretKey(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metadata,
 		Spec: appsv1.DeploymentSpec{
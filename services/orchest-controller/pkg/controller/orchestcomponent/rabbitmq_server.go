@@ -25,7 +25,7 @@ func (reconciler *RabbitmqServerReconciler) Reconcile(ctx context.Context, compo
 
 	hash := utils.ComputeHash(component)
 	matchLabels := controller.GetResourceMatchLables(controller.Rabbitmq, component)
-	metadata := controller.GetMetadata(controller.Rabbitmq, hash, component, OrchestComponentKind)
+	metadata := getComponentMetadata(controller.Rabbitmq, hash, component)
 	newDep := getRabbitMqDeployment(metadata, matchLabels, component)
 
 	oldDep, err := reconciler.depLister.Deployments(component.Namespace).Get(component.Name)
@@ -84,7 +84,7 @@ func getRabbitMqDeployment(metadata metav1.ObjectMeta,
 					Name: controller.UserDirName,
 					VolumeSource: corev1.VolumeSource{
 						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-							ClaimName: controller.UserDirName,
+							ClaimName: getResourceName(component, controller.UserDirName),
 							ReadOnly:  false,
 						},
 					},
@@ -113,6 +113,10 @@ func getRabbitMqDeployment(metadata metav1.ObjectMeta,
 		},
 	}
 
+	injectTrustedCA(component, &template.ObjectMeta, &template.Spec)
+	injectSecurityContext(component, &template.Spec)
+	injectDataOwner(&template.Spec, template.Spec.Containers[0].VolumeMounts[0])
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metadata,
 		Spec: appsv1.DeploymentSpec{
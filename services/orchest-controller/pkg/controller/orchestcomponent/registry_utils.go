@@ -0,0 +1,155 @@
+package orchestcomponent
+
+import (
+	"strings"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	registryTLSSecret = "registry-tls-secret"
+
+	// The credentials of the in-cluster registry, created by the OrchestCluster controller
+	registryCredentialsSecret = "docker-registry-credentials"
+
+	// Names of the registry volumes
+	registryCAVolume          = "tls-secret"
+	registryCredentialsVolume = "registry-credentials"
+
+	// The directory the registry credentials are mounted in, in the format of a docker
+	// config directory
+	registryCredentialsMountPath = "/etc/orchest/registry"
+)
+
+// getRegistryHost returns the host of the image registry the component uses, registryIP is
+// the address of the in-cluster docker-registry, IPv6 addresses are enclosed in brackets.
+func getRegistryHost(registryIP string, component *orchestv1alpha1.OrchestComponent) string {
+	if component.Spec.ImageRegistry == nil {
+		return utils.GetHostFromIP(registryIP)
+	}
+
+	url := component.Spec.ImageRegistry.URL
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimPrefix(url, "http://")
+	return strings.SplitN(url, "/", 2)[0]
+}
+
+// getRegistryCredentialsSecret returns the name of the secret holding the credentials of the
+// image registry, or an empty string if the registry does not require credentials.
+func getRegistryCredentialsSecret(component *orchestv1alpha1.OrchestComponent) string {
+	if component.Spec.ImageRegistry == nil {
+		return getResourceName(component, registryCredentialsSecret)
+	}
+	return component.Spec.ImageRegistry.CredentialsSecret
+}
+
+// getRegistryVolumes returns the volumes and volume mounts which inject the CA certificate of
+// the image registry at caMountPath, and the registry credentials if the registry requires them.
+func getRegistryVolumes(component *orchestv1alpha1.OrchestComponent, caMountPath, caFileName string) (
+	[]corev1.Volume, []corev1.VolumeMount) {
+
+	volumes := make([]corev1.Volume, 0, 2)
+	volumeMounts := make([]corev1.VolumeMount, 0, 2)
+
+	registry := component.Spec.ImageRegistry
+	if registry == nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: registryCAVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: getResourceName(component, registryTLSSecret),
+					Items: []corev1.KeyToPath{
+						{
+							Key:  "ca.crt",
+							Path: caFileName,
+						},
+					},
+				},
+			},
+		})
+	} else if registry.CABundle != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: registryCAVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: registry.CABundle.LocalObjectReference,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  registry.CABundle.Key,
+							Path: caFileName,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	if len(volumes) != 0 {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      registryCAVolume,
+			MountPath: caMountPath,
+			SubPath:   caFileName,
+			ReadOnly:  true,
+		})
+	}
+
+	if credentialsSecret := getRegistryCredentialsSecret(component); credentialsSecret != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: registryCredentialsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: credentialsSecret,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  corev1.DockerConfigJsonKey,
+							Path: "config.json",
+						},
+					},
+				},
+			},
+		})
+
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      registryCredentialsVolume,
+			MountPath: registryCredentialsMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	return volumes, volumeMounts
+}
+
+// getRegistryEnvVars returns the env vars which point the component to the image registry
+// and its credentials. ORCHEST_IMAGE_REGISTRY is only set if an external registry is used.
+func getRegistryEnvVars(component *orchestv1alpha1.OrchestComponent) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+
+	if registry := component.Spec.ImageRegistry; registry != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "ORCHEST_IMAGE_REGISTRY",
+			Value: registry.URL,
+		})
+	}
+
+	if credentialsSecret := getRegistryCredentialsSecret(component); credentialsSecret != "" {
+		envVars = append(envVars,
+			corev1.EnvVar{
+				Name:  "ORCHEST_IMAGE_REGISTRY_CREDENTIALS_SECRET",
+				Value: credentialsSecret,
+			},
+			// Used by the docker and buildah clients respectively
+			corev1.EnvVar{
+				Name:  "DOCKER_CONFIG",
+				Value: registryCredentialsMountPath,
+			},
+			corev1.EnvVar{
+				Name:  "REGISTRY_AUTH_FILE",
+				Value: registryCredentialsMountPath + "/config.json",
+			},
+		)
+	}
+
+	return envVars
+}
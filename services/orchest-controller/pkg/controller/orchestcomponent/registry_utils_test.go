@@ -0,0 +1,104 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetRegistryHost(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		registryIP string
+		registry   *orchestv1alpha1.ImageRegistrySpec
+		host       string
+	}{
+		{
+			name:       "in-cluster registry",
+			registryIP: "10.96.0.10",
+			registry:   nil,
+			host:       "10.96.0.10",
+		},
+		{
+			name:       "in-cluster ipv6 registry",
+			registryIP: "fd00::a",
+			registry:   nil,
+			host:       "[fd00::a]",
+		},
+		{
+			name: "external registry with project",
+			registry: &orchestv1alpha1.ImageRegistrySpec{
+				URL: "harbor.example.com/orchest",
+			},
+			host: "harbor.example.com",
+		},
+		{
+			name: "external registry with scheme and port",
+			registry: &orchestv1alpha1.ImageRegistrySpec{
+				URL: "https://localhost:5000",
+			},
+			host: "localhost:5000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			component := &orchestv1alpha1.OrchestComponent{}
+			component.Spec.ImageRegistry = test.registry
+			assert.Equal(t, test.host, getRegistryHost(test.registryIP, component))
+		})
+	}
+}
+
+func TestGetRegistryVolumes(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		registry *orchestv1alpha1.ImageRegistrySpec
+		volumes  []string
+	}{
+		{
+			name:     "in-cluster registry",
+			registry: nil,
+			volumes:  []string{registryCAVolume, registryCredentialsVolume},
+		},
+		{
+			name: "external registry without ca and credentials",
+			registry: &orchestv1alpha1.ImageRegistrySpec{
+				URL: "harbor.example.com/orchest",
+			},
+			volumes: []string{},
+		},
+		{
+			name: "external registry with ca and credentials",
+			registry: &orchestv1alpha1.ImageRegistrySpec{
+				URL:               "harbor.example.com/orchest",
+				CredentialsSecret: "harbor-credentials",
+				CABundle: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "harbor-ca"},
+					Key:                  "ca.crt",
+				},
+			},
+			volumes: []string{registryCAVolume, registryCredentialsVolume},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			component := &orchestv1alpha1.OrchestComponent{}
+			component.Spec.ImageRegistry = test.registry
+
+			volumes, volumeMounts := getRegistryVolumes(component, "/tls-secret/ca.crt", "ca.crt")
+			assert.Equal(t, len(volumes), len(volumeMounts))
+
+			names := []string{}
+			for _, volume := range volumes {
+				names = append(names, volume.Name)
+			}
+			assert.Equal(t, test.volumes, names)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package orchestcomponent
+
+import (
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	secretKeyEnvVar = "ORCHEST_SECRET_KEY"
+
+	// The pod template annotation which rolls out the pods if the secret key is rotated
+	secretKeyHashAnnotationKey = "orchest.io/secret-key-hash"
+)
+
+// injectSecretKey injects the generated secret key of the component into the first container
+// of the pod, a secret key set by hand in the env vars of the component takes precedence.
+func injectSecretKey(component *orchestv1alpha1.OrchestComponent, objectMeta *metav1.ObjectMeta,
+	podSpec *corev1.PodSpec) {
+
+	secretKey := component.Spec.SecretKey
+	if secretKey == nil || len(podSpec.Containers) == 0 {
+		return
+	}
+
+	container := &podSpec.Containers[0]
+	if utils.GetKeyFromEnvVar(container.Env, secretKeyEnvVar) != "" {
+		return
+	}
+
+	objectMeta.Annotations = utils.CloneAndAddLabel(objectMeta.Annotations, map[string]string{
+		secretKeyHashAnnotationKey: component.Spec.SecretKeyHash,
+	})
+
+	// Appended as MergeEnvVars drops the secret references
+	utils.RemoveEnvVariable(&container.Env, secretKeyEnvVar)
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name: secretKeyEnvVar,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: secretKey.DeepCopy(),
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectSecretKey(t *testing.T) {
+
+	component := &orchestv1alpha1.OrchestComponent{}
+	component.Spec.SecretKey = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "cluster-1-application-secrets"},
+		Key:                  "orchest-api-secret-key",
+	}
+	component.Spec.SecretKeyHash = "abc"
+
+	objectMeta := metav1.ObjectMeta{}
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "orchest-api"}}}
+
+	injectSecretKey(component, &objectMeta, &podSpec)
+
+	assert.Equal(t, "abc", objectMeta.Annotations[secretKeyHashAnnotationKey])
+	assert.Equal(t, []corev1.EnvVar{
+		{
+			Name: secretKeyEnvVar,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: component.Spec.SecretKey,
+			},
+		},
+	}, podSpec.Containers[0].Env)
+
+	// A secret key set by hand takes precedence
+	objectMeta = metav1.ObjectMeta{}
+	podSpec = corev1.PodSpec{Containers: []corev1.Container{
+		{
+			Name: "orchest-api",
+			Env:  []corev1.EnvVar{{Name: secretKeyEnvVar, Value: "custom"}},
+		},
+	}}
+
+	injectSecretKey(component, &objectMeta, &podSpec)
+
+	assert.Empty(t, objectMeta.Annotations)
+	assert.Equal(t, []corev1.EnvVar{{Name: secretKeyEnvVar, Value: "custom"}}, podSpec.Containers[0].Env)
+}
@@ -0,0 +1,131 @@
+package orchestcomponent
+
+import (
+	"fmt"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// The uid and gid of the postgres and rabbitmq users of the database and broker images
+	dataUserID int64 = 999
+	rootUserID int64 = 0
+
+	trueValue  = true
+	falseValue = false
+
+	dataOwner = "data-owner"
+
+	// The capabilities the images running as root need, e.g. to bind port 80 or to write
+	// the files of the userdir owned by other users
+	rootCapabilities = []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL",
+		"SETGID", "SETUID", "NET_BIND_SERVICE"}
+)
+
+// injectSecurityContext sets the security contexts of the component template, or the defaults
+// of the component if not specified, on the pod and on the containers without one.
+func injectSecurityContext(component *orchestv1alpha1.OrchestComponent, podSpec *corev1.PodSpec) {
+
+	podSecurityContext, securityContext := getDefaultSecurityContexts(getReconcilerName(component))
+	if component.Spec.Template.PodSecurityContext != nil {
+		podSecurityContext = component.Spec.Template.PodSecurityContext.DeepCopy()
+	}
+	if component.Spec.Template.SecurityContext != nil {
+		securityContext = component.Spec.Template.SecurityContext.DeepCopy()
+	}
+
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = podSecurityContext
+	}
+
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].SecurityContext == nil {
+			podSpec.InitContainers[i].SecurityContext = securityContext.DeepCopy()
+		}
+	}
+
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].SecurityContext == nil {
+			podSpec.Containers[i].SecurityContext = securityContext.DeepCopy()
+		}
+	}
+}
+
+// injectDataOwner prepends an init container which hands the data directory over to the user
+// the pod runs as, the directory is created by the kubelet as root on a fresh install, and
+// owned by root if the image previously ran as root and dropped the privileges itself.
+func injectDataOwner(podSpec *corev1.PodSpec, mount corev1.VolumeMount) {
+
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext == nil || podSecurityContext.RunAsUser == nil ||
+		*podSecurityContext.RunAsUser == 0 || len(podSpec.Containers) == 0 {
+		return
+	}
+
+	group := *podSecurityContext.RunAsUser
+	if podSecurityContext.RunAsGroup != nil {
+		group = *podSecurityContext.RunAsGroup
+	}
+
+	podSpec.InitContainers = append([]corev1.Container{
+		{
+			Name:            dataOwner,
+			Image:           podSpec.Containers[0].Image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command: []string{"chown", "-R",
+				fmt.Sprintf("%d:%d", *podSecurityContext.RunAsUser, group), mount.MountPath},
+			VolumeMounts: []corev1.VolumeMount{mount},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:                &rootUserID,
+				RunAsNonRoot:             &falseValue,
+				AllowPrivilegeEscalation: &falseValue,
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+					Add:  []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER"},
+				},
+			},
+		},
+	}, podSpec.InitContainers...)
+}
+
+// getDefaultSecurityContexts returns the default pod and container security contexts of a
+// component. The database and the broker run as their non-root users, the other Orchest images
+// run as root with only the capabilities they need. node-agent is the privileged exception,
+// it runs as root with the default capabilities to use the container runtime socket of the node.
+func getDefaultSecurityContexts(name string) (*corev1.PodSecurityContext, *corev1.SecurityContext) {
+
+	podSecurityContext := &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+
+	securityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &falseValue,
+	}
+
+	switch name {
+	case controller.OrchestDatabase, controller.Rabbitmq:
+		podSecurityContext.RunAsNonRoot = &trueValue
+		podSecurityContext.RunAsUser = &dataUserID
+		podSecurityContext.RunAsGroup = &dataUserID
+		securityContext.Capabilities = &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		}
+		// The database only writes to the data directory and the emptyDirs of the socket and
+		// temporary files, the broker writes its cookie to the home directory of the image
+		if name == controller.OrchestDatabase {
+			securityContext.ReadOnlyRootFilesystem = &trueValue
+		}
+	case controller.NodeAgent:
+	default:
+		securityContext.Capabilities = &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+			Add:  rootCapabilities,
+		}
+	}
+
+	return podSecurityContext, securityContext
+}
@@ -0,0 +1,105 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestInjectSecurityContext(t *testing.T) {
+
+	var customUserID int64 = 1000
+
+	testCases := []struct {
+		name               string
+		reconcilerName     string
+		template           orchestv1alpha1.OrchestComponentTemplate
+		expectedUser       *int64
+		expectedCapsAdded  int
+		expectedCapsDrop   bool
+		expectedReadOnly   bool
+		expectedDataOwner  bool
+		expectedInitUserID *int64
+	}{
+		{
+			name:              "database",
+			reconcilerName:    controller.OrchestDatabase,
+			expectedUser:      &dataUserID,
+			expectedCapsDrop:  true,
+			expectedReadOnly:  true,
+			expectedDataOwner: true,
+		},
+		{
+			name:              "rabbitmq",
+			reconcilerName:    controller.Rabbitmq,
+			expectedUser:      &dataUserID,
+			expectedCapsDrop:  true,
+			expectedDataOwner: true,
+		},
+		{
+			name:              "orchest-api",
+			reconcilerName:    controller.OrchestApi,
+			expectedCapsAdded: len(rootCapabilities),
+			expectedCapsDrop:  true,
+		},
+		{
+			name:           "node-agent",
+			reconcilerName: controller.NodeAgent,
+		},
+		{
+			name:           "custom database",
+			reconcilerName: controller.OrchestDatabase,
+			template: orchestv1alpha1.OrchestComponentTemplate{
+				PodSecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &customUserID,
+				},
+				SecurityContext: &corev1.SecurityContext{},
+			},
+			expectedUser:      &customUserID,
+			expectedDataOwner: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			component := &orchestv1alpha1.OrchestComponent{}
+			component.Spec.ReconcilerName = test.reconcilerName
+			component.Spec.Template = test.template
+
+			podSpec := corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  test.reconcilerName,
+						Image: "image",
+					},
+				},
+			}
+
+			injectSecurityContext(component, &podSpec)
+			injectDataOwner(&podSpec, corev1.VolumeMount{Name: "data", MountPath: "/data"})
+
+			assert.Equal(t, test.expectedUser, podSpec.SecurityContext.RunAsUser)
+
+			securityContext := podSpec.Containers[0].SecurityContext
+			if test.expectedCapsDrop {
+				assert.Equal(t, []corev1.Capability{"ALL"}, securityContext.Capabilities.Drop)
+				assert.Len(t, securityContext.Capabilities.Add, test.expectedCapsAdded)
+			} else {
+				assert.True(t, securityContext.Capabilities == nil || len(securityContext.Capabilities.Drop) == 0)
+			}
+			assert.Equal(t, test.expectedReadOnly, securityContext.ReadOnlyRootFilesystem != nil &&
+				*securityContext.ReadOnlyRootFilesystem)
+
+			if test.expectedDataOwner {
+				assert.Len(t, podSpec.InitContainers, 1)
+				assert.Equal(t, int64(0), *podSpec.InitContainers[0].SecurityContext.RunAsUser)
+				assert.Contains(t, podSpec.InitContainers[0].Command, "/data")
+			} else {
+				assert.Empty(t, podSpec.InitContainers)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package orchestcomponent
+
+import (
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	trustedCA = "trusted-ca"
+
+	// Names of the trusted CA volumes, the first holds the CA certificates of the ConfigMap,
+	// the second the bundle merged with the CA certificates of the image.
+	trustedCAVolume       = "trusted-ca"
+	trustedCABundleVolume = "trusted-ca-bundle"
+
+	trustedCAMountPath       = "/etc/orchest/trusted-ca"
+	trustedCABundleMountPath = "/etc/orchest/ca-bundle"
+	trustedCABundleFile      = trustedCABundleMountPath + "/ca-certificates.crt"
+
+	// The pod template annotation which rolls out the pods if the trusted CA changes
+	trustedCAHashAnnotationKey = "orchest.io/trusted-ca-hash"
+
+	// The init container merges the CA certificates of the image, if any, with the trusted
+	// CA certificates, so the system CA certificates remain trusted.
+	trustedCAScript = "{ cat /etc/ssl/certs/ca-certificates.crt 2>/dev/null; echo; cat " +
+		trustedCAMountPath + "/ca.crt; } > " + trustedCABundleFile
+)
+
+// injectTrustedCA injects the trusted CA certificates of the component into all containers
+// of the pod, the bundle is merged by an init container running the image of the first
+// container and pointed to by the env vars the common TLS clients read.
+func injectTrustedCA(component *orchestv1alpha1.OrchestComponent, objectMeta *metav1.ObjectMeta,
+	podSpec *corev1.PodSpec) {
+
+	ca := component.Spec.TrustedCA
+	if ca == nil || len(podSpec.Containers) == 0 {
+		return
+	}
+
+	objectMeta.Annotations = utils.CloneAndAddLabel(objectMeta.Annotations, map[string]string{
+		trustedCAHashAnnotationKey: component.Spec.TrustedCAHash,
+	})
+
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name: trustedCAVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: ca.LocalObjectReference,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  ca.Key,
+							Path: "ca.crt",
+						},
+					},
+				},
+			},
+		},
+		corev1.Volume{
+			Name: trustedCABundleVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	)
+
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		Name:            trustedCA,
+		Image:           podSpec.Containers[0].Image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/bin/sh", "-c", trustedCAScript},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      trustedCAVolume,
+				MountPath: trustedCAMountPath,
+				ReadOnly:  true,
+			},
+			{
+				Name:      trustedCABundleVolume,
+				MountPath: trustedCABundleMountPath,
+			},
+		},
+	})
+
+	envVars := []corev1.EnvVar{
+		// OpenSSL, e.g. the ssl module of python
+		{Name: "SSL_CERT_FILE", Value: trustedCABundleFile},
+		// python requests
+		{Name: "REQUESTS_CA_BUNDLE", Value: trustedCABundleFile},
+		// curl
+		{Name: "CURL_CA_BUNDLE", Value: trustedCABundleFile},
+		// node, which adds the certificates to its own bundle
+		{Name: "NODE_EXTRA_CA_CERTS", Value: trustedCABundleFile},
+	}
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		for _, envVar := range envVars {
+			if utils.GetKeyFromEnvVar(container.Env, envVar.Name) == "" {
+				container.Env = append(container.Env, envVar)
+			}
+		}
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      trustedCABundleVolume,
+			MountPath: trustedCABundleMountPath,
+			ReadOnly:  true,
+		})
+	}
+}
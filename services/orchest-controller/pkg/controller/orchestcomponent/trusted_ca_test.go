@@ -0,0 +1,73 @@
+package orchestcomponent
+
+import (
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectTrustedCA(t *testing.T) {
+
+	getPodSpec := func() corev1.PodSpec {
+		return corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "app",
+					Image: "orchest/orchest-api:v2022.08.0",
+					Env: []corev1.EnvVar{
+						{Name: "REQUESTS_CA_BUNDLE", Value: "/custom/ca.crt"},
+					},
+				},
+				{
+					Name:  "sidecar",
+					Image: "busybox",
+				},
+			},
+		}
+	}
+
+	t.Run("no trusted CA", func(t *testing.T) {
+		component := &orchestv1alpha1.OrchestComponent{}
+		objectMeta := metav1.ObjectMeta{}
+		podSpec := getPodSpec()
+
+		injectTrustedCA(component, &objectMeta, &podSpec)
+		assert.Equal(t, getPodSpec(), podSpec)
+		assert.Empty(t, objectMeta.Annotations)
+	})
+
+	t.Run("trusted CA", func(t *testing.T) {
+		component := &orchestv1alpha1.OrchestComponent{}
+		component.Spec.TrustedCA = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"},
+			Key:                  "bundle.pem",
+		}
+		component.Spec.TrustedCAHash = "abc"
+		objectMeta := metav1.ObjectMeta{}
+		podSpec := getPodSpec()
+
+		injectTrustedCA(component, &objectMeta, &podSpec)
+
+		assert.Equal(t, "abc", objectMeta.Annotations[trustedCAHashAnnotationKey])
+
+		assert.Len(t, podSpec.Volumes, 2)
+		assert.Equal(t, "corporate-ca", podSpec.Volumes[0].ConfigMap.Name)
+		assert.Equal(t, "bundle.pem", podSpec.Volumes[0].ConfigMap.Items[0].Key)
+
+		assert.Len(t, podSpec.InitContainers, 1)
+		assert.Equal(t, "orchest/orchest-api:v2022.08.0", podSpec.InitContainers[0].Image)
+
+		for _, container := range podSpec.Containers {
+			assert.Equal(t, trustedCABundleFile, utils.GetKeyFromEnvVar(container.Env, "SSL_CERT_FILE"))
+			assert.Equal(t, trustedCABundleVolume, container.VolumeMounts[len(container.VolumeMounts)-1].Name)
+		}
+
+		// Explicitly set env vars are kept
+		assert.Equal(t, "/custom/ca.crt", utils.GetKeyFromEnvVar(podSpec.Containers[0].Env, "REQUESTS_CA_BUNDLE"))
+		assert.Equal(t, trustedCABundleFile, utils.GetKeyFromEnvVar(podSpec.Containers[1].Env, "REQUESTS_CA_BUNDLE"))
+	})
+}
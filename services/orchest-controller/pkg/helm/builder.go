@@ -34,6 +34,11 @@ func (builder *HelmArgBuilder) WithRollback() *HelmArgBuilder {
 	return builder
 }
 
+func (builder *HelmArgBuilder) WithHistory() *HelmArgBuilder {
+	builder.command = []string{"history"}
+	return builder
+}
+
 func (builder *HelmArgBuilder) WithTemplate() *HelmArgBuilder {
 	builder.command = []string{"template", "--debug"}
 	return builder
@@ -49,6 +54,21 @@ func (builder *HelmArgBuilder) WithStatus() *HelmArgBuilder {
 	return builder
 }
 
+func (builder *HelmArgBuilder) WithPull() *HelmArgBuilder {
+	builder.command = []string{"pull"}
+	return builder
+}
+
+func (builder *HelmArgBuilder) WithRegistryLogin() *HelmArgBuilder {
+	builder.command = []string{"registry", "login"}
+	return builder
+}
+
+func (builder *HelmArgBuilder) WithRepoAdd() *HelmArgBuilder {
+	builder.command = []string{"repo", "add"}
+	return builder
+}
+
 func (builder *HelmArgBuilder) WithJsonOutput() *HelmArgBuilder {
 	builder.args = append(builder.args, "--output", "json")
 	return builder
@@ -64,6 +84,16 @@ func (builder *HelmArgBuilder) WithAtomic() *HelmArgBuilder {
 	return builder
 }
 
+func (builder *HelmArgBuilder) WithHistoryMax(max int) *HelmArgBuilder {
+	builder.args = append(builder.args, "--history-max", strconv.Itoa(max))
+	return builder
+}
+
+func (builder *HelmArgBuilder) WithMax(max int) *HelmArgBuilder {
+	builder.args = append(builder.args, "--max", strconv.Itoa(max))
+	return builder
+}
+
 func (builder *HelmArgBuilder) WithValuesFile(values string) *HelmArgBuilder {
 	builder.args = append(builder.args, "-f", values)
 	return builder
@@ -99,6 +129,40 @@ func (builder *HelmArgBuilder) WithSetValue(key, value string) *HelmArgBuilder {
 	return builder
 }
 
+func (builder *HelmArgBuilder) WithRepoURL(url string) *HelmArgBuilder {
+	builder.args = append(builder.args, "--repo", url)
+	return builder
+}
+
+func (builder *HelmArgBuilder) WithChartVersion(version string) *HelmArgBuilder {
+	builder.args = append(builder.args, "--version", version)
+	return builder
+}
+
+func (builder *HelmArgBuilder) WithDestination(dir string) *HelmArgBuilder {
+	builder.args = append(builder.args, "--destination", dir)
+	return builder
+}
+
+// WithPasswordStdin makes helm read the password from stdin, so it is not visible in the
+// arguments of the process
+func (builder *HelmArgBuilder) WithPasswordStdin(username string) *HelmArgBuilder {
+	builder.args = append(builder.args, "--username", username, "--password-stdin")
+	return builder
+}
+
+func (builder *HelmArgBuilder) WithRepositoryConfig(config, cacheDir string) *HelmArgBuilder {
+	builder.args = append(builder.args, "--repository-config", config, "--repository-cache", cacheDir)
+	return builder
+}
+
+// WithRegistryConfig makes helm store and read the OCI registry credentials in the config,
+// instead of the global one
+func (builder *HelmArgBuilder) WithRegistryConfig(config string) *HelmArgBuilder {
+	builder.args = append(builder.args, "--registry-config", config)
+	return builder
+}
+
 func (builder *HelmArgBuilder) WithRepository(repo string) *HelmArgBuilder {
 	builder.args = append(builder.args, repo)
 	return builder
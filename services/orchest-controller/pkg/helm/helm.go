@@ -6,6 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,6 +19,43 @@ import (
 
 type Json map[string]interface{}
 
+var (
+	// Release statuses
+	StatusDeployed        = "deployed"
+	StatusSuperseded      = "superseded"
+	StatusFailed          = "failed"
+	StatusPendingInstall  = "pending-install"
+	StatusPendingUpgrade  = "pending-upgrade"
+	StatusPendingRollback = "pending-rollback"
+
+	rollbackDescription = regexp.MustCompile(`^Rollback to (\d+)`)
+)
+
+// ReleaseRevision is a single revision of the history of a release
+type ReleaseRevision struct {
+	Revision    int    `json:"revision"`
+	Status      string `json:"status"`
+	Chart       string `json:"chart"`
+	AppVersion  string `json:"app_version"`
+	Description string `json:"description"`
+}
+
+// RolledBackTo returns the revision the release was rolled back to, if the revision is
+// the result of a rollback, otherwise 0
+func (r *ReleaseRevision) RolledBackTo() int {
+	match := rollbackDescription.FindStringSubmatch(r.Description)
+	if match == nil {
+		return 0
+	}
+
+	revision, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+
+	return revision
+}
+
 func GetReleaseConfig(ctx context.Context, name, namespace string) (string, error) {
 
 	klog.V(2).Infof("Attempting to get the manifests of release: %s, namespace: %s", name, namespace)
@@ -92,6 +133,113 @@ func RemoveHelmHistoryIfNeeded(ctx context.Context,
 	return nil
 }
 
+// GetReleaseHistory returns the last max revisions of the release (all revisions kept by helm
+// if max is 0), ordered from the oldest to the newest. If the release does not exist, an empty
+// history is returned.
+func GetReleaseHistory(ctx context.Context, name, namespace string, max int) ([]ReleaseRevision, error) {
+
+	klog.V(2).Infof("Attempting to get the history of release: %s, namespace: %s", name, namespace)
+
+	args := NewHelmArgBuilder().
+		WithHistory().
+		WithName(name).
+		WithNamespace(namespace).
+		WithJsonOutput()
+
+	if max > 0 {
+		args.WithMax(max)
+	}
+
+	output, err := RunCommand(ctx, args.Build())
+	if err != nil {
+		if strings.Contains(err.Error(), "release: not found") {
+			return []ReleaseRevision{}, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get the history of release: %s, namespace: %s", name, namespace)
+	}
+
+	history := []ReleaseRevision{}
+	err = json.Unmarshal([]byte(output), &history)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the history of release: %s", name)
+	}
+
+	return history, nil
+}
+
+// RollbackRelease rolls the release back to the given revision
+func RollbackRelease(ctx context.Context, name, namespace string, revision int, timeout time.Duration) error {
+
+	klog.Infof("Rolling back release: %s, namespace: %s to revision %d", name, namespace, revision)
+
+	args := NewHelmArgBuilder().
+		WithRollback().
+		WithName(name).
+		WithVersion(revision).
+		WithNamespace(namespace).
+		WithWait().
+		WithTimeout(timeout).
+		Build()
+
+	_, err := RunCommand(ctx, args)
+	if err != nil {
+		return errors.Wrapf(err, "failed to rollback release: %s to revision %d", name, revision)
+	}
+
+	return nil
+}
+
+// RecoverReleaseIfNeeded brings a release which is stuck in failed or pending state back to
+// the last deployed revision. It returns the revision the release was rolled back to, or 0
+// if no rollback was needed or possible.
+func RecoverReleaseIfNeeded(ctx context.Context, client kubernetes.Interface,
+	name, namespace string, timeout time.Duration) (int, error) {
+
+	history, err := GetReleaseHistory(ctx, name, namespace, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(history) == 0 {
+		return 0, nil
+	}
+
+	latest := history[len(history)-1]
+	switch latest.Status {
+	case StatusPendingRollback:
+		return 0, RemoveHelmHistoryIfNeeded(ctx, client, name, namespace)
+	case StatusFailed, StatusPendingInstall, StatusPendingUpgrade:
+	default:
+		return 0, nil
+	}
+
+	klog.Infof("Release: %s, namespace: %s is in %s state", name, namespace, latest.Status)
+
+	revision := lastDeployedRevision(history)
+	if revision == 0 {
+		// The release was never deployed successfully, there is nothing to roll back to,
+		// so the release is removed to be able to install it again.
+		return 0, RemoveRelease(ctx, name, namespace)
+	}
+
+	err = RollbackRelease(ctx, name, namespace, revision, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	return revision, nil
+}
+
+// lastDeployedRevision returns the newest revision which was deployed successfully
+func lastDeployedRevision(history []ReleaseRevision) int {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Status == StatusDeployed || history[i].Status == StatusSuperseded {
+			return history[i].Revision
+		}
+	}
+	return 0
+}
+
 func GetTemplate(ctx context.Context, name, namespace string) ([]byte, error) {
 
 	klog.V(2).Infof("Attempting to render the templates of release: %s, namespace: %s", name, namespace)
@@ -118,8 +266,16 @@ func GetTemplate(ctx context.Context, name, namespace string) ([]byte, error) {
 }
 
 func RunCommand(ctx context.Context, args []string) (string, error) {
+	return RunCommandWithInput(ctx, args, "")
+}
+
+// RunCommandWithInput runs helm with the input on its stdin, e.g. a password
+func RunCommandWithInput(ctx context.Context, args []string, input string) (string, error) {
 
 	cmd := exec.CommandContext(ctx, "helm", args...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
 
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
@@ -0,0 +1,98 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolledBackTo(t *testing.T) {
+
+	tests := []struct {
+		description string
+		revision    int
+	}{
+		{
+			description: "Install complete",
+			revision:    0,
+		},
+		{
+			description: "Rollback to 3",
+			revision:    3,
+		},
+		{
+			description: "Upgrade \"orchest-argo-workflow\" failed: timed out waiting for the condition",
+			revision:    0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			revision := ReleaseRevision{Description: test.description}
+			assert.Equal(t, test.revision, revision.RolledBackTo())
+		})
+	}
+}
+
+func TestLastDeployedRevision(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		history  []ReleaseRevision
+		revision int
+	}{
+		{
+			name: "failed upgrade",
+			history: []ReleaseRevision{
+				{Revision: 1, Status: StatusSuperseded},
+				{Revision: 2, Status: StatusDeployed},
+				{Revision: 3, Status: StatusFailed},
+			},
+			revision: 2,
+		},
+		{
+			name: "pending upgrade after superseded",
+			history: []ReleaseRevision{
+				{Revision: 1, Status: StatusSuperseded},
+				{Revision: 2, Status: StatusPendingUpgrade},
+			},
+			revision: 1,
+		},
+		{
+			name: "failed install",
+			history: []ReleaseRevision{
+				{Revision: 1, Status: StatusFailed},
+			},
+			revision: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.revision, lastDeployedRevision(test.history))
+		})
+	}
+}
+
+func TestPasswordStdinArgs(t *testing.T) {
+
+	args := NewHelmArgBuilder().WithRepoAdd().
+		WithName("repo").
+		WithRepository("https://charts.example.com").
+		WithPasswordStdin("user").
+		WithRepositoryConfig("/tmp/repositories.yaml", "/tmp/cache").
+		Build()
+
+	assert.Equal(t, []string{"repo", "add", "repo", "https://charts.example.com",
+		"--username", "user", "--password-stdin",
+		"--repository-config", "/tmp/repositories.yaml", "--repository-cache", "/tmp/cache"}, args)
+
+	args = NewHelmArgBuilder().WithRegistryLogin().
+		WithName("registry.example.com").
+		WithPasswordStdin("user").
+		WithRegistryConfig("/tmp/registry.json").
+		Build()
+
+	assert.Equal(t, []string{"registry", "login", "registry.example.com",
+		"--username", "user", "--password-stdin", "--registry-config", "/tmp/registry.json"}, args)
+}
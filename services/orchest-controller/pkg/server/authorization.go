@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// authorizeClusterUpdate only calls the handler if the request carries the bearer token of a
+// Kubernetes user allowed to update the OrchestCluster of the path, as the handler changes the
+// OrchestCluster with the permissions of the controller on behalf of the user.
+func (s *Server) authorizeClusterUpdate(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			writeErrorResponseJSON(w, http.StatusUnauthorized, errors.New("a bearer token is required"))
+			return
+		}
+
+		tokenReview, err := s.kClient.AuthenticationV1().TokenReviews().Create(r.Context(),
+			&authenticationv1.TokenReview{
+				Spec: authenticationv1.TokenReviewSpec{Token: token},
+			}, metav1.CreateOptions{})
+		if err != nil {
+			klog.Error(err)
+			writeErrorResponseJSON(w, http.StatusInternalServerError, errors.New("failed to review the token"))
+			return
+		}
+
+		if !tokenReview.Status.Authenticated {
+			writeErrorResponseJSON(w, http.StatusUnauthorized, errors.New("the bearer token is invalid"))
+			return
+		}
+
+		user := tokenReview.Status.User
+		extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for key, value := range user.Extra {
+			extra[key] = authorizationv1.ExtraValue(value)
+		}
+
+		vars := mux.Vars(r)
+		accessReview, err := s.kClient.AuthorizationV1().SubjectAccessReviews().Create(r.Context(),
+			&authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					User:   user.Username,
+					UID:    user.UID,
+					Groups: user.Groups,
+					Extra:  extra,
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: vars["namespace"],
+						Verb:      "update",
+						Group:     orchestv1alpha1.SchemeGroupVersion.Group,
+						Resource:  "orchestclusters",
+						Name:      vars["name"],
+					},
+				},
+			}, metav1.CreateOptions{})
+		if err != nil {
+			klog.Error(err)
+			writeErrorResponseJSON(w, http.StatusInternalServerError, errors.New("failed to review the access"))
+			return
+		}
+
+		if !accessReview.Status.Allowed {
+			writeErrorResponseJSON(w, http.StatusForbidden, errors.Errorf(
+				"%s is not allowed to update OrchestCluster %s", user.Username, vars["name"]))
+			return
+		}
+
+		handler(w, r)
+	}
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ClusterSummary is the state of an OrchestCluster returned by the list endpoints
+type ClusterSummary struct {
+	Namespace       string                       `json:"namespace"`
+	Name            string                       `json:"name"`
+	ResourceVersion string                       `json:"resourceVersion"`
+	Version         string                       `json:"version"`
+	Paused          bool                         `json:"paused"`
+	Phase           orchestv1alpha1.OrchestPhase `json:"phase,omitempty"`
+	URL             string                       `json:"url,omitempty"`
+}
+
+// ComponentStatus is the state of an OrchestComponent of an OrchestCluster
+type ComponentStatus struct {
+	Name           string                       `json:"name"`
+	ReconcilerName string                       `json:"reconcilerName"`
+	Phase          orchestv1alpha1.OrchestPhase `json:"phase,omitempty"`
+}
+
+// VersionRequest changes the version of an OrchestCluster, the change is rejected if the
+// OrchestCluster changed since it was read at ResourceVersion
+type VersionRequest struct {
+	Version         string `json:"version"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// ListOrchestClusters lists the OrchestClusters of all namespaces, or of the namespace of the path
+func (s *Server) ListOrchestClusters(w http.ResponseWriter, r *http.Request) {
+
+	var orchests []*orchestv1alpha1.OrchestCluster
+	var err error
+	if namespace, ok := mux.Vars(r)["namespace"]; ok {
+		orchests, err = s.ocLister.OrchestClusters(namespace).List(labels.Everything())
+	} else {
+		orchests, err = s.ocLister.List(labels.Everything())
+	}
+	if err != nil {
+		writeErrorResponseJSON(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	clusters := make([]ClusterSummary, 0, len(orchests))
+	for _, orchest := range orchests {
+		clusters = append(clusters, getClusterSummary(orchest))
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Namespace != clusters[j].Namespace {
+			return clusters[i].Namespace < clusters[j].Namespace
+		}
+		return clusters[i].Name < clusters[j].Name
+	})
+
+	writeJsonResponse(w, http.StatusOK, clusters)
+}
+
+// GetOrchestClusterComponents returns the statuses of the components of the OrchestCluster
+func (s *Server) GetOrchestClusterComponents(w http.ResponseWriter, r *http.Request) {
+
+	orchest, ok := s.getOrchestCluster(w, r)
+	if !ok {
+		return
+	}
+
+	selector, err := controller.GetOrchestLabelSelector(orchest)
+	if err != nil {
+		writeErrorResponseJSON(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	components, err := s.oComponentLister.OrchestComponents(orchest.Namespace).List(selector)
+	if err != nil {
+		writeErrorResponseJSON(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	statuses := make([]ComponentStatus, 0, len(components))
+	for _, component := range components {
+		status := ComponentStatus{
+			Name:           component.Name,
+			ReconcilerName: component.Spec.ReconcilerName,
+		}
+		if component.Status != nil {
+			status.Phase = component.Status.Phase
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	writeJsonResponse(w, http.StatusOK, statuses)
+}
+
+// PauseOrchestCluster pauses the OrchestCluster
+func (s *Server) PauseOrchestCluster(w http.ResponseWriter, r *http.Request) {
+	s.patchOrchestCluster(w, r, PauseOperation, `{"spec":{"orchest":{"pause":true}}}`)
+}
+
+// ResumeOrchestCluster resumes the paused OrchestCluster
+func (s *Server) ResumeOrchestCluster(w http.ResponseWriter, r *http.Request) {
+	s.patchOrchestCluster(w, r, ResumeOperation, `{"spec":{"orchest":{"pause":false}}}`)
+}
+
+// RestartOrchestCluster restarts the OrchestCluster, the controller removes the restart
+// annotation once the cluster is stopped
+func (s *Server) RestartOrchestCluster(w http.ResponseWriter, r *http.Request) {
+	s.patchOrchestCluster(w, r, RestartOperation,
+		fmt.Sprintf(`{"metadata":{"annotations":{%q:"true"}}}`, controller.RestartAnnotationKey))
+}
+
+// UpdateOrchestClusterVersion changes the version of the OrchestCluster, the change is only
+// applied if the OrchestCluster is unchanged since the resourceVersion of the request.
+func (s *Server) UpdateOrchestClusterVersion(w http.ResponseWriter, r *http.Request) {
+
+	var request VersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeErrorResponseJSON(w, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+		return
+	}
+
+	if request.Version == "" || request.ResourceVersion == "" {
+		writeErrorResponseJSON(w, http.StatusBadRequest,
+			errors.New("the version and the resourceVersion have to be specified"))
+		return
+	}
+
+	vars := mux.Vars(r)
+	namespace, name := vars["namespace"], vars["name"]
+
+	orchest, err := s.oClient.OrchestV1alpha1().OrchestClusters(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeKubernetesError(w, err)
+		return
+	}
+
+	// The update is rejected by the API server too, this avoids the round trip
+	if orchest.ResourceVersion != request.ResourceVersion {
+		writeErrorResponseJSON(w, http.StatusConflict, errors.Errorf(
+			"OrchestCluster %s was changed, its resourceVersion is %s", name, orchest.ResourceVersion))
+		return
+	}
+
+	orchest = orchest.DeepCopy()
+	orchest.Spec.Orchest.Version = request.Version
+
+	orchest, err = s.oClient.OrchestV1alpha1().OrchestClusters(namespace).Update(r.Context(), orchest, metav1.UpdateOptions{})
+	if err != nil {
+		writeKubernetesError(w, err)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusAccepted, s.operations.create(UpdateOperation, orchest))
+}
+
+// GetOperation returns the operation with its progress
+func (s *Server) GetOperation(w http.ResponseWriter, r *http.Request) {
+
+	id := mux.Vars(r)["id"]
+
+	operation, ok, err := s.operations.get(id, func(namespace, name string) (
+		*orchestv1alpha1.OrchestCluster, error) {
+		return s.getOrchestClusterIfExists(r.Context(), namespace, name)
+	})
+	if err != nil {
+		writeErrorResponseJSON(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !ok {
+		writeErrorResponseJSON(w, http.StatusNotFound, errors.Errorf("operation %s not found", id))
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, operation)
+}
+
+// patchOrchestCluster applies the merge patch to the OrchestCluster of the path and returns
+// the operation tracking it
+func (s *Server) patchOrchestCluster(w http.ResponseWriter, r *http.Request,
+	opType OperationType, patch string) {
+
+	vars := mux.Vars(r)
+	namespace, name := vars["namespace"], vars["name"]
+
+	orchest, err := s.oClient.OrchestV1alpha1().OrchestClusters(namespace).Patch(r.Context(), name,
+		types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		writeKubernetesError(w, err)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusAccepted, s.operations.create(opType, orchest))
+}
+
+// getOrchestCluster returns the OrchestCluster of the path from the cache, the error response
+// is written if it is not found.
+func (s *Server) getOrchestCluster(w http.ResponseWriter, r *http.Request) (
+	*orchestv1alpha1.OrchestCluster, bool) {
+
+	vars := mux.Vars(r)
+
+	orchest, err := s.ocLister.OrchestClusters(vars["namespace"]).Get(vars["name"])
+	if err != nil {
+		writeKubernetesError(w, err)
+		return nil, false
+	}
+
+	return orchest, true
+}
+
+// getOrchestClusterIfExists returns the OrchestCluster from the API server, which reflects the
+// changes of the operations right away unlike the cache, or nil if it does not exist.
+func (s *Server) getOrchestClusterIfExists(ctx context.Context, namespace, name string) (
+	*orchestv1alpha1.OrchestCluster, error) {
+
+	orchest, err := s.oClient.OrchestV1alpha1().OrchestClusters(namespace).Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return orchest, err
+}
+
+func getClusterSummary(orchest *orchestv1alpha1.OrchestCluster) ClusterSummary {
+
+	summary := ClusterSummary{
+		Namespace:       orchest.Namespace,
+		Name:            orchest.Name,
+		ResourceVersion: orchest.ResourceVersion,
+		Version:         orchest.Spec.Orchest.Version,
+		Paused:          orchest.Spec.Orchest.Pause != nil && *orchest.Spec.Orchest.Pause,
+	}
+
+	if orchest.Status != nil {
+		summary.Phase = orchest.Status.Phase
+		summary.URL = orchest.Status.URL
+	}
+
+	return summary
+}
+
+// writeKubernetesError writes the error of the API server with its status code
+func writeKubernetesError(w http.ResponseWriter, err error) {
+	switch {
+	case kerrors.IsNotFound(err):
+		writeErrorResponseJSON(w, http.StatusNotFound, err)
+	case kerrors.IsConflict(err):
+		writeErrorResponseJSON(w, http.StatusConflict, err)
+	case kerrors.IsInvalid(err), kerrors.IsBadRequest(err):
+		writeErrorResponseJSON(w, http.StatusBadRequest, err)
+	default:
+		writeErrorResponseJSON(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	ofake "github.com/orchest/orchest/services/orchest-controller/pkg/client/clientset/versioned/fake"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/client/informers/externalversions"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestServer returns a server with the OrchestClusters in its cache and API server, the
+// tokens are "<username>-token" and only the admin is allowed to update the clusters.
+func newTestServer(t *testing.T, orchests ...*orchestv1alpha1.OrchestCluster) (*Server, *ofake.Clientset) {
+
+	objects := make([]runtime.Object, 0, len(orchests))
+	for _, orchest := range orchests {
+		objects = append(objects, orchest)
+	}
+	oClient := ofake.NewSimpleClientset(objects...)
+
+	kClient := kfake.NewSimpleClientset()
+	kClient.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if username := strings.TrimSuffix(review.Spec.Token, "-token"); username != review.Spec.Token {
+			review.Status.Authenticated = true
+			review.Status.User.Username = username
+		}
+		return true, review, nil
+	})
+	kClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = review.Spec.User == "admin" &&
+			review.Spec.ResourceAttributes.Resource == "orchestclusters" &&
+			review.Spec.ResourceAttributes.Verb == "update"
+		return true, review, nil
+	})
+
+	factory := externalversions.NewSharedInformerFactory(oClient, 0)
+	ocInformer := factory.Orchest().V1alpha1().OrchestClusters()
+	for _, orchest := range orchests {
+		assert.NoError(t, ocInformer.Informer().GetIndexer().Add(orchest))
+	}
+
+	server := NewServer(NewDefaultServerConfig(), kClient, oClient, ocInformer,
+		factory.Orchest().V1alpha1().OrchestComponents())
+
+	return server, oClient
+}
+
+func newTestCluster(namespace, name string) *orchestv1alpha1.OrchestCluster {
+	return &orchestv1alpha1.OrchestCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, ResourceVersion: "1"},
+		Spec: orchestv1alpha1.OrchestClusterSpec{
+			Orchest: orchestv1alpha1.OrchestSpec{Version: "v2022.10.0"},
+		},
+		Status: &orchestv1alpha1.OrchestClusterStatus{Phase: orchestv1alpha1.Running},
+	}
+}
+
+func serve(server *Server, method, path, token, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestListOrchestClusters(t *testing.T) {
+
+	server, _ := newTestServer(t, newTestCluster("orchest", "cluster-2"),
+		newTestCluster("default", "cluster-3"), newTestCluster("orchest", "cluster-1"))
+
+	var clusters []ClusterSummary
+	response := serve(server, http.MethodGet, "/clusters", "", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &clusters))
+
+	names := []string{}
+	for _, cluster := range clusters {
+		names = append(names, cluster.Namespace+"/"+cluster.Name)
+	}
+	assert.Equal(t, []string{"default/cluster-3", "orchest/cluster-1", "orchest/cluster-2"}, names)
+	assert.Equal(t, orchestv1alpha1.Running, clusters[0].Phase)
+
+	response = serve(server, http.MethodGet, "/namespaces/default/clusters", "", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &clusters))
+	assert.Len(t, clusters, 1)
+}
+
+func TestClusterActionsAuthorization(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "no token",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid token",
+			token:        "invalid",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "user not allowed to update the cluster",
+			token:        "viewer-token",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "user allowed to update the cluster",
+			token:        "admin-token",
+			expectedCode: http.StatusAccepted,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			server, oClient := newTestServer(t, newTestCluster("orchest", "cluster-1"))
+
+			response := serve(server, http.MethodPost, "/namespaces/orchest/clusters/cluster-1/pause",
+				test.token, "")
+			assert.Equal(t, test.expectedCode, response.Code)
+
+			orchest, err := oClient.OrchestV1alpha1().OrchestClusters("orchest").Get(
+				context.Background(), "cluster-1", metav1.GetOptions{})
+			assert.NoError(t, err)
+			paused := orchest.Spec.Orchest.Pause != nil && *orchest.Spec.Orchest.Pause
+			assert.Equal(t, test.expectedCode == http.StatusAccepted, paused)
+		})
+	}
+}
+
+func TestUpdateOrchestClusterVersion(t *testing.T) {
+
+	testCases := []struct {
+		name            string
+		body            string
+		expectedCode    int
+		expectedVersion string
+	}{
+		{
+			name:            "invalid body",
+			body:            "{",
+			expectedCode:    http.StatusBadRequest,
+			expectedVersion: "v2022.10.0",
+		},
+		{
+			name:            "no resource version",
+			body:            `{"version": "v2022.11.0"}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedVersion: "v2022.10.0",
+		},
+		{
+			name:            "changed cluster",
+			body:            `{"version": "v2022.11.0", "resourceVersion": "0"}`,
+			expectedCode:    http.StatusConflict,
+			expectedVersion: "v2022.10.0",
+		},
+		{
+			name:            "update",
+			body:            `{"version": "v2022.11.0", "resourceVersion": "1"}`,
+			expectedCode:    http.StatusAccepted,
+			expectedVersion: "v2022.11.0",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			server, oClient := newTestServer(t, newTestCluster("orchest", "cluster-1"))
+
+			response := serve(server, http.MethodPut, "/namespaces/orchest/clusters/cluster-1/version",
+				"admin-token", test.body)
+			assert.Equal(t, test.expectedCode, response.Code)
+
+			orchest, err := oClient.OrchestV1alpha1().OrchestClusters("orchest").Get(
+				context.Background(), "cluster-1", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedVersion, orchest.Spec.Orchest.Version)
+		})
+	}
+}
+
+func TestGetOperation(t *testing.T) {
+
+	server, _ := newTestServer(t, newTestCluster("orchest", "cluster-1"))
+
+	var operation Operation
+	response := serve(server, http.MethodPost, "/namespaces/orchest/clusters/cluster-1/restart",
+		"admin-token", "")
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &operation))
+	assert.Equal(t, RestartOperation, operation.Type)
+
+	// The restart annotation is still present
+	response = serve(server, http.MethodGet, "/operations/"+operation.ID, "", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &operation))
+	assert.Equal(t, OperationRunning, operation.Phase)
+
+	response = serve(server, http.MethodGet, "/operations/unknown", "", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serve(server, http.MethodPost, "/namespaces/orchest/clusters/cluster-2/restart",
+		"admin-token", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// GetMetrics writes the metrics of the Orchest Clusters in the Prometheus text format
+func (s *Server) GetMetrics(w http.ResponseWriter, r *http.Request) {
+
+	orchests, err := s.ocLister.List(labels.Everything())
+	if err != nil {
+		writeErrorResponseJSON(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	metrics := &bytes.Buffer{}
+
+	fmt.Fprintln(metrics, "# HELP orchest_registry_certificate_expiry_timestamp_seconds "+
+		"The time the TLS certificates of the docker-registry expire at.")
+	fmt.Fprintln(metrics, "# TYPE orchest_registry_certificate_expiry_timestamp_seconds gauge")
+	for _, orchest := range orchests {
+		if orchest.Status == nil || orchest.Status.Registry == nil ||
+			orchest.Status.Registry.CertificateExpiry == nil {
+			continue
+		}
+
+		fmt.Fprintf(metrics, "orchest_registry_certificate_expiry_timestamp_seconds{namespace=%q,name=%q} %d\n",
+			orchest.Namespace, orchest.Name, orchest.Status.Registry.CertificateExpiry.Unix())
+	}
+
+	writeResponse(w, http.StatusOK, metrics.Bytes(), mimeText)
+}
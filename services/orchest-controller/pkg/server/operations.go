@@ -0,0 +1,176 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+type OperationType string
+
+const (
+	PauseOperation   OperationType = "pause"
+	ResumeOperation  OperationType = "resume"
+	RestartOperation OperationType = "restart"
+	UpdateOperation  OperationType = "update"
+)
+
+type OperationPhase string
+
+const (
+	OperationRunning   OperationPhase = "Running"
+	OperationSucceeded OperationPhase = "Succeeded"
+	OperationFailed    OperationPhase = "Failed"
+)
+
+var (
+	// The time finished operations can be polled for
+	operationRetention = time.Hour
+)
+
+// Operation is an action on an OrchestCluster, its progress is derived from the status of the
+// OrchestCluster when it is polled.
+type Operation struct {
+	ID        string         `json:"id"`
+	Type      OperationType  `json:"type"`
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Phase     OperationPhase `json:"phase"`
+	Message   string         `json:"message,omitempty"`
+
+	// Generation is the generation of the OrchestCluster the operation waits to be observed
+	Generation int64 `json:"generation"`
+
+	// ClusterPhase is the last observed phase of the OrchestCluster
+	ClusterPhase orchestv1alpha1.OrchestPhase `json:"clusterPhase,omitempty"`
+
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// operationStore keeps the operations in memory, they are lost if the controller restarts
+type operationStore struct {
+	lock       sync.Mutex
+	operations map[string]*Operation
+}
+
+func newOperationStore() *operationStore {
+	return &operationStore{
+		operations: map[string]*Operation{},
+	}
+}
+
+// create adds a running operation on the OrchestCluster and returns a copy of it
+func (s *operationStore) create(opType OperationType, orchest *orchestv1alpha1.OrchestCluster) Operation {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	for id, operation := range s.operations {
+		if operation.FinishedAt != nil && now.Sub(*operation.FinishedAt) > operationRetention {
+			delete(s.operations, id)
+		}
+	}
+
+	operation := &Operation{
+		ID:         string(uuid.NewUUID()),
+		Type:       opType,
+		Namespace:  orchest.Namespace,
+		Name:       orchest.Name,
+		Phase:      OperationRunning,
+		Generation: orchest.Generation,
+		CreatedAt:  now,
+	}
+	s.operations[operation.ID] = operation
+
+	return *operation
+}
+
+// get returns a copy of the operation, updated with the OrchestCluster it acts on, which is
+// nil if the OrchestCluster is deleted
+func (s *operationStore) get(id string, getCluster func(namespace, name string) (
+	*orchestv1alpha1.OrchestCluster, error)) (Operation, bool, error) {
+
+	s.lock.Lock()
+	operation, ok := s.operations[id]
+	if !ok {
+		s.lock.Unlock()
+		return Operation{}, false, nil
+	}
+	snapshot := *operation
+	s.lock.Unlock()
+
+	if snapshot.Phase != OperationRunning {
+		return snapshot, true, nil
+	}
+
+	// The OrchestCluster is fetched without holding the lock, so a slow API server does not
+	// block the other operations
+	orchest, err := getCluster(snapshot.Namespace, snapshot.Name)
+	if err != nil {
+		return Operation{}, true, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The operation might have been updated by a concurrent poll meanwhile
+	operation, ok = s.operations[id]
+	if !ok {
+		return Operation{}, false, nil
+	}
+	if operation.Phase == OperationRunning {
+		updateOperation(operation, orchest, time.Now())
+	}
+
+	return *operation, true, nil
+}
+
+// updateOperation updates the phase of the operation from the status of the OrchestCluster
+func updateOperation(operation *Operation, orchest *orchestv1alpha1.OrchestCluster, now time.Time) {
+
+	finish := func(phase OperationPhase, message string) {
+		operation.Phase = phase
+		operation.Message = message
+		operation.FinishedAt = &now
+	}
+
+	if orchest == nil {
+		finish(OperationFailed, "the OrchestCluster is deleted")
+		return
+	}
+
+	if orchest.Status == nil {
+		return
+	}
+	operation.ClusterPhase = orchest.Status.Phase
+
+	if orchest.Status.Phase == orchestv1alpha1.Error {
+		finish(OperationFailed, orchest.Status.Reason)
+		return
+	}
+
+	if orchest.Status.ObservedGeneration < operation.Generation {
+		return
+	}
+
+	switch operation.Type {
+	case PauseOperation:
+		if orchest.Status.Phase == orchestv1alpha1.Stopped {
+			finish(OperationSucceeded, "")
+		}
+	case RestartOperation:
+		// The restart annotation is removed once the cluster is stopped
+		if _, ok := orchest.GetAnnotations()[controller.RestartAnnotationKey]; !ok &&
+			orchest.Status.Phase == orchestv1alpha1.Running {
+			finish(OperationSucceeded, "")
+		}
+	default:
+		if orchest.Status.Phase == orchestv1alpha1.Running {
+			finish(OperationSucceeded, "")
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	orchestv1alpha1 "github.com/orchest/orchest/services/orchest-controller/pkg/apis/orchest/v1alpha1"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateOperation(t *testing.T) {
+
+	getCluster := func(phase orchestv1alpha1.OrchestPhase, observedGeneration int64,
+		annotations map[string]string) *orchestv1alpha1.OrchestCluster {
+		return &orchestv1alpha1.OrchestCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Generation: 2, Annotations: annotations},
+			Status: &orchestv1alpha1.OrchestClusterStatus{
+				Phase:              phase,
+				ObservedGeneration: observedGeneration,
+				Reason:             "invalid",
+			},
+		}
+	}
+
+	restartAnnotations := map[string]string{controller.RestartAnnotationKey: "true"}
+
+	testCases := []struct {
+		name          string
+		opType        OperationType
+		orchest       *orchestv1alpha1.OrchestCluster
+		expectedPhase OperationPhase
+	}{
+		{
+			name:          "pause not observed",
+			opType:        PauseOperation,
+			orchest:       getCluster(orchestv1alpha1.Stopped, 1, nil),
+			expectedPhase: OperationRunning,
+		},
+		{
+			name:          "paused",
+			opType:        PauseOperation,
+			orchest:       getCluster(orchestv1alpha1.Stopped, 2, nil),
+			expectedPhase: OperationSucceeded,
+		},
+		{
+			name:          "update stopped",
+			opType:        UpdateOperation,
+			orchest:       getCluster(orchestv1alpha1.Stopped, 2, nil),
+			expectedPhase: OperationRunning,
+		},
+		{
+			name:          "updated",
+			opType:        UpdateOperation,
+			orchest:       getCluster(orchestv1alpha1.Running, 2, nil),
+			expectedPhase: OperationSucceeded,
+		},
+		{
+			name:          "restart not stopped yet",
+			opType:        RestartOperation,
+			orchest:       getCluster(orchestv1alpha1.Running, 2, restartAnnotations),
+			expectedPhase: OperationRunning,
+		},
+		{
+			name:          "restarted",
+			opType:        RestartOperation,
+			orchest:       getCluster(orchestv1alpha1.Running, 2, nil),
+			expectedPhase: OperationSucceeded,
+		},
+		{
+			name:          "error",
+			opType:        ResumeOperation,
+			orchest:       getCluster(orchestv1alpha1.Error, 1, nil),
+			expectedPhase: OperationFailed,
+		},
+		{
+			name:          "deleted",
+			opType:        ResumeOperation,
+			expectedPhase: OperationFailed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			operation := &Operation{Type: test.opType, Phase: OperationRunning, Generation: 2}
+
+			updateOperation(operation, test.orchest, time.Now())
+
+			assert.Equal(t, test.expectedPhase, operation.Phase)
+			assert.Equal(t, test.expectedPhase != OperationRunning, operation.FinishedAt != nil)
+		})
+	}
+}
+
+func TestOperationStore(t *testing.T) {
+
+	store := newOperationStore()
+	orchest := &orchestv1alpha1.OrchestCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: "orchest", Generation: 3},
+	}
+
+	operation := store.create(PauseOperation, orchest)
+	assert.NotEmpty(t, operation.ID)
+	assert.Equal(t, int64(3), operation.Generation)
+
+	_, ok, err := store.get("unknown", nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	operation, ok, err = store.get(operation.ID, func(namespace, name string) (
+		*orchestv1alpha1.OrchestCluster, error) {
+		assert.Equal(t, "orchest", namespace)
+		assert.Equal(t, "cluster-1", name)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, OperationFailed, operation.Phase)
+}
+
+func TestOperationStoreGetWithoutLock(t *testing.T) {
+
+	store := newOperationStore()
+	orchest := &orchestv1alpha1.OrchestCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: "orchest"},
+	}
+
+	operation := store.create(PauseOperation, orchest)
+
+	// The store is used while the OrchestCluster is fetched, which deadlocks if it is locked
+	var other Operation
+	_, ok, err := store.get(operation.ID, func(namespace, name string) (
+		*orchestv1alpha1.OrchestCluster, error) {
+		other = store.create(ResumeOperation, orchest)
+		return orchest, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NotEmpty(t, other.ID)
+}
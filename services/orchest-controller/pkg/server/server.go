@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/orchest/orchest/services/orchest-controller/pkg/client/clientset/versioned"
 	orchestinformers "github.com/orchest/orchest/services/orchest-controller/pkg/client/informers/externalversions/orchest/v1alpha1"
 	orchestlisters "github.com/orchest/orchest/services/orchest-controller/pkg/client/listers/orchest/v1alpha1"
 	"github.com/orchest/orchest/services/orchest-controller/pkg/server/middlewares"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
 
@@ -25,18 +27,33 @@ func NewDefaultServerConfig() ServerConfig {
 type Server struct {
 	config ServerConfig
 
+	kClient kubernetes.Interface
+
+	oClient versioned.Interface
+
 	ocLister orchestlisters.OrchestClusterLister
 
+	oComponentLister orchestlisters.OrchestComponentLister
+
+	operations *operationStore
+
 	router *mux.Router
 }
 
 func NewServer(config ServerConfig,
-	ocInformer orchestinformers.OrchestClusterInformer) *Server {
+	kClient kubernetes.Interface,
+	oClient versioned.Interface,
+	ocInformer orchestinformers.OrchestClusterInformer,
+	oComponentInformer orchestinformers.OrchestComponentInformer) *Server {
 
 	server := Server{
-		config:   config,
-		router:   mux.NewRouter(),
-		ocLister: ocInformer.Lister(),
+		config:           config,
+		router:           mux.NewRouter(),
+		kClient:          kClient,
+		oClient:          oClient,
+		ocLister:         ocInformer.Lister(),
+		oComponentLister: oComponentInformer.Lister(),
+		operations:       newOperationStore(),
 	}
 
 	server.setupHandlers()
@@ -50,6 +67,20 @@ func (s *Server) setupHandlers() {
 
 	// get user followers statistics
 	s.router.Methods(http.MethodGet).Path("/namespaces/{namespace}/clusters/{name}/status").HandlerFunc(s.GetOrchestsClusterStatus)
+
+	// cluster lifecycle, the actions return an operation to poll and require a Kubernetes user
+	// allowed to update the cluster
+	s.router.Methods(http.MethodGet).Path("/clusters").HandlerFunc(s.ListOrchestClusters)
+	s.router.Methods(http.MethodGet).Path("/namespaces/{namespace}/clusters").HandlerFunc(s.ListOrchestClusters)
+	s.router.Methods(http.MethodGet).Path("/namespaces/{namespace}/clusters/{name}/components").HandlerFunc(s.GetOrchestClusterComponents)
+	s.router.Methods(http.MethodPost).Path("/namespaces/{namespace}/clusters/{name}/pause").HandlerFunc(s.authorizeClusterUpdate(s.PauseOrchestCluster))
+	s.router.Methods(http.MethodPost).Path("/namespaces/{namespace}/clusters/{name}/resume").HandlerFunc(s.authorizeClusterUpdate(s.ResumeOrchestCluster))
+	s.router.Methods(http.MethodPost).Path("/namespaces/{namespace}/clusters/{name}/restart").HandlerFunc(s.authorizeClusterUpdate(s.RestartOrchestCluster))
+	s.router.Methods(http.MethodPut).Path("/namespaces/{namespace}/clusters/{name}/version").HandlerFunc(s.authorizeClusterUpdate(s.UpdateOrchestClusterVersion))
+	s.router.Methods(http.MethodGet).Path("/operations/{id}").HandlerFunc(s.GetOperation)
+
+	// prometheus metrics
+	s.router.Methods(http.MethodGet).Path("/metrics").HandlerFunc(s.GetMetrics)
 }
 
 func (s *Server) Run(stopCh <-chan struct{}) {
@@ -16,6 +16,8 @@ const (
 	mimeJSON mimeType = "application/json"
 	// Means response type is XML.
 	mimeXML mimeType = "application/xml"
+	// Means response type is the Prometheus text exposition format.
+	mimeText mimeType = "text/plain; version=0.0.4"
 )
 
 // Encodes the response headers into JSON format.
@@ -151,6 +151,10 @@ func NewServiceInformer(factory informers.SharedInformerFactory) coreinformers.S
 	return factory.Core().V1().Services()
 }
 
+func NewNodeInformer(factory informers.SharedInformerFactory) coreinformers.NodeInformer {
+	return factory.Core().V1().Nodes()
+}
+
 func NewServiceAccountInformer(factory informers.SharedInformerFactory) coreinformers.ServiceAccountInformer {
 	return factory.Core().V1().ServiceAccounts()
 }
@@ -324,6 +328,24 @@ func UpsertEnvVariable(envVarList *[]corev1.EnvVar, eEnvVarMap map[string]string
 	return changed
 }
 
+// RemoveEnvVariable removes the env variable from the list and returns true if it was present
+func RemoveEnvVariable(envVarList *[]corev1.EnvVar, name string) bool {
+
+	envVars := make([]corev1.EnvVar, 0, len(*envVarList))
+	for _, envVar := range *envVarList {
+		if envVar.Name != name {
+			envVars = append(envVars, envVar)
+		}
+	}
+
+	if len(envVars) == len(*envVarList) {
+		return false
+	}
+
+	*envVarList = envVars
+	return true
+}
+
 func CloneLabel(labels map[string]string) map[string]string {
 
 	// Clone labels
@@ -357,18 +379,19 @@ func CloneAndAddLabel(labels, addLabels map[string]string) map[string]string {
 }
 
 // This function is borrowed from projectcountour
-// OutputCerts outputs the certs in certs as directed by config.
-func OutputCerts(ctx context.Context, namespace string, owner metav1.OwnerReference,
-	client kubernetes.Interface, certs *certs.Certificates) error {
+// OutputCerts outputs the certs in certs to the secret with the given name, existing secrets
+// are only overwritten if update is true.
+func OutputCerts(ctx context.Context, namespace, name string, owner metav1.OwnerReference,
+	client kubernetes.Interface, certs *certs.Certificates, update bool) error {
 	var secrets []*corev1.Secret
 
-	secrets, err := AsSecrets(namespace, owner, certs)
+	secrets, err := AsSecrets(namespace, name, owner, certs)
 	if err != nil {
 		return errors.Wrap(err, "Failed to create secret from cets")
 	}
 
 	klog.Infof("Writing Secrets to namespace %q\n", namespace)
-	if err := WriteSecretsKube(ctx, client, secrets); err != nil {
+	if err := WriteSecretsKube(ctx, client, secrets, update); err != nil {
 		return fmt.Errorf("failed to write certificates to %q: %w", namespace, err)
 	}
 
@@ -378,16 +401,28 @@ func OutputCerts(ctx context.Context, namespace string, owner metav1.OwnerRefere
 // This function is borrowed from projectcountour
 // WriteSecretsKube writes all the keypairs out to Kubernetes Secrets in the
 // compact format which is compatible with Secrets generated by cert-manager.
-func WriteSecretsKube(ctx context.Context, client kubernetes.Interface, secrets []*corev1.Secret) error {
+// Existing secrets are only overwritten if update is true.
+func WriteSecretsKube(ctx context.Context, client kubernetes.Interface, secrets []*corev1.Secret, update bool) error {
 	for _, s := range secrets {
 		if _, err := client.CoreV1().Secrets(s.Namespace).Create(ctx, s, metav1.CreateOptions{}); err != nil {
 			if err != nil && !kerrors.IsAlreadyExists(err) {
 				return err
 			}
 
-			//if _, err := client.CoreV1().Secrets(s.Namespace).Update(ctx, s, metav1.UpdateOptions{}); err != nil {
-			//	return err
-			//}
+			if !update {
+				continue
+			}
+
+			oldSecret, err := client.CoreV1().Secrets(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			newSecret := oldSecret.DeepCopy()
+			newSecret.Data = s.Data
+			if _, err := client.CoreV1().Secrets(s.Namespace).Update(ctx, newSecret, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
 		}
 
 		klog.Infof("secret/%s updated\n", s.Name)
@@ -400,12 +435,12 @@ func WriteSecretsKube(ctx context.Context, client kubernetes.Interface, secrets
 // AsSecrets transforms the given Certificates struct into a slice of
 // Secrets in in compact Secret format, which is compatible with
 // both cert-manager and Contour.
-func AsSecrets(namespace string, owner metav1.OwnerReference, certdata *certs.Certificates) ([]*corev1.Secret, error) {
+func AsSecrets(namespace, name string, owner metav1.OwnerReference, certdata *certs.Certificates) ([]*corev1.Secret, error) {
 
 	return []*corev1.Secret{
 		newSecret(
 			corev1.SecretTypeTLS,
-			"registry-tls-secret",
+			name,
 			namespace,
 			owner,
 			map[string][]byte{
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"net"
 
 	"github.com/davecgh/go-spew/spew"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -28,6 +29,15 @@ func Remove(list []string, s string) []string {
 	return list
 }
 
+// GetHostFromIP returns the IP in the form used as the host of an image name, IPv6
+// addresses are enclosed in brackets.
+func GetHostFromIP(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return "[" + ip + "]"
+	}
+	return ip
+}
+
 func ComputeHash(object interface{}) string {
 	hasher := fnv.New32a()
 	DeepHashObject(hasher, object)
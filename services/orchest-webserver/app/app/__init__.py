@@ -21,7 +21,7 @@ from subprocess import Popen
 import requests
 import werkzeug
 from apscheduler.schedulers.background import BackgroundScheduler
-from flask import Flask, request, safe_join, send_from_directory
+from flask import Flask, make_response, request, safe_join, send_from_directory
 from flask_migrate import Migrate
 from flask_socketio import SocketIO
 from sqlalchemy_utils import create_database, database_exists
@@ -80,6 +80,13 @@ def create_app(to_migrate_db=False):
 
     socketio = SocketIO(app, cors_allowed_origins="*", **socketio_kwargs)
 
+    # Wraps the middleware of SocketIO, so that the socket.io path is
+    # matched without the base path as well.
+    if _config.ORCHEST_BASE_PATH:
+        app.wsgi_app = _utils.BasePathMiddleware(
+            app.wsgi_app, _config.ORCHEST_BASE_PATH
+        )
+
     if not to_migrate_db:
         orchest_config = requests.get(
             f"http://{config.CONFIG_CLASS.ORCHEST_API_ADDRESS}/api/ctl/orchest-settings"
@@ -165,13 +172,27 @@ def create_app(to_migrate_db=False):
     @app.route("/<path:path>", methods=["GET"])
     def index(path):
         file_path = safe_join(app.config["STATIC_DIR"], path)
-        if os.path.isfile(file_path):
+        if os.path.isfile(file_path) and path != "index.html":
             return send_from_directory(app.config["STATIC_DIR"], path)
+        elif _config.ORCHEST_BASE_PATH:
+            return send_index_under_base_path()
         else:
             return send_from_directory(
                 app.config["STATIC_DIR"], "index.html", cache_timeout=0
             )
 
+    def send_index_under_base_path():
+        # The client resolves its bundle, fetches and routes against the
+        # base of the page, which is only known once deployed.
+        with open(os.path.join(app.config["STATIC_DIR"], "index.html")) as f:
+            index = f.read()
+        index = index.replace(
+            '<base href="/" />', f'<base href="{_config.ORCHEST_BASE_PATH}/" />', 1
+        )
+        response = make_response(index)
+        response.cache_control.max_age = 0
+        return response
+
     register_views(app, db)
     register_orchest_api_views(app, db)
     register_background_tasks_view(app, db)
@@ -7,14 +7,18 @@ class Config:
     DEBUG = False
     TESTING = False
 
-    SQLALCHEMY_DATABASE_URI = "postgresql://postgres@orchest-database/orchest_webserver"
+    SQLALCHEMY_DATABASE_URI = (
+        f"postgresql://postgres@{_config.ORCHEST_DATABASE_ADDRESS}/orchest_webserver"
+    )
     SQLALCHEMY_TRACK_MODIFICATIONS = False
 
+    SECRET_KEY = os.environ.get("ORCHEST_SECRET_KEY")
+
     dir_path = os.path.dirname(os.path.realpath(__file__))
 
     USER_DIR = os.path.join("/userdir")
     PROJECTS_DIR = os.path.join(USER_DIR, "projects")
-    USERDIR_PVC = os.environ.get("USERDIR_PVC", "userdir-pvc")
+    USERDIR_PVC = os.environ.get("USERDIR_PVC", _config.USERDIR_PVC)
     WEBSERVER_LOGS = _config.WEBSERVER_LOGS
     STATIC_DIR = os.path.join(dir_path, "..", "..", "client", "dist")
 
@@ -2,7 +2,7 @@ import { useInterval } from "@/hooks/use-interval";
 import { Routes } from "@/Routes";
 import Box from "@mui/material/Box";
 import OpenReplay from "@openreplay/tracker";
-import { makeRequest } from "@orchest/lib-utils";
+import { getBasePath, makeRequest } from "@orchest/lib-utils";
 import React from "react";
 import { BrowserRouter as Router, Prompt } from "react-router-dom";
 import { useIntercom } from "react-use-intercom";
@@ -77,6 +77,7 @@ const App = () => {
 
   return (
     <Router
+      basename={getBasePath()}
       getUserConfirmation={(message, callback) => {
         // use Prompt component to intercept route changes
         // handle the blocking event here
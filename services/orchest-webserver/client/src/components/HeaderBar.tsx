@@ -19,7 +19,7 @@ import Stack from "@mui/material/Stack";
 import Toolbar from "@mui/material/Toolbar";
 import Tooltip from "@mui/material/Tooltip";
 import Typography from "@mui/material/Typography";
-import { hasValue } from "@orchest/lib-utils";
+import { hasValue, withBasePath } from "@orchest/lib-utils";
 import React from "react";
 import { useRouteMatch } from "react-router-dom";
 import { ProjectSelector } from "../project-selector/ProjectSelector";
@@ -80,7 +80,7 @@ export const HeaderBar = ({
   };
 
   const logoutHandler = () => {
-    window.location.href = "/login/clear";
+    window.location.href = withBasePath("/login/clear");
   };
   // Only show the pipeline name if pipeline_uuid is in the route args,
   // where `pipeline` exists in `PorjectsContext` or not.
@@ -117,7 +117,7 @@ export const HeaderBar = ({
             component="img"
             onClick={goToHome}
             onAuxClick={goToHome}
-            src="/image/logo.svg"
+            src="image/logo.svg"
             data-test-id="orchest-logo"
             sx={{
               cursor: "pointer",
@@ -12,25 +12,25 @@ export const LANGUAGE_MAP: Record<Language, string> = {
 export const DEFAULT_BASE_IMAGES: (CustomImage & { img_src: string })[] = [
   {
     base_image: "orchest/base-kernel-py",
-    img_src: "/image/python_logo.png",
+    img_src: "image/python_logo.png",
     language: "python",
     gpu_support: false,
   },
   {
     base_image: "orchest/base-kernel-r",
-    img_src: "/image/r_logo.svg",
+    img_src: "image/r_logo.svg",
     language: "r",
     gpu_support: false,
   },
   {
     base_image: "orchest/base-kernel-julia",
-    img_src: "/image/julia_logo.svg",
+    img_src: "image/julia_logo.svg",
     language: "julia",
     gpu_support: false,
   },
   {
     base_image: "orchest/base-kernel-javascript",
-    img_src: "/image/javascript_logo.svg",
+    img_src: "image/javascript_logo.svg",
     language: "javascript",
     gpu_support: false,
   },
@@ -1,6 +1,6 @@
 import { openInNewTab } from "@/utils/openInNewTab";
 import { toQueryString } from "@/utils/routing";
-import { hasValue } from "@orchest/lib-utils";
+import { hasValue, withBasePath } from "@orchest/lib-utils";
 import React from "react";
 import { useHistory, useLocation } from "react-router-dom";
 
@@ -136,7 +136,9 @@ const useCustomRoute = () => {
         : toQueryString(query);
 
       if (shouldOpenNewTab) {
-        openInNewTab(`${window.location.origin}${pathname}${queryString}`);
+        openInNewTab(
+          `${window.location.origin}${withBasePath(pathname)}${queryString}`
+        );
       } else {
         const mutateHistory = replace ? history.replace : history.push;
         mutateHistory({
@@ -6,7 +6,11 @@ import { Position } from "@/types";
 import { join } from "@/utils/path";
 import Menu from "@mui/material/Menu";
 import MenuItem from "@mui/material/MenuItem";
-import { ALLOWED_STEP_EXTENSIONS, hasValue } from "@orchest/lib-utils";
+import {
+  ALLOWED_STEP_EXTENSIONS,
+  hasValue,
+  withBasePath,
+} from "@orchest/lib-utils";
 import React from "react";
 import { usePipelineEditorContext } from "../contexts/PipelineEditorContext";
 import { useOpenNoteBook } from "../hooks/useOpenNoteBook";
@@ -67,11 +71,13 @@ export const FileManagerContextMenu: React.FC<{
     const { root, path } = unpackPath(contextMenuCombinedPath);
 
     await fetch(
-      `${FILE_MANAGEMENT_ENDPOINT}/duplicate?${queryArgs({
-        path,
-        root,
-        project_uuid: projectUuid,
-      })}`,
+      withBasePath(
+        `${FILE_MANAGEMENT_ENDPOINT}/duplicate?${queryArgs({
+          path,
+          root,
+          project_uuid: projectUuid,
+        })}`
+      ),
       { method: "POST" }
     );
     reload();
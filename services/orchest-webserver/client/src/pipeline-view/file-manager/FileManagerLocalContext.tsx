@@ -7,6 +7,7 @@ import { siteMap } from "@/routingConfig";
 import { basename } from "@/utils/path";
 import Box from "@mui/material/Box";
 import Stack from "@mui/material/Stack";
+import { withBasePath } from "@orchest/lib-utils";
 import React from "react";
 import {
   FILE_MANAGEMENT_ENDPOINT,
@@ -52,11 +53,13 @@ export const useFileManagerLocalContext = () =>
 const deleteFetch = (projectUuid: string, combinedPath: string) => {
   const { root, path } = unpackPath(combinedPath);
   return fetch(
-    `${FILE_MANAGEMENT_ENDPOINT}/delete?${queryArgs({
-      project_uuid: projectUuid,
-      path,
-      root,
-    })}`,
+    withBasePath(
+      `${FILE_MANAGEMENT_ENDPOINT}/delete?${queryArgs({
+        project_uuid: projectUuid,
+        path,
+        root,
+      })}`
+    ),
     { method: "POST" }
   );
 };
@@ -74,7 +77,7 @@ const downloadFile = (
     project_uuid: projectUuid,
   })}`;
   const a = document.createElement("a");
-  a.href = downloadUrl;
+  a.href = withBasePath(downloadUrl);
   a.download = downloadLink;
   document.body.appendChild(a);
   a.click();
@@ -1,4 +1,5 @@
 import React from "react";
+import { withBasePath } from "@orchest/lib-utils";
 import io from "socket.io-client";
 
 // eslint-disable-next-line @typescript-eslint/no-explicit-any
@@ -10,7 +11,10 @@ export type Socket = Record<"on" | "off" | "emit" | "close", any> & {
 
 export const useSocketIO = (namespace: string) => {
   const socket = React.useMemo<Socket>(() => {
-    return io.connect(namespace, { transports: ["websocket"] });
+    return io.connect(namespace, {
+      path: withBasePath("/socket.io"),
+      transports: ["websocket"],
+    });
   }, [namespace]);
 
   React.useEffect(() => {
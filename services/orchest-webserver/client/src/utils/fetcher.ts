@@ -1,7 +1,8 @@
+import { withBasePath } from "@orchest/lib-utils";
 import fetch from "isomorphic-unfetch";
 
 export const fetcher = (input: RequestInfo, init: RequestInit) =>
-  fetch(input, init).then((res) => {
+  fetch(typeof input === "string" ? withBasePath(input) : input, init).then((res) => {
     if (res.status >= 299) {
       throw res;
     }
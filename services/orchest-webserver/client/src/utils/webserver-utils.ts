@@ -8,7 +8,12 @@ import {
   Step,
 } from "@/types";
 import { pipelineSchema } from "@/utils/pipeline-schema";
-import { fetcher, hasValue, HEADER } from "@orchest/lib-utils";
+import {
+  fetcher,
+  getBasePath,
+  hasValue,
+  HEADER,
+} from "@orchest/lib-utils";
 import Ajv from "ajv";
 import dashify from "dashify";
 import { format, parseISO } from "date-fns";
@@ -190,6 +195,7 @@ export function getServiceURLs(
   return service.ports.map(
     (port) =>
       window.location.origin +
+      getBasePath() +
       "/" +
       pbpPrefix +
       "service-" +
@@ -73,14 +73,14 @@ const HelpView: React.FC = () => {
             <>
               <HelpItem
                 link={`${readthedocs}/getting_started/quickstart.html`}
-                image="/image/readthedocs.png"
+                image="image/readthedocs.png"
                 imageStyle={{ width: "18px", margin: "0 auto" }}
               >
                 Quickstart
               </HelpItem>
               <HelpItem
                 link={readthedocs}
-                image="/image/readthedocs.png"
+                image="image/readthedocs.png"
                 imageStyle={{ width: "18px", margin: "0 auto" }}
               >
                 Documentation
@@ -90,26 +90,26 @@ const HelpView: React.FC = () => {
           {hasValue(website) && (
             <HelpItem
               link={`${website}/video-tutorials`}
-              image="/image/logo.svg"
+              image="image/logo.svg"
               imageStyle={{ minWidth: "28px", marginLeft: "-2px" }}
             >
               Video tutorials
             </HelpItem>
           )}
           {hasValue(slack) && (
-            <HelpItem link={slack} image="/image/slack.png">
+            <HelpItem link={slack} image="image/slack.png">
               Slack
             </HelpItem>
           )}
           {hasValue(github) && (
-            <HelpItem link={github} image="/image/github.png">
+            <HelpItem link={github} image="image/github.png">
               GitHub
             </HelpItem>
           )}
           {hasValue(website) && (
             <HelpItem
               link={website}
-              image="/image/logo.svg"
+              image="image/logo.svg"
               imageStyle={{ minWidth: "28px", marginLeft: "-2px" }}
             >
               Website
@@ -1,6 +1,7 @@
 import { Layout } from "@/components/Layout";
 import { useSendAnalyticEvent } from "@/hooks/useSendAnalyticEvent";
 import { siteMap } from "@/routingConfig";
+import { withBasePath } from "@orchest/lib-utils";
 import React from "react";
 
 const ManageUsersView: React.FC = () => {
@@ -11,7 +12,7 @@ const ManageUsersView: React.FC = () => {
       <div className="view-page no-padding manage-users fullheight">
         <iframe
           className="borderless fullsize"
-          src="/login/admin"
+          src={withBasePath("/login/admin")}
           data-test-id="auth-admin-iframe"
         />
       </div>
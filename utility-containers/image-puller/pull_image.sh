@@ -3,6 +3,8 @@ set -euo pipefail
 
 if [ "$CONTAINER_RUNTIME" = containerd ]; then
     ctr -n=k8s.io -a=/var/run/runtime.sock i pull "${IMAGE_TO_PULL}" --skip-verify
+elif [ "$CONTAINER_RUNTIME" = cri-o ]; then
+    crictl -r unix:///var/run/runtime.sock pull "${IMAGE_TO_PULL}"
 elif [ "$CONTAINER_RUNTIME" = docker ]; then
 
     image_exist=$(docker -H unix:///var/run/runtime.sock images -q "${IMAGE_TO_PULL}")